@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
 	"github.com/OpenCHAMI/power-control/v2/internal/storage"
 )
 
@@ -108,6 +109,21 @@ func createRootCommand(pcs *pcsConfig, etcd *etcdConfig, postgres *storage.Postg
 
 	rootCommand.Flags().IntVar(&pcs.maxNumCompleted, "max-num-completed", defaultMaxNumCompleted, "Maximum number of completed records to keep.")
 	rootCommand.Flags().IntVar(&pcs.expireTimeMins, "expire-time-mins", defaultExpireTimeMins, "The time, in mins, to keep completed records.")
+	rootCommand.Flags().StringVar(&pcs.powerSequencesFile, "power-sequences-file", "", "JSON file of named power sequences transitions may use in addition to the default.")
+	rootCommand.Flags().StringSliceVar(&pcs.webhookURLs, "webhook-urls", []string{}, "URLs notified of every completed transition and power cap task (comma-separated)")
+	rootCommand.Flags().StringSliceVar(&pcs.callbackAllowlist, "callback-url-allowlist", []string{}, "URL prefixes a request's callbackURL must fall under (comma-separated). Callbacks are refused when empty.")
+	rootCommand.Flags().StringVar(&pcs.webhookSecret, "webhook-secret", "", "Secret used to sign webhook notifications with HMAC-SHA256. Required with --webhook-urls or --callback-url-allowlist.")
+	rootCommand.Flags().IntVar(&pcs.idempotencyKeyMins, "idempotency-key-mins", defaultExpireTimeMins, "The time, in mins, to remember Idempotency-Key headers and their responses.")
+	rootCommand.Flags().StringVar(&pcs.redfishEventsURL, "redfish-events-url", "", "URL of this service's /redfish-events endpoint, as reachable from BMCs. Subscribes BMCs to power state events when set.")
+	rootCommand.Flags().IntVar(&pcs.reconcileInterval, "redfish-events-reconcile-interval", defaultReconcileInterval, "The time, in seconds, between power state polls when Redfish events are enabled.")
+	rootCommand.Flags().StringSliceVar(&pcs.taskDeadlines, "task-deadlines", []string{}, "Task deadlines, in minutes, for every transition by action, component type, or both, as action:componentType=minutes (comma-separated). The action may also be waitForBMC.")
+	rootCommand.Flags().IntVar(&pcs.desiredStateRate, "desired-power-state-rate", defaultDesiredStateRate, "The most components, each minute, the desired power state reconciler may start transitions for.")
+	rootCommand.Flags().IntVar(&pcs.desiredStateBackoff, "desired-power-state-backoff", defaultDesiredStateBackoff, "The time, in seconds, the desired power state reconciler first waits before retrying a component. Doubles with each retry, up to an hour.")
+	rootCommand.Flags().StringSliceVar(&pcs.powerOnStagger, "power-on-stagger", []string{}, "Power on stagger for every transition by component type, as componentType:groupBy=count/delaySeconds (comma-separated). Powers on count components in each cabinet, or pdu, at a time, delaySeconds apart.")
+	rootCommand.Flags().StringSliceVar(&pcs.powerBudgets, "power-budgets", []string{}, "Power budgets, in watts, that powering on components is checked against, as target=watts (comma-separated), where target is a cabinet xname or system.")
+	rootCommand.Flags().StringSliceVar(&pcs.protectedComponents, "protected-components", []string{}, "Components that transitions may only power off or restart when forced, as kind=value (comma-separated), where kind is xname, type, group, or role. A role may be followed by /subrole.")
+	rootCommand.Flags().IntVar(&pcs.confirmPollSeconds, "confirm-poll-seconds", 0, fmt.Sprintf("The time, in seconds, between BMC power state polls while confirming transitions that don't set confirmPollSeconds. At least %d; 0 uses the stored power status.", model.MinConfirmPollSeconds))
+	rootCommand.Flags().StringVar(&pcs.forceScope, "protected-components-force-scope", model.DefaultForceScope, "The token scope needed to force a transition on protected components.")
 
 	// ETCD flags
 	rootCommand.Flags().BoolVar(&etcd.disableSizeChecks, "etcd-disable-size-checks", false, "Disables checking object size before storing and doing message truncation and paging.")
@@ -43,6 +43,14 @@ const (
 	defaultExpireTimeMins  = 1440  // Time, in mins, to keep completed records (default 24 hours).
 )
 
+// Time, in seconds, between power state polls when BMCs send Redfish events.
+const defaultReconcileInterval = 300
+
+const (
+	defaultDesiredStateRate    = 20  // Components the desired power state reconciler may start each minute.
+	defaultDesiredStateBackoff = 300 // Time, in seconds, before the reconciler first retries a component.
+)
+
 const (
 	dfltMaxHTTPRetries = 5
 	dfltMaxHTTPTimeout = 40
@@ -73,15 +81,30 @@ var (
 
 // pcsConfig holds the configuration for the Power Control Service (PCS).
 type pcsConfig struct {
-	fakeVaultEnabled   bool
-	vaultEnabled       bool
-	vaultKeypath       string
-	stateManagerServer string
-	hsmLockEnabled     bool
-	runControl         bool
-	credCacheDuration  int
-	maxNumCompleted    int
-	expireTimeMins     int
+	fakeVaultEnabled    bool
+	vaultEnabled        bool
+	vaultKeypath        string
+	stateManagerServer  string
+	hsmLockEnabled      bool
+	runControl          bool
+	credCacheDuration   int
+	maxNumCompleted     int
+	expireTimeMins      int
+	powerSequencesFile  string
+	webhookURLs         []string
+	callbackAllowlist   []string
+	webhookSecret       string
+	idempotencyKeyMins  int
+	redfishEventsURL    string
+	reconcileInterval   int
+	taskDeadlines       []string
+	desiredStateRate    int
+	desiredStateBackoff int
+	powerOnStagger      []string
+	powerBudgets        []string
+	protectedComponents []string
+	forceScope          string
+	confirmPollSeconds  int
 }
 
 // etcdConfig holds the configuration for the ETCD storage (if that is used).
@@ -126,6 +149,19 @@ func runPCS(pcs *pcsConfig, etcd *etcdConfig, postgres *storage.PostgresConfig,
 	logger.Log.Info("Fake Vault Enabled: ", pcs.fakeVaultEnabled)
 	logger.Log.Info("Max Completed Records: ", pcs.maxNumCompleted)
 	logger.Log.Info("Completed Record Expire Time: ", pcs.expireTimeMins)
+	logger.Log.Info("Power Sequences File: ", pcs.powerSequencesFile)
+	logger.Log.Info("Webhook URLs: ", pcs.webhookURLs)
+	logger.Log.Info("Callback URL Allowlist: ", pcs.callbackAllowlist)
+	logger.Log.Info("Idempotency Key Retention: ", pcs.idempotencyKeyMins)
+	logger.Log.Info("Redfish Events URL: ", pcs.redfishEventsURL)
+	logger.Log.Info("Task Deadlines: ", pcs.taskDeadlines)
+	logger.Log.Info("Desired Power State Rate: ", pcs.desiredStateRate)
+	logger.Log.Info("Desired Power State Backoff: ", pcs.desiredStateBackoff)
+	logger.Log.Info("Power On Stagger: ", pcs.powerOnStagger)
+	logger.Log.Info("Power Budgets: ", pcs.powerBudgets)
+	logger.Log.Info("Protected Components: ", pcs.protectedComponents)
+	logger.Log.Info("Force Scope: ", pcs.forceScope)
+	logger.Log.Info("Confirm Poll Seconds: ", pcs.confirmPollSeconds)
 	logger.Log.SetReportCaller(true)
 
 	///////////////////////////////
@@ -468,6 +504,66 @@ func runPCS(pcs *pcsConfig, etcd *etcdConfig, postgres *storage.PostgresConfig,
 	//////////////////////////////
 	domain.Init(&domainGlobals)
 
+	err = domain.LoadPowerSequences(pcs.powerSequencesFile)
+	if err != nil {
+		logger.Log.Errorf("Error loading power sequences: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigureWebhooks(pcs.webhookURLs, pcs.callbackAllowlist, pcs.webhookSecret)
+	if err != nil {
+		logger.Log.Errorf("Error configuring webhooks: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigureIdempotencyKeys(pcs.idempotencyKeyMins)
+	if err != nil {
+		logger.Log.Errorf("Error configuring idempotency keys: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigureRedfishEvents(pcs.redfishEventsURL)
+	if err != nil {
+		logger.Log.Errorf("Error configuring Redfish events: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigureTaskDeadlines(pcs.taskDeadlines)
+	if err != nil {
+		logger.Log.Errorf("Error configuring task deadlines: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigureDesiredPowerStates(pcs.desiredStateRate, pcs.desiredStateBackoff)
+	if err != nil {
+		logger.Log.Errorf("Error configuring desired power states: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigurePowerOnStaggers(pcs.powerOnStagger)
+	if err != nil {
+		logger.Log.Errorf("Error configuring power on stagger: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigurePowerBudgets(pcs.powerBudgets)
+	if err != nil {
+		logger.Log.Errorf("Error configuring power budgets: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigureProtectedComponents(pcs.protectedComponents, pcs.forceScope)
+	if err != nil {
+		logger.Log.Errorf("Error configuring protected components: %v", err)
+		os.Exit(1)
+	}
+
+	err = domain.ConfigureConfirmPolling(pcs.confirmPollSeconds)
+	if err != nil {
+		logger.Log.Errorf("Error configuring confirm polling: %v", err)
+		os.Exit(1)
+	}
+
 	dlockTimeout := 60
 	pwrSampleInterval := 30
 	statusTimeout := 30
@@ -486,6 +582,12 @@ func runPCS(pcs *pcsConfig, etcd *etcdConfig, postgres *storage.PostgresConfig,
 			pwrSampleInterval = tps
 		}
 	}
+	if pcs.redfishEventsURL != "" {
+		// Events keep power states current, so polling only has to catch
+		// the ones that were missed.
+		logger.Log.Infof("Redfish events enabled, using reconcile interval: %v", pcs.reconcileInterval)
+		pwrSampleInterval = pcs.reconcileInterval
+	}
 	envstr = os.Getenv("PCS_DISTLOCK_TIMEOUT")
 	if envstr != "" {
 		tps, err := strconv.Atoi(envstr)
@@ -567,6 +669,7 @@ func runPCS(pcs *pcsConfig, etcd *etcdConfig, postgres *storage.PostgresConfig,
 		statusTimeout, statusHttpRetries, maxIdleConns, maxIdleConnsPerHost)
 
 	domain.StartRecordsReaper()
+	domain.StartWebhookDelivery()
 
 	///////////////////////////////
 	//SIGNAL HANDLING -- //TODO does this need to move up ^ so it happens sooner?
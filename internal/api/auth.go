@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/OpenCHAMI/jwtauth/v5"
 	"github.com/lestrrat-go/jwx/jwk"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
 )
 
 type statusCheckTransport struct {
@@ -57,3 +60,40 @@ func FetchPublicKeyFromURL(url string) error {
 
 	return nil
 }
+
+// requesterFromRequest returns the sub and iss claims of the token the auth
+// middleware verified for req, whether it may override freezes, and its
+// scopes. It is empty if the route isn't protected.
+func requesterFromRequest(req *http.Request) model.Requester {
+	_, claims, err := jwtauth.FromContext(req.Context())
+	if err != nil || claims == nil {
+		return model.Requester{}
+	}
+	var requester model.Requester
+	requester.Subject, _ = claims["sub"].(string)
+	requester.Issuer, _ = claims["iss"].(string)
+	requester.FreezeOverride, _ = claims[model.FreezeOverrideClaim].(bool)
+	requester.Scopes = tokenScopes(claims)
+	return requester
+}
+
+// tokenScopes returns the scopes in a token's scope claim, a space-separated
+// string, or its scp claim, which some issuers send as a list instead.
+func tokenScopes(claims map[string]interface{}) []string {
+	var scopes []string
+	for _, name := range []string{"scope", "scp"} {
+		switch claim := claims[name].(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(claim)...)
+		case []interface{}:
+			for _, scope := range claim {
+				if s, ok := scope.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+		case []string:
+			scopes = append(scopes, claim...)
+		}
+	}
+	return scopes
+}
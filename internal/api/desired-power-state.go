@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/domain"
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// GetDesiredPowerStates - returns all desired power states or the one for the target in the URL
+func GetDesiredPowerStates(w http.ResponseWriter, req *http.Request) {
+	var pb model.Passback
+
+	defer base.DrainAndCloseRequestBody(req)
+
+	target := chi.URLParam(req, "target")
+	if target != "" {
+		pb = domain.GetDesiredPowerState(target)
+	} else {
+		pb = domain.GetDesiredPowerStates()
+	}
+	WriteHeaders(w, pb)
+}
+
+// PutDesiredPowerState - creates or replaces the desired power state for the target in the URL
+func PutDesiredPowerState(w http.ResponseWriter, req *http.Request) {
+	var pb model.Passback
+	var state model.DesiredPowerState
+
+	target := chi.URLParam(req, "target")
+	if req.Body == nil {
+		err := errors.New("empty body not allowed")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("empty body")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+
+	base.DrainAndCloseRequestBody(req)
+
+	logger.Log.WithFields(logrus.Fields{"body": string(body)}).Trace("Printing request body")
+
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error detected retrieving body")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	err = json.Unmarshal(body, &state)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Unparseable json")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	// The target in the body is optional but must match the URL if given.
+	if state.Target != "" && model.NormalizeDesiredPowerStateTarget(state.Target) != model.NormalizeDesiredPowerStateTarget(target) {
+		err = errors.New("desired power state target in body does not match URL")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Mismatched desired power state target")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	pb = domain.StoreDesiredPowerState(target, state.PowerState)
+	WriteHeaders(w, pb)
+}
+
+// DeleteDesiredPowerState - deletes the desired power state for the target in the URL
+func DeleteDesiredPowerState(w http.ResponseWriter, req *http.Request) {
+	base.DrainAndCloseRequestBody(req)
+
+	pb := domain.DeleteDesiredPowerState(chi.URLParam(req, "target"))
+	WriteHeaders(w, pb)
+}
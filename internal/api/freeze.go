@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/domain"
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// GetFreezes - returns all freezes or the one with the freezeID in the URL
+func GetFreezes(w http.ResponseWriter, req *http.Request) {
+	var pb model.Passback
+
+	defer base.DrainAndCloseRequestBody(req)
+
+	if chi.URLParam(req, "freezeID") != "" {
+		pb = GetUUIDFromVars("freezeID", req)
+		if pb.IsError {
+			WriteHeaders(w, pb)
+			return
+		}
+		pb = domain.GetFreeze(pb.Obj.(uuid.UUID))
+	} else {
+		pb = domain.GetFreezes()
+	}
+	WriteHeaders(w, pb)
+}
+
+// CreateFreeze - freezes components until the end time in the body
+func CreateFreeze(w http.ResponseWriter, req *http.Request) {
+	var pb model.Passback
+	var parameters model.FreezeParameter
+
+	if req.Body == nil {
+		err := errors.New("empty body not allowed")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("empty body")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+
+	base.DrainAndCloseRequestBody(req)
+
+	logger.Log.WithFields(logrus.Fields{"body": string(body)}).Trace("Printing request body")
+
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error detected retrieving body")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	err = json.Unmarshal(body, &parameters)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Unparseable json")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	parameters.Requester = requesterFromRequest(req)
+
+	pb = domain.CreateFreeze(parameters)
+	if pb.IsError {
+		WriteHeaders(w, pb)
+		return
+	}
+	location := "../freezes/" + pb.Obj.(model.FreezeResp).FreezeID.String()
+	WriteHeadersWithLocation(w, pb, location)
+}
+
+// DeleteFreeze - ends the freeze with the freezeID in the URL, if the
+// caller's token may override freezes
+func DeleteFreeze(w http.ResponseWriter, req *http.Request) {
+	base.DrainAndCloseRequestBody(req)
+
+	pb := GetUUIDFromVars("freezeID", req)
+	if pb.IsError {
+		WriteHeaders(w, pb)
+		return
+	}
+	pb = domain.DeleteFreeze(pb.Obj.(uuid.UUID), requesterFromRequest(req))
+	WriteHeaders(w, pb)
+}
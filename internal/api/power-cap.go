@@ -84,6 +84,23 @@ func SnapshotPowerCap(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	err := model.ValidateLabels(parameters.Labels)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid labels")
+		WriteHeaders(w, pb)
+		return
+	}
+	parameters.Requester = requesterFromRequest(req)
+	parameters.IdempotencyKey = req.Header.Get(model.IdempotencyKeyHeader)
+	err = model.ValidateIdempotencyKey(parameters.IdempotencyKey)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid idempotency key")
+		WriteHeaders(w, pb)
+		return
+	}
+
 	//Call the domain logic to do something!
 	pb = domain.SnapshotPowerCap(parameters)
 
@@ -154,6 +171,30 @@ func PatchPowerCap(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	err := model.ValidateCallbackURL(parameters.CallbackURL)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid callback URL")
+		WriteHeaders(w, pb)
+		return
+	}
+	err = model.ValidateLabels(parameters.Labels)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid labels")
+		WriteHeaders(w, pb)
+		return
+	}
+	parameters.Requester = requesterFromRequest(req)
+	parameters.IdempotencyKey = req.Header.Get(model.IdempotencyKeyHeader)
+	err = model.ValidateIdempotencyKey(parameters.IdempotencyKey)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid idempotency key")
+		WriteHeaders(w, pb)
+		return
+	}
+
 	//Call the domain logic to do something!
 	pb = domain.PatchPowerCap(parameters)
 
@@ -169,10 +210,19 @@ func PatchPowerCap(w http.ResponseWriter, req *http.Request) {
 
 // GetPowerCap - Get PowerCap tasks array
 func GetPowerCap(w http.ResponseWriter, req *http.Request) {
+	queryParams := req.URL.Query()
+
 	base.DrainAndCloseRequestBody(req)
 
 	var pb model.Passback
-	pb = domain.GetPowerCap()
+	labels, err := model.ParseLabelSelectors(queryParams["label"])
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid label selector")
+		WriteHeaders(w, pb)
+		return
+	}
+	pb = domain.GetPowerCap(labels)
 	WriteHeaders(w, pb)
 	return
 }
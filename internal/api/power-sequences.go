@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/domain"
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// GetPowerSequences - returns all power sequences or the one named in the URL
+func GetPowerSequences(w http.ResponseWriter, req *http.Request) {
+	var pb model.Passback
+
+	defer base.DrainAndCloseRequestBody(req)
+
+	name := chi.URLParam(req, "name")
+	if name != "" {
+		pb = domain.GetPowerSequence(name)
+	} else {
+		pb = domain.GetPowerSequences()
+	}
+	WriteHeaders(w, pb)
+}
+
+// PutPowerSequence - creates or replaces the power sequence named in the URL
+func PutPowerSequence(w http.ResponseWriter, req *http.Request) {
+	var pb model.Passback
+	var seq model.PowerSequence
+
+	name := chi.URLParam(req, "name")
+	if req.Body == nil {
+		err := errors.New("empty body not allowed")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("empty body")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+
+	base.DrainAndCloseRequestBody(req)
+
+	logger.Log.WithFields(logrus.Fields{"body": string(body)}).Trace("Printing request body")
+
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error detected retrieving body")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	err = json.Unmarshal(body, &seq)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Unparseable json")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	// The name in the body is optional but must match the URL if given.
+	if seq.Name != "" && seq.Name != name {
+		err = errors.New("power sequence name in body does not match URL")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Mismatched power sequence name")
+		WriteHeaders(w, pb)
+		return
+	}
+	seq.Name = name
+
+	pb = domain.StorePowerSequence(seq)
+	WriteHeaders(w, pb)
+}
+
+// DeletePowerSequence - deletes the power sequence named in the URL
+func DeletePowerSequence(w http.ResponseWriter, req *http.Request) {
+	base.DrainAndCloseRequestBody(req)
+
+	pb := domain.DeletePowerSequence(chi.URLParam(req, "name"))
+	WriteHeaders(w, pb)
+}
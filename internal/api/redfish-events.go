@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/OpenCHAMI/smd/v2/pkg/redfish"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/domain"
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// PostRedfishEvent - receives an event from a BMC subscribed to by PCS.
+// BMCs can't present tokens, so this route is always public.
+func PostRedfishEvent(w http.ResponseWriter, req *http.Request) {
+	var pb model.Passback
+	var event rf.Event
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+
+		base.DrainAndCloseRequestBody(req)
+
+		logger.Log.WithFields(logrus.Fields{"body": string(body)}).Trace("Printing request body")
+
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error detected retrieving body")
+			WriteHeaders(w, pb)
+			return
+		}
+
+		err = json.Unmarshal(body, &event)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Unparseable json")
+			WriteHeaders(w, pb)
+			return
+		}
+	} else {
+		err := errors.New("empty body not allowed")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("empty body")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	pb = domain.IngestRedfishEvent(event)
+	WriteHeaders(w, pb)
+}
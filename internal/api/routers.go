@@ -158,12 +158,117 @@ var protectedRoutes = Routes{
 		"/transitions/{transitionID}",
 		GetTransitions,
 	},
+	Route{
+		"GetTransitionEvents",
+		strings.ToUpper("get"),
+		"/transitions/{transitionID}/events",
+		GetTransitionEvents,
+	},
 	Route{
 		"AbortTransitionID",
 		strings.ToUpper("delete"),
 		"/transitions/{transitionID}",
 		AbortTransitionID,
 	},
+	Route{
+		"AbortTransitionTask",
+		strings.ToUpper("delete"),
+		"/transitions/{transitionID}/tasks/{xname}",
+		AbortTransitionTask,
+	},
+	Route{
+		"PauseTransitionID",
+		strings.ToUpper("post"),
+		"/transitions/{transitionID}/pause",
+		PauseTransitionID,
+	},
+	Route{
+		"ResumeTransitionID",
+		strings.ToUpper("post"),
+		"/transitions/{transitionID}/resume",
+		ResumeTransitionID,
+	},
+	Route{
+		"RetryTransitionID",
+		strings.ToUpper("post"),
+		"/transitions/{transitionID}/retry",
+		RetryTransitionID,
+	},
+	// Power Sequences
+	Route{
+		"GetPowerSequences",
+		strings.ToUpper("get"),
+		"/power-sequences",
+		GetPowerSequences,
+	},
+	Route{
+		"GetPowerSequence",
+		strings.ToUpper("get"),
+		"/power-sequences/{name}",
+		GetPowerSequences,
+	},
+	Route{
+		"PutPowerSequence",
+		strings.ToUpper("put"),
+		"/power-sequences/{name}",
+		PutPowerSequence,
+	},
+	Route{
+		"DeletePowerSequence",
+		strings.ToUpper("delete"),
+		"/power-sequences/{name}",
+		DeletePowerSequence,
+	},
+	// Desired Power States
+	Route{
+		"GetDesiredPowerStates",
+		strings.ToUpper("get"),
+		"/desired-power-state",
+		GetDesiredPowerStates,
+	},
+	Route{
+		"GetDesiredPowerState",
+		strings.ToUpper("get"),
+		"/desired-power-state/{target}",
+		GetDesiredPowerStates,
+	},
+	Route{
+		"PutDesiredPowerState",
+		strings.ToUpper("put"),
+		"/desired-power-state/{target}",
+		PutDesiredPowerState,
+	},
+	Route{
+		"DeleteDesiredPowerState",
+		strings.ToUpper("delete"),
+		"/desired-power-state/{target}",
+		DeleteDesiredPowerState,
+	},
+	// Freezes
+	Route{
+		"GetFreezes",
+		strings.ToUpper("get"),
+		"/freezes",
+		GetFreezes,
+	},
+	Route{
+		"CreateFreeze",
+		strings.ToUpper("post"),
+		"/freezes",
+		CreateFreeze,
+	},
+	Route{
+		"GetFreeze",
+		strings.ToUpper("get"),
+		"/freezes/{freezeID}",
+		GetFreezes,
+	},
+	Route{
+		"DeleteFreeze",
+		strings.ToUpper("delete"),
+		"/freezes/{freezeID}",
+		DeleteFreeze,
+	},
 	// Power Status
 	Route{
 		"GetPowerStatus",
@@ -222,4 +327,10 @@ var publicRoutes = Routes{
 		"/health",
 		GetHealth,
 	},
+	Route{
+		"PostRedfishEvent",
+		strings.ToUpper("post"),
+		"/redfish-events",
+		PostRedfishEvent,
+	},
 }
@@ -25,8 +25,10 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/go-chi/chi/v5"
@@ -78,6 +80,8 @@ func CreateTransition(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	parameters.Requester = requesterFromRequest(req)
+
 	//Validate the transition (specifically the Operation type)
 	transition, err := model.ToTransition(parameters, domain.GLOB.ExpireTimeMins)
 	if err != nil {
@@ -87,8 +91,24 @@ func CreateTransition(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	//A dry run only reports what the transition would do.
+	if parameters.DryRun {
+		pb = domain.PlanTransition(transition)
+		WriteHeaders(w, pb)
+		return
+	}
+
+	parameters.IdempotencyKey = req.Header.Get(model.IdempotencyKeyHeader)
+	err = model.ValidateIdempotencyKey(parameters.IdempotencyKey)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid idempotency key")
+		WriteHeaders(w, pb)
+		return
+	}
+
 	//Call the domain logic to do something!
-	pb = domain.TriggerTransition(transition)
+	pb = domain.CreateTransition(parameters, transition)
 
 	if pb.IsError == false {
 		location := "../transitions/" + (pb.Obj.(model.TransitionCreation).TransitionID.String())
@@ -116,16 +136,84 @@ func GetTransitions(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 		transitionID := pb.Obj.(uuid.UUID)
-		pb = domain.GetTransition(transitionID)
+		timeline := false
+		if value := req.URL.Query().Get("timeline"); value != "" {
+			var err error
+			timeline, err = strconv.ParseBool(value)
+			if err != nil {
+				pb = model.BuildErrorPassback(http.StatusBadRequest, errors.New("invalid timeline value "+value))
+				logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid timeline parameter")
+				WriteHeaders(w, pb)
+				return
+			}
+		}
+		pb = domain.GetTransition(transitionID, timeline)
 
 	} else {
-
-		pb = domain.GetTransitionStatuses()
+		filter, err := model.ToTransitionFilter(req.URL.Query())
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid transition filter")
+			WriteHeaders(w, pb)
+			return
+		}
+		pb = domain.GetTransitionStatuses(filter)
 	}
 	WriteHeaders(w, pb)
 	return
 }
 
+// GetTransitionEvents - streams a transition's progress as server-sent events
+func GetTransitionEvents(w http.ResponseWriter, req *http.Request) {
+	defer base.DrainAndCloseRequestBody(req)
+
+	pb := GetUUIDFromVars("transitionID", req)
+	if pb.IsError {
+		WriteHeaders(w, pb)
+		return
+	}
+	transitionID := pb.Obj.(uuid.UUID)
+
+	// Make sure the transition exists before starting the stream.
+	pb = domain.GetTransition(transitionID, false)
+	if pb.IsError {
+		WriteHeaders(w, pb)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := errors.New("streaming is not supported")
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error starting event stream")
+		WriteHeaders(w, pb)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	err := domain.WatchTransition(req.Context(), transitionID, func(event model.TransitionEvent) error {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, data)
+		if err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil && req.Context().Err() == nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Event stream for transition " + transitionID.String() + " ended early")
+	}
+	return
+}
+
 // AbortTransitionID - abort transition by transitionID
 func AbortTransitionID(w http.ResponseWriter, req *http.Request) {
 	pb := GetUUIDFromVars("transitionID", req)
@@ -141,3 +229,100 @@ func AbortTransitionID(w http.ResponseWriter, req *http.Request) {
 	WriteHeaders(w, pb)
 	return
 }
+
+// AbortTransitionTask - abort a single component's task in a transition
+func AbortTransitionTask(w http.ResponseWriter, req *http.Request) {
+	pb := GetUUIDFromVars("transitionID", req)
+
+	base.DrainAndCloseRequestBody(req)
+
+	if pb.IsError {
+		WriteHeaders(w, pb)
+		return
+	}
+	transitionID := pb.Obj.(uuid.UUID)
+	xname := chi.URLParam(req, "xname")
+	pb = domain.AbortTransitionTask(transitionID, xname)
+	WriteHeaders(w, pb)
+	return
+}
+
+// PauseTransitionID - pause transition by transitionID
+func PauseTransitionID(w http.ResponseWriter, req *http.Request) {
+	pb := GetUUIDFromVars("transitionID", req)
+
+	base.DrainAndCloseRequestBody(req)
+
+	if pb.IsError {
+		WriteHeaders(w, pb)
+		return
+	}
+	transitionID := pb.Obj.(uuid.UUID)
+	pb = domain.PauseTransition(transitionID)
+	WriteHeaders(w, pb)
+	return
+}
+
+// ResumeTransitionID - resume a paused transition by transitionID
+func ResumeTransitionID(w http.ResponseWriter, req *http.Request) {
+	pb := GetUUIDFromVars("transitionID", req)
+
+	base.DrainAndCloseRequestBody(req)
+
+	if pb.IsError {
+		WriteHeaders(w, pb)
+		return
+	}
+	transitionID := pb.Obj.(uuid.UUID)
+	pb = domain.ResumeTransition(transitionID)
+	WriteHeaders(w, pb)
+	return
+}
+
+// RetryTransitionID - retries the failed tasks of a finished transition as a new transition
+func RetryTransitionID(w http.ResponseWriter, req *http.Request) {
+	var parameters model.TransitionRetryParameter
+	pb := GetUUIDFromVars("transitionID", req)
+	if pb.IsError {
+		base.DrainAndCloseRequestBody(req)
+		WriteHeaders(w, pb)
+		return
+	}
+	transitionID := pb.Obj.(uuid.UUID)
+
+	// The body is optional. Without one every failed task is retried.
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+
+		base.DrainAndCloseRequestBody(req)
+
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error detected retrieving body")
+			WriteHeaders(w, pb)
+			return
+		}
+
+		if len(body) > 0 {
+			err = json.Unmarshal(body, &parameters)
+			if err != nil {
+				pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+				logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Unparseable json")
+				WriteHeaders(w, pb)
+				return
+			}
+		}
+	}
+
+	parameters.Requester = requesterFromRequest(req)
+	pb = domain.RetryTransition(transitionID, parameters)
+
+	if pb.IsError == false {
+		location := "../../transitions/" + (pb.Obj.(model.TransitionCreation).TransitionID.String())
+
+		WriteHeadersWithLocation(w, pb, location)
+	} else {
+		WriteHeaders(w, pb)
+	}
+	return
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/hsm"
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// The longest the reconciler waits before trying a component again.
+const desiredStateMaxBackoff = time.Hour
+
+// A component the reconciler has started transitions for without getting it
+// to its desired power state.
+type desiredStateAttempt struct {
+	count int
+	next  time.Time
+}
+
+// Reconciler state. Only the power status master's monitor loop uses these,
+// so they start over when another instance becomes master.
+var (
+	desiredStateAttempts = make(map[string]desiredStateAttempt)
+	desiredStateStarts   []time.Time
+)
+
+// ConfigureDesiredPowerStates sets how many components the reconciler may
+// start transitions for each minute, and how long, in seconds, it first
+// waits before trying a component that is still wrong again. The wait
+// doubles with each try, up to an hour.
+func ConfigureDesiredPowerStates(ratePerMinute int, backoffSecs int) error {
+	if ratePerMinute < 1 {
+		return errors.New("desired power state rate must be at least 1 component a minute")
+	}
+	if backoffSecs < 1 {
+		return errors.New("desired power state backoff must be at least 1 second")
+	}
+	GLOB.DesiredStateRate = ratePerMinute
+	GLOB.DesiredStateBackoff = time.Duration(backoffSecs) * time.Second
+	return nil
+}
+
+func GetDesiredPowerStates() (pb model.Passback) {
+	states, err := (*GLOB.DSP).GetAllDesiredPowerStates()
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving desired power states")
+		return
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Target < states[j].Target })
+	pb = model.BuildSuccessPassback(http.StatusOK, model.DesiredPowerStateArray{DesiredPowerStates: states})
+	return
+}
+
+func GetDesiredPowerState(target string) (pb model.Passback) {
+	state, err := (*GLOB.DSP).GetDesiredPowerState(model.NormalizeDesiredPowerStateTarget(target))
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving desired power state")
+		return
+	}
+	pb = model.BuildSuccessPassback(http.StatusOK, state)
+	return
+}
+
+// StoreDesiredPowerState creates or replaces the desired power state of
+// target, an xname or group:<name>.
+func StoreDesiredPowerState(target string, powerState string) (pb model.Passback) {
+	state, err := model.ToDesiredPowerState(target, powerState)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid desired power state")
+		return
+	}
+	err = (*GLOB.DSP).StoreDesiredPowerState(state)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing desired power state")
+		return
+	}
+	pb = model.BuildSuccessPassback(http.StatusOK, state)
+	return
+}
+
+// DeleteDesiredPowerState stops the reconciler from managing target.
+// Transitions it already started are left to finish.
+func DeleteDesiredPowerState(target string) (pb model.Passback) {
+	target = model.NormalizeDesiredPowerStateTarget(target)
+	_, err := (*GLOB.DSP).GetDesiredPowerState(target)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving desired power state")
+		return
+	}
+	err = (*GLOB.DSP).DeleteDesiredPowerState(target)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error deleting desired power state")
+		return
+	}
+	pb = model.BuildSuccessPassback(http.StatusNoContent, nil)
+	return
+}
+
+///////////////////////////
+// Non-exported functions (helpers, utils, etc)
+///////////////////////////
+
+// Starts transitions for components whose stored power state isn't their
+// desired power state. Components are skipped while their power state is
+// unknown, their BMC is unavailable, or another transition has them. At
+// most GLOB.DesiredStateRate components are started a minute, and each is
+// left alone for a backoff that doubles each time it is tried without
+// reaching its desired power state. Run by the power status master.
+func reconcileDesiredPowerStates() {
+	states, err := (*GLOB.DSP).GetAllDesiredPowerStates()
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving desired power states")
+		return
+	}
+	if len(states) == 0 {
+		desiredStateAttempts = make(map[string]desiredStateAttempt)
+		return
+	}
+	desired := resolveDesiredPowerStates(states)
+
+	pStates, err := (*GLOB.DSP).GetAllPowerStatus()
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving power states to reconcile")
+		return
+	}
+
+	now := time.Now()
+	recent := desiredStateStarts[:0]
+	for _, start := range desiredStateStarts {
+		if now.Sub(start) < time.Minute {
+			recent = append(recent, start)
+		}
+	}
+	desiredStateStarts = recent
+
+	for xname := range desiredStateAttempts {
+		if _, ok := desired[xname]; !ok {
+			delete(desiredStateAttempts, xname)
+		}
+	}
+
+	sort.Slice(pStates.Status, func(i, j int) bool { return pStates.Status[i].XName < pStates.Status[j].XName })
+	for _, ps := range pStates.Status {
+		want, ok := desired[ps.XName]
+		if !ok {
+			continue
+		}
+		current := strings.ToLower(ps.PowerState)
+		if current == want {
+			delete(desiredStateAttempts, ps.XName)
+			continue
+		}
+		if current != model.DesiredPowerStateOn && current != model.DesiredPowerStateOff {
+			continue
+		}
+		if strings.ToLower(ps.ManagementState) != "available" {
+			continue
+		}
+		attempt := desiredStateAttempts[ps.XName]
+		if now.Before(attempt.next) {
+			continue
+		}
+		if len(desiredStateStarts) >= GLOB.DesiredStateRate {
+			logger.Log.Infof("Desired power state rate limit reached, %d components started in the last minute", len(desiredStateStarts))
+			return
+		}
+
+		started, err := startDesiredStateTransition(ps.XName, want)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error starting transition for desired power state of %s", ps.XName)
+		} else if !started {
+			// Another transition has it, it is frozen, or there's no room
+			// in the power budgets. Check again next time.
+			continue
+		} else {
+			desiredStateStarts = append(desiredStateStarts, now)
+		}
+		attempt.count++
+		backoff := GLOB.DesiredStateBackoff
+		for i := 1; i < attempt.count && backoff < desiredStateMaxBackoff; i++ {
+			backoff *= 2
+		}
+		if backoff > desiredStateMaxBackoff {
+			backoff = desiredStateMaxBackoff
+		}
+		attempt.next = now.Add(backoff)
+		desiredStateAttempts[ps.XName] = attempt
+	}
+}
+
+// Maps each component with a desired power state to it. Xname entries take
+// precedence over groups. Components in groups that disagree are left out.
+func resolveDesiredPowerStates(states []model.DesiredPowerState) map[string]string {
+	desired := make(map[string]string)
+	conflicted := make(map[string]bool)
+	for _, state := range states {
+		if state.Group == "" {
+			continue
+		}
+		xnames, err := (*GLOB.HSM).GetSelectedComponents(hsm.ComponentSelector{Group: state.Group})
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error resolving desired power state group %s", state.Group)
+			continue
+		}
+		for _, xname := range xnames {
+			xname = model.NormalizeDesiredPowerStateTarget(xname)
+			if other, ok := desired[xname]; ok && other != state.PowerState {
+				conflicted[xname] = true
+			}
+			desired[xname] = state.PowerState
+		}
+	}
+	for xname := range conflicted {
+		logger.Log.Warnf("Groups disagree on the desired power state of %s, leaving it alone", xname)
+		delete(desired, xname)
+	}
+	for _, state := range states {
+		if state.Xname != "" {
+			desired[state.Xname] = state.PowerState
+		}
+	}
+	return desired
+}
+
+// Starts a transition to get xname to powerState. Returns false, without an
+// error, if another transition has xname, it is frozen, or powering it on
+// would exceed a power budget.
+func startDesiredStateTransition(xname string, powerState string) (bool, error) {
+	operation := "On"
+	if powerState == model.DesiredPowerStateOff {
+		operation = "Off"
+	}
+	params := model.TransitionParameter{
+		Operation: operation,
+		Location:  []model.LocationParameter{{Xname: xname}},
+		Reason:    "Reconciling desired power state",
+		Labels:    model.Labels{model.DesiredPowerStateLabel: powerState},
+	}
+	transition, err := model.ToTransition(params, GLOB.ExpireTimeMins)
+	if err != nil {
+		return false, err
+	}
+	pb := TriggerTransition(transition)
+	if pb.IsError {
+		if pb.StatusCode == http.StatusConflict {
+			return false, nil
+		}
+		return false, errors.New(pb.Error.Detail)
+	}
+	logger.Log.Infof("Started transition %s to power %s %s, which drifted from its desired power state",
+		transition.TransitionID.String(), powerState, xname)
+	return true, nil
+}
@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/hsm"
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// The components covered by the freezes in effect at some time.
+type frozenSet struct {
+	// Xnames frozen themselves, by xname or HSM group.
+	xnames map[string]model.Freeze
+	// Xnames frozen along with all of their descendants.
+	prefixes map[string]model.Freeze
+	// Ancestors of frozen xnames and prefixes.
+	above map[string]model.Freeze
+}
+
+func GetFreezes() (pb model.Passback) {
+	freezes, err := (*GLOB.DSP).GetAllFreezes()
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving freezes")
+		return
+	}
+	sort.Slice(freezes, func(i, j int) bool { return freezes[i].StartTime.Before(freezes[j].StartTime) })
+	rsp := model.FreezeRespArray{Freezes: []model.FreezeResp{}}
+	for _, freeze := range freezes {
+		rsp.Freezes = append(rsp.Freezes, model.ToFreezeResp(freeze))
+	}
+	pb = model.BuildSuccessPassback(http.StatusOK, rsp)
+	return
+}
+
+func GetFreeze(freezeID uuid.UUID) (pb model.Passback) {
+	freeze, err := (*GLOB.DSP).GetFreeze(freezeID)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving freeze")
+		return
+	}
+	pb = model.BuildSuccessPassback(http.StatusOK, model.ToFreezeResp(freeze))
+	return
+}
+
+// CreateFreeze stores a freeze built from parameters. Its groups must exist
+// in HSM.
+func CreateFreeze(parameters model.FreezeParameter) (pb model.Passback) {
+	freeze, err := model.ToFreeze(parameters)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid freeze")
+		return
+	}
+	for _, group := range freeze.Groups {
+		_, err = (*GLOB.HSM).GetSelectedComponents(hsm.ComponentSelector{Group: group})
+		if err != nil {
+			err = fmt.Errorf("Invalid freeze group %s: %w", group, err)
+			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid freeze")
+			return
+		}
+	}
+	err = (*GLOB.DSP).StoreFreeze(freeze)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing freeze")
+		return
+	}
+	logger.Log.Infof("Freeze %s created from %s to %s: %s", freeze.FreezeID.String(),
+		freeze.StartTime.Format(time.RFC3339), freeze.EndTime.Format(time.RFC3339), freeze.Reason)
+	pb = model.BuildSuccessPassback(http.StatusOK, model.ToFreezeResp(freeze))
+	return
+}
+
+// DeleteFreeze ends a freeze, or cancels one that hasn't started yet. Only
+// requesters who may override freezes can, since anybody else could get
+// around a freeze by deleting it.
+func DeleteFreeze(freezeID uuid.UUID, requester model.Requester) (pb model.Passback) {
+	if !requester.FreezeOverride {
+		err := fmt.Errorf("deleting a freeze requires a token with a true %s claim", model.FreezeOverrideClaim)
+		pb = model.BuildErrorPassback(http.StatusForbidden, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Unauthorized freeze deletion")
+		return
+	}
+	_, err := (*GLOB.DSP).GetFreeze(freezeID)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving freeze")
+		return
+	}
+	err = (*GLOB.DSP).DeleteFreeze(freezeID)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error deleting freeze")
+		return
+	}
+	logger.Log.Infof("Freeze %s deleted", freezeID.String())
+	pb = model.BuildSuccessPassback(http.StatusNoContent, nil)
+	return
+}
+
+///////////////////////////
+// Non-exported functions (helpers, utils, etc)
+///////////////////////////
+
+// Builds the set of components frozen by the freezes in effect at t. Group
+// members are looked up in HSM.
+func loadFrozenSet(t time.Time) (frozenSet, error) {
+	frozen := frozenSet{
+		xnames:   make(map[string]model.Freeze),
+		prefixes: make(map[string]model.Freeze),
+		above:    make(map[string]model.Freeze),
+	}
+	freezes, err := (*GLOB.DSP).GetAllFreezes()
+	if err != nil {
+		return frozen, err
+	}
+	for _, freeze := range freezes {
+		if !freeze.Active(t) {
+			continue
+		}
+		xnames := append([]string{}, freeze.Xnames...)
+		for _, group := range freeze.Groups {
+			members, err := (*GLOB.HSM).GetSelectedComponents(hsm.ComponentSelector{Group: group})
+			if err != nil {
+				return frozen, fmt.Errorf("error resolving group %s of freeze %s: %w", group, freeze.FreezeID.String(), err)
+			}
+			xnames = append(xnames, members...)
+		}
+		for _, xname := range xnames {
+			xname = xnametypes.NormalizeHMSCompID(xname)
+			frozen.xnames[xname] = freeze
+			frozen.addAbove(xname, freeze)
+		}
+		for _, prefix := range freeze.Prefixes {
+			frozen.prefixes[prefix] = freeze
+			frozen.addAbove(prefix, freeze)
+		}
+	}
+	return frozen, nil
+}
+
+func (f frozenSet) addAbove(xname string, freeze model.Freeze) {
+	for _, x := range xnameLineage(xname) {
+		if x != xname {
+			f.above[x] = freeze
+		}
+	}
+}
+
+func (f frozenSet) empty() bool {
+	return len(f.xnames) == 0 && len(f.prefixes) == 0
+}
+
+// Returns the freeze covering xname, if it is frozen by xname or group, or
+// it or an ancestor is a frozen prefix.
+func (f frozenSet) covering(xname string) (model.Freeze, bool) {
+	if freeze, ok := f.xnames[xname]; ok {
+		return freeze, true
+	}
+	for _, x := range xnameLineage(xname) {
+		if freeze, ok := f.prefixes[x]; ok {
+			return freeze, true
+		}
+	}
+	return model.Freeze{}, false
+}
+
+// Returns the freeze that stops xname from being powered, if it is frozen
+// or has frozen components below it.
+func (f frozenSet) blocking(xname string) (model.Freeze, bool) {
+	if freeze, ok := f.covering(xname); ok {
+		return freeze, true
+	}
+	freeze, ok := f.above[xname]
+	return freeze, ok
+}
+
+func describeFreeze(xname string, freeze model.Freeze) string {
+	return fmt.Sprintf("%s is frozen until %s by freeze %s: %s", xname,
+		freeze.EndTime.Format(time.RFC3339), freeze.FreezeID.String(), freeze.Reason)
+}
+
+// Checks tr's components against the freezes in effect now. Returns a
+// description of the first frozen one, or "" if there are none or tr's
+// requester may override freezes.
+func checkTransitionFreezes(tr model.Transition) (string, error) {
+	if tr.FreezeOverride {
+		return "", nil
+	}
+	frozen, err := loadFrozenSet(time.Now())
+	if err != nil || frozen.empty() {
+		return "", err
+	}
+	requested, _ := xnameHierarchy(tr.Location, nil)
+	xnames := make([]string, 0, len(requested))
+	for xname := range requested {
+		xnames = append(xnames, xname)
+	}
+	sort.Strings(xnames)
+	for _, xname := range xnames {
+		if freeze, ok := frozen.blocking(xname); ok {
+			return describeFreeze(xname, freeze), nil
+		}
+	}
+	return "", nil
+}
+
+// Checks the components of a power cap patch against the freezes in effect
+// now, as checkTransitionFreezes does for transitions. Only the components
+// themselves count, as capping one doesn't affect the others.
+func checkPowerCapFreezes(parameters model.PowerCapPatchParameter) (string, error) {
+	if parameters.Requester.FreezeOverride {
+		return "", nil
+	}
+	frozen, err := loadFrozenSet(time.Now())
+	if err != nil || frozen.empty() {
+		return "", err
+	}
+	for _, comp := range parameters.Components {
+		xname := xnametypes.NormalizeHMSCompID(comp.Xname)
+		if freeze, ok := frozen.covering(xname); ok {
+			return describeFreeze(xname, freeze), nil
+		}
+	}
+	return "", nil
+}
+
+// Marks the tasks of tr's frozen components, and of components with frozen
+// components below them, as blocked, unless tr's requester may override
+// freezes. Catches freezes that started while tr was scheduled or queued.
+// If the freezes can't be checked, every remaining task is failed.
+func failFrozenComps(tr model.Transition, xnameMap map[string]*TransitionComponent) {
+	if tr.FreezeOverride {
+		return
+	}
+	frozen, err := loadFrozenSet(time.Now())
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Cannot check freezes, cannot continue")
+	} else if frozen.empty() {
+		return
+	}
+	for xname, comp := range xnameMap {
+		if comp.Task.Status != model.TransitionTaskStatusNew &&
+			comp.Task.Status != model.TransitionTaskStatusInProgress {
+			continue
+		}
+		if err != nil {
+			comp.Task.StatusDesc = "Error checking freezes"
+			comp.Task.Error = err.Error()
+		} else if freeze, ok := frozen.blocking(xname); ok {
+			comp.Task.StatusDesc = "Blocked by freeze"
+			comp.Task.Error = describeFreeze(xname, freeze)
+		} else {
+			continue
+		}
+		comp.Task.Status = model.TransitionTaskStatusFailed
+		if storeErr := storeTransitionTask(comp.Task); storeErr != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": storeErr}).Error("Error storing transition task")
+		}
+	}
+}
+
+// Removes freezes that ended longer ago than finished transitions are kept.
+func freezeReaper() {
+	freezes, err := (*GLOB.DSP).GetAllFreezes()
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retreiving freezes")
+		return
+	}
+	cutoff := time.Now().Add(-time.Minute * time.Duration(GLOB.ExpireTimeMins))
+	for _, freeze := range freezes {
+		if freeze.EndTime.Before(cutoff) {
+			err = (*GLOB.DSP).DeleteFreeze(freeze.FreezeID)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error deleting freeze, %s.", freeze.FreezeID.String())
+			}
+		}
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/OpenCHAMI/power-control/v2/internal/credstore"
 	"github.com/OpenCHAMI/power-control/v2/internal/hsm"
 	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
 	"github.com/OpenCHAMI/power-control/v2/internal/storage"
 )
 
@@ -44,23 +45,37 @@ func Init(glob *DOMAIN_GLOBALS) {
 }
 
 type DOMAIN_GLOBALS struct {
-	CAUri            string
-	BaseTRSTask      *trs_http_api.HttpTask
-	RFTloc           *trs_http_api.TrsAPI
-	HSMTloc          *trs_http_api.TrsAPI
-	RFClientLock     *sync.RWMutex
-	Running          *bool
-	DSP              *storage.StorageProvider
-	HSM              *hsm.HSMProvider
-	RFHttpClient     *hms_certs.HTTPClientPair
-	SVCHttpClient    *hms_certs.HTTPClientPair
-	RFTransportReady *bool
-	VaultEnabled     bool
-	CS               *credstore.CredStoreProvider
-	DistLock         *storage.DistributedLockProvider
-	MaxNumCompleted  int
-	ExpireTimeMins   int
-	PodName          string
+	CAUri               string
+	BaseTRSTask         *trs_http_api.HttpTask
+	RFTloc              *trs_http_api.TrsAPI
+	HSMTloc             *trs_http_api.TrsAPI
+	RFClientLock        *sync.RWMutex
+	Running             *bool
+	DSP                 *storage.StorageProvider
+	HSM                 *hsm.HSMProvider
+	RFHttpClient        *hms_certs.HTTPClientPair
+	SVCHttpClient       *hms_certs.HTTPClientPair
+	RFTransportReady    *bool
+	VaultEnabled        bool
+	CS                  *credstore.CredStoreProvider
+	DistLock            *storage.DistributedLockProvider
+	MaxNumCompleted     int
+	ExpireTimeMins      int
+	PodName             string
+	PowerSequences      map[string]model.PowerSequence // Sequences from the power sequences file
+	WebhookURLs         []string                       // Notified of every completed transition and power cap task
+	CallbackAllowlist   []string                       // URL prefixes a request's callbackURL must fall under
+	WebhookSecret       string                         // Signs webhook notifications
+	IdempotencyMins     int                            // How long an Idempotency-Key is remembered
+	RedfishEventsURL    string                         // BMCs send power state events here when set
+	TaskDeadlines       model.TaskDeadlineSlice        // Task deadlines for every transition
+	PowerOnStaggers     model.PowerOnStaggerSlice      // Power on stagger for every transition
+	PowerBudgets        map[string]int                 // Watts by cabinet xname, or system
+	DesiredStateRate    int                            // Components the reconciler may start each minute
+	DesiredStateBackoff time.Duration                  // First wait before the reconciler retries a component
+	ProtectedSelectors  []model.ProtectedSelector      // Components only forced transitions may power off
+	ForceScope          string                         // Token scope needed to force a transition
+	ConfirmPollSeconds  int                            // BMC poll interval for transitions that don't set one
 }
 
 func (g *DOMAIN_GLOBALS) NewGlobals(base *trs_http_api.HttpTask,
@@ -92,8 +107,8 @@ func (g *DOMAIN_GLOBALS) NewGlobals(base *trs_http_api.HttpTask,
 	g.PodName = podName
 }
 
-// Periodically runs functions to prune expired transitions and power-capping
-// records and restart abandoned transitions.
+// Periodically runs functions to prune expired transitions, power-capping
+// records, idempotency keys and freezes and restart abandoned transitions.
 func StartRecordsReaper() {
 	go func() {
 		logger.Log.Debug("Starting records reaper.")
@@ -104,6 +119,8 @@ func StartRecordsReaper() {
 			case <-ticker.C:
 				transitionsReaper()
 				powerCapReaper()
+				idempotencyReaper()
+				freezeReaper()
 			}
 		}
 	}()
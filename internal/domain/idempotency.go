@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// How long a request may hold its Idempotency-Key without recording a
+// response. After that the claim is taken to have been left behind, as by an
+// instance that went away, and a repeat of the request may take it over.
+var idempotencyClaimLease = 2 * time.Minute
+
+// ConfigureIdempotencyKeys sets how long, in minutes, an Idempotency-Key is
+// remembered after its request.
+func ConfigureIdempotencyKeys(retentionMins int) error {
+	if retentionMins < 1 {
+		return fmt.Errorf("idempotency key retention must be at least 1 minute")
+	}
+	GLOB.IdempotencyMins = retentionMins
+	return nil
+}
+
+// Runs start, which creates a job and returns its creation response, once
+// per Idempotency-Key. A repeat of the request within the retention window
+// gets the first response, decoded by decode, instead. Requests without a
+// key always run start.
+func startOnce(scope string, requester model.Requester, key string, request interface{},
+	decode func(response string) (interface{}, error), start func() model.Passback) (pb model.Passback) {
+	if key == "" {
+		return start()
+	}
+	record, err := model.NewIdempotencyRecord(scope, requester, key, request, GLOB.IdempotencyMins)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error building idempotency record")
+		return
+	}
+	existing, claimed, err := (*GLOB.DSP).ClaimIdempotencyRecord(record)
+	if err == nil && !claimed && staleIdempotencyRecord(existing, time.Now()) {
+		// Take the record over, unless another repeat of the request got to
+		// it first or the reaper removed it meanwhile.
+		claimed, err = (*GLOB.DSP).TASIdempotencyRecord(record, existing)
+		if err != nil || !claimed {
+			existing, claimed, err = (*GLOB.DSP).ClaimIdempotencyRecord(record)
+		}
+	}
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error claiming idempotency key")
+		return
+	}
+	if !claimed {
+		return replayIdempotencyRecord(record, existing, decode)
+	}
+
+	pb = start()
+	if pb.IsError {
+		// Nothing was started, so let the request be retried with the same key.
+		err = (*GLOB.DSP).DeleteIdempotencyRecord(record)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error deleting idempotency key")
+		}
+		return
+	}
+	response, err := json.Marshal(pb.Obj)
+	if err == nil {
+		finished := record
+		finished.Response = string(response)
+		var stored bool
+		stored, err = (*GLOB.DSP).TASIdempotencyRecord(finished, record)
+		if err == nil && !stored {
+			err = errors.New("the claim was taken over by a repeat of the request")
+		}
+	}
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing idempotency key response")
+	}
+	return
+}
+
+// Reports whether existing may be replaced by a new claim: it has expired,
+// but the reaper hasn't removed it yet, or its request never recorded a
+// response within the claim lease.
+func staleIdempotencyRecord(existing model.IdempotencyRecord, now time.Time) bool {
+	if existing.AutomaticExpirationTime.Before(now) {
+		return true
+	}
+	return existing.Response == "" && existing.CreateTime.Add(idempotencyClaimLease).Before(now)
+}
+
+// Builds the response to a repeat of the request that claimed existing.
+func replayIdempotencyRecord(record model.IdempotencyRecord, existing model.IdempotencyRecord,
+	decode func(response string) (interface{}, error)) (pb model.Passback) {
+	if existing.RequestHash != record.RequestHash {
+		err := errors.New("Idempotency-Key was already used for a different request")
+		pb = model.BuildErrorPassback(http.StatusUnprocessableEntity, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Idempotency key reused")
+		return
+	}
+	if existing.Response == "" {
+		err := errors.New("A request with this Idempotency-Key is still being processed")
+		pb = model.BuildErrorPassback(http.StatusConflict, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Idempotency key in use")
+		return
+	}
+	rsp, err := decode(existing.Response)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error decoding idempotency key response")
+		return
+	}
+	logger.Log.Debugf("Replaying response for idempotency key %s", record.Key)
+	pb = model.BuildSuccessPassback(http.StatusOK, rsp)
+	return
+}
+
+func decodeTransitionCreation(response string) (interface{}, error) {
+	var rsp model.TransitionCreation
+	err := json.Unmarshal([]byte(response), &rsp)
+	return rsp, err
+}
+
+func decodePowerCapTaskCreation(response string) (interface{}, error) {
+	var rsp model.PowerCapTaskCreation
+	err := json.Unmarshal([]byte(response), &rsp)
+	return rsp, err
+}
+
+// Removes expired idempotency keys.
+func idempotencyReaper() {
+	records, err := (*GLOB.DSP).GetAllIdempotencyRecords()
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retreiving idempotency keys")
+		return
+	}
+	now := time.Now()
+	for _, record := range records {
+		if record.AutomaticExpirationTime.Before(now) {
+			err = (*GLOB.DSP).DeleteIdempotencyRecord(record)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error deleting idempotency key, %s.", record.Key)
+			}
+		}
+	}
+}
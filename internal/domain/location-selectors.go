@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/hsm"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// Returned, wrapped, when a location selects no components.
+var errEmptySelection = errors.New("selects no components")
+
+// Replaces the group, partition, and role selectors in tr's locations with
+// the xnames they match in HSM, and expanded xnames with their descendants of
+// the requested types from the stored power status hierarchy. An xname
+// selected more than once is only kept once, and xnames that were also
+// requested directly keep their deputy keys.
+func resolveLocationSelectors(tr *model.Transition) error {
+	hasSelectors := false
+	seen := make(map[string]bool)
+	for _, loc := range tr.Location {
+		if loc.IsSelector() || len(loc.Expand) > 0 {
+			hasSelectors = true
+		} else {
+			seen[xnametypes.NormalizeHMSCompID(loc.Xname)] = true
+		}
+	}
+	if !hasSelectors {
+		return nil
+	}
+
+	location := make([]model.LocationParameter, 0, len(tr.Location))
+	for _, loc := range tr.Location {
+		var (
+			xnames []string
+			err    error
+		)
+		if len(loc.Expand) > 0 {
+			xnames, err = expandXname(loc.Xname, loc.Expand)
+			if err != nil {
+				return fmt.Errorf("Error expanding location %s: %v", loc.Xname, err)
+			}
+			if len(xnames) == 0 {
+				return fmt.Errorf("Location %s with expand %s %w", loc.Xname,
+					strings.Join(loc.Expand, ","), errEmptySelection)
+			}
+		} else if loc.IsSelector() {
+			selector := hsm.ComponentSelector{
+				Group:     loc.Group,
+				Partition: loc.Partition,
+				Role:      loc.Role,
+				SubRole:   loc.SubRole,
+			}
+			xnames, err = (*GLOB.HSM).GetSelectedComponents(selector)
+			if err != nil {
+				return fmt.Errorf("Error resolving location %s: %v", selectorString(loc), err)
+			}
+			if len(xnames) == 0 {
+				return fmt.Errorf("Location %s %w", selectorString(loc), errEmptySelection)
+			}
+		} else {
+			location = append(location, loc)
+			continue
+		}
+		for _, xname := range xnames {
+			normalized := xnametypes.NormalizeHMSCompID(xname)
+			if seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+			location = append(location, model.LocationParameter{Xname: xname})
+		}
+	}
+	tr.Location = location
+	return nil
+}
+
+// Returns xname and its descendants with one of hmsTypes, from the stored
+// power status hierarchy, so only power controllable components are found.
+func expandXname(xname string, hmsTypes []string) ([]string, error) {
+	xname = xnametypes.NormalizeHMSCompID(xname)
+	types := make(map[string]bool)
+	for _, hmsType := range hmsTypes {
+		types[xnametypes.VerifyNormalizeType(hmsType)] = true
+	}
+	pStates, err := (*GLOB.DSP).GetPowerStatusHierarchy(xname)
+	if err != nil {
+		return nil, err
+	}
+	var xnames []string
+	for _, ps := range pStates.Status {
+		if !types[xnametypes.GetHMSType(ps.XName).String()] {
+			continue
+		}
+		// The hierarchy is a prefix match, so x100 would also find x1000.
+		for _, x := range xnameLineage(xnametypes.NormalizeHMSCompID(ps.XName)) {
+			if x == xname {
+				xnames = append(xnames, ps.XName)
+				break
+			}
+		}
+	}
+	sort.Strings(xnames)
+	return xnames, nil
+}
+
+// Describes a location selector for error messages, e.g. "role=Compute".
+func selectorString(loc model.LocationParameter) string {
+	var parts []string
+	if loc.Group != "" {
+		parts = append(parts, "group="+loc.Group)
+	}
+	if loc.Partition != "" {
+		parts = append(parts, "partition="+loc.Partition)
+	}
+	if loc.Role != "" {
+		parts = append(parts, "role="+loc.Role)
+	}
+	if loc.SubRole != "" {
+		parts = append(parts, "subRole="+loc.SubRole)
+	}
+	return strings.Join(parts, ",")
+}
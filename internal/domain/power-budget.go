@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// ConfigurePowerBudgets sets the power budgets, each written as
+// target=watts, that powering on components is checked against. The target
+// is a cabinet xname or system.
+func ConfigurePowerBudgets(specs []string) error {
+	budgets := make(map[string]int)
+	for _, spec := range specs {
+		budget, err := model.ParsePowerBudget(spec)
+		if err != nil {
+			return err
+		}
+		if _, ok := budgets[budget.Target]; ok {
+			return fmt.Errorf("more than one power budget for %s", budget.Target)
+		}
+		budgets[budget.Target] = budget.Watts
+	}
+	GLOB.PowerBudgets = budgets
+	return nil
+}
+
+///////////////////////////
+// Non-exported functions (helpers, utils, etc)
+///////////////////////////
+
+// The outcome of checking a transition against the power budgets.
+type powerBudgetCheck struct {
+	over      []string // Components that don't fit
+	exceeded  string   // Description of the first budget exceeded
+	unchecked []string // Nodes to be powered on whose draw isn't known
+	uncounted int      // Nodes on, or being powered on, whose draw isn't known
+}
+
+// Reports whether op may power components on.
+func powersOn(op model.Operation) bool {
+	return op == model.Operation_On || op == model.Operation_Init
+}
+
+// Stores the power capabilities a power capping task read from xname's BMC,
+// for estimating what it will draw once powered on.
+func recordPowerCapabilities(xname string, limits model.PowerCapabilities) {
+	caps := model.ToComponentPowerCapabilities(xname, limits)
+	if _, ok := caps.EstimatedDraw(); !ok {
+		return
+	}
+	err := (*GLOB.DSP).StorePowerCapabilities(caps)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error storing power capabilities for %s", xname)
+	}
+}
+
+// Checks that powering on tr's components fits in the power budgets. Under
+// the partial budget policy the components that don't fit are deferred, as
+// long as some do. Nodes whose draw isn't known are let through, listed in
+// tr's BudgetUnchecked, and warned about. Returns a description of the
+// budget tr would exceed if it can't start, or "" if it can.
+func admitPowerBudget(tr *model.Transition) (string, error) {
+	check, err := checkPowerBudget(*tr)
+	if err != nil {
+		return "", err
+	}
+	if len(check.over) > 0 {
+		if tr.BudgetPolicy != model.PowerBudgetPolicyPartial || len(check.over) >= len(tr.Location) {
+			return check.exceeded, nil
+		}
+		tr.BudgetDeferred = check.over
+		logger.Log.Infof("Transition %s deferred %d components that would exceed power budgets, %s (%s)",
+			tr.TransitionID.String(), len(check.over), check.exceeded, GLOB.PodName)
+	}
+	tr.BudgetUnchecked = check.unchecked
+	if len(check.unchecked) > 0 || check.uncounted > 0 {
+		logger.Log.Warnf("Transition %s powers on %d nodes without known power draw, and %d other nodes without "+
+			"known power draw aren't counted against power budgets; take a power cap snapshot of them (%s)",
+			tr.TransitionID.String(), len(check.unchecked), check.uncounted, GLOB.PodName)
+	}
+	return "", nil
+}
+
+// Works out which of tr's components, if any, powering on would exceed a
+// cabinet or system power budget. Each component is expected to draw its
+// estimated draw once on. Components that are already on, or that active
+// transitions are powering on, are counted against the budgets first, then
+// tr's components in xname order. Components without known power
+// capabilities can't be counted. Power capping only reads them for nodes, so
+// nodes without them are reported as unchecked, if tr powers them on, or
+// uncounted. Other components are taken to draw nothing.
+func checkPowerBudget(tr model.Transition) (powerBudgetCheck, error) {
+	var check powerBudgetCheck
+	if len(GLOB.PowerBudgets) == 0 || !powersOn(tr.Operation) {
+		return check, nil
+	}
+
+	capsList, err := (*GLOB.DSP).GetAllPowerCapabilities()
+	if err != nil {
+		return check, err
+	}
+	draws := make(map[string]int)
+	for _, caps := range capsList {
+		if draw, ok := caps.EstimatedDraw(); ok {
+			draws[caps.Xname] = draw
+		}
+	}
+
+	pStates, err := (*GLOB.DSP).GetAllPowerStatus()
+	if err != nil {
+		return check, err
+	}
+	isOn := make(map[string]bool)
+	usage := make(map[string]int)
+	counted := make(map[string]bool)
+	// Nodes that would have been counted if their draw were known.
+	uncounted := make(map[string]bool)
+	count := func(xname string) {
+		if counted[xname] || uncounted[xname] {
+			return
+		}
+		draw, ok := draws[xname]
+		if !ok {
+			if xnametypes.GetHMSType(xname) == xnametypes.Node {
+				uncounted[xname] = true
+			}
+			return
+		}
+		counted[xname] = true
+		usage[model.PowerBudgetSystem] += draw
+		if cabinet := xnameCabinet(xname); cabinet != "" {
+			usage[cabinet] += draw
+		}
+	}
+	for _, ps := range pStates.Status {
+		if strings.ToLower(ps.PowerState) == "on" {
+			isOn[ps.XName] = true
+			count(ps.XName)
+		}
+	}
+
+	filter := model.TransitionFilter{Statuses: activeTransitionStatuses}
+	transitions, _, err := (*GLOB.DSP).GetTransitions(filter)
+	if err != nil {
+		return check, err
+	}
+	for _, other := range transitions {
+		if other.TransitionID == tr.TransitionID || !powersOn(other.Operation) {
+			continue
+		}
+		// Large transitions may keep some of their locations in other pages.
+		full, _, err := (*GLOB.DSP).GetTransition(other.TransitionID)
+		if err != nil {
+			if strings.Contains(err.Error(), "does not exist") {
+				continue
+			}
+			return check, err
+		}
+		requested, _ := xnameHierarchy(full.Location, append(full.AbortedXnames, full.BudgetDeferred...))
+		for xname := range requested {
+			count(xname)
+		}
+	}
+
+	requested, _ := xnameHierarchy(tr.Location, nil)
+	xnames := make([]string, 0, len(requested))
+	for xname := range requested {
+		xnames = append(xnames, xname)
+	}
+	sort.Strings(xnames)
+	check.uncounted = len(uncounted)
+
+	for _, xname := range xnames {
+		if isOn[xname] || counted[xname] || uncounted[xname] {
+			continue
+		}
+		draw, ok := draws[xname]
+		if !ok {
+			if xnametypes.GetHMSType(xname) == xnametypes.Node {
+				check.unchecked = append(check.unchecked, xname)
+			}
+			continue
+		}
+		targets := []string{model.PowerBudgetSystem}
+		if cabinet := xnameCabinet(xname); cabinet != "" {
+			targets = append(targets, cabinet)
+		}
+		fits := true
+		for _, target := range targets {
+			budget, ok := GLOB.PowerBudgets[target]
+			if ok && usage[target]+draw > budget {
+				fits = false
+				if check.exceeded == "" {
+					check.exceeded = fmt.Sprintf("powering on %s (%d W) would exceed the %s budget of %d W with %d W in use",
+						xname, draw, target, budget, usage[target])
+				}
+			}
+		}
+		if !fits {
+			check.over = append(check.over, xname)
+			continue
+		}
+		count(xname)
+	}
+	return check, nil
+}
+
+// Fails the tasks of the components tr deferred because powering them on
+// would exceed a power budget.
+func failBudgetDeferredComps(tr model.Transition, xnameMap map[string]*TransitionComponent) {
+	for _, xname := range tr.BudgetDeferred {
+		comp, ok := xnameMap[xname]
+		if !ok || (comp.Task.Status != model.TransitionTaskStatusNew &&
+			comp.Task.Status != model.TransitionTaskStatusInProgress) {
+			continue
+		}
+		comp.Task.Status = model.TransitionTaskStatusFailed
+		comp.Task.StatusDesc = "Failed to achieve transition"
+		comp.Task.Error = "Powering on would exceed power budget"
+		depErrMsg := fmt.Sprintf("Powering on dependency, %s, would exceed power budget.", xname)
+		failDependentComps(xnameMap, "on", xname, depErrMsg)
+		err := storeTransitionTask(comp.Task)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+		}
+	}
+}
@@ -191,33 +191,43 @@ type PowerMetric struct {
 
 // Start a power cap snapshot task
 func SnapshotPowerCap(parameters model.PowerCapSnapshotParameter) (pb model.Passback) {
+	return startOnce(model.IdempotencyScopePowerCapSnapshot, parameters.Requester, parameters.IdempotencyKey, parameters,
+		decodePowerCapTaskCreation, func() model.Passback {
+			return startPowerCapTask(model.NewPowerCapSnapshotTask(parameters, GLOB.ExpireTimeMins))
+		})
+}
 
-	// Create task
-	task := model.NewPowerCapSnapshotTask(parameters, GLOB.ExpireTimeMins)
+// Start a power cap patch task for setting power limits for nodes.
+func PatchPowerCap(parameters model.PowerCapPatchParameter) (pb model.Passback) {
+	pb = checkCallbackURL(parameters.CallbackURL)
+	if pb.IsError {
+		return
+	}
+	return startOnce(model.IdempotencyScopePowerCapPatch, parameters.Requester, parameters.IdempotencyKey, parameters,
+		decodePowerCapTaskCreation, func() model.Passback {
+			return startPowerCapPatch(parameters)
+		})
+}
 
-	// Store task
-	err := (*GLOB.DSP).StorePowerCapTask(task)
+// Starts a power cap patch task unless it would cap frozen components.
+func startPowerCapPatch(parameters model.PowerCapPatchParameter) (pb model.Passback) {
+	frozen, err := checkPowerCapFreezes(parameters)
 	if err != nil {
 		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
-		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing new power capping task")
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error checking freezes")
 		return
 	}
-
-	// Start task
-	go doPowerCapTask(task.TaskID)
-
-	rsp := model.PowerCapTaskCreation{
-		TaskID: task.TaskID,
+	if frozen != "" {
+		err = fmt.Errorf("Components are frozen: %s", frozen)
+		pb = model.BuildErrorPassback(http.StatusConflict, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Frozen components")
+		return
 	}
-	pb = model.BuildSuccessPassback(http.StatusOK, rsp)
-	return
+	return startPowerCapTask(model.NewPowerCapPatchTask(parameters, GLOB.ExpireTimeMins))
 }
 
-// Start a power cap patch task for setting power limits for nodes.
-func PatchPowerCap(parameters model.PowerCapPatchParameter) (pb model.Passback) {
-	// Create task
-	task := model.NewPowerCapPatchTask(parameters, GLOB.ExpireTimeMins)
-
+// Store and start a new power cap task
+func startPowerCapTask(task model.PowerCapTask) (pb model.Passback) {
 	// Store task
 	err := (*GLOB.DSP).StorePowerCapTask(task)
 	if err != nil {
@@ -236,8 +246,8 @@ func PatchPowerCap(parameters model.PowerCapPatchParameter) (pb model.Passback)
 	return
 }
 
-// Get all of the existing power capping tasks
-func GetPowerCap() (pb model.Passback) {
+// Get all of the existing power capping tasks, or just those matching every label selector
+func GetPowerCap(labels []model.LabelSelector) (pb model.Passback) {
 	// Get all tasks
 	tasks, err := (*GLOB.DSP).GetAllPowerCapTasks()
 	if err != nil {
@@ -251,6 +261,9 @@ func GetPowerCap() (pb model.Passback) {
 	}
 	// Get the operations for each task
 	for _, task := range tasks {
+		if !model.MatchesLabels(labels, task.Labels) {
+			continue
+		}
 		var ops []model.PowerCapOperation
 		// Compressed tasks don't have operations anymore. No need to look them up.
 		if !task.IsCompressed {
@@ -322,6 +335,9 @@ func buildPowerCapResponse(task model.PowerCapTask, ops []model.PowerCapOperatio
 		TaskCreateTime:          task.TaskCreateTime,
 		AutomaticExpirationTime: task.AutomaticExpirationTime,
 		TaskStatus:              task.TaskStatus,
+		Requester:               task.Requester(),
+		Reason:                  task.Reason,
+		Labels:                  task.Labels,
 	}
 
 	// task.IsCompressed == true when the task is complete. compressAndCompleteTask populates a summary of the task's
@@ -799,6 +815,9 @@ func doPowerCapTask(taskID uuid.UUID) {
 					logger.Log.WithFields(logrus.Fields{"ERROR": err, "URI": tdone.Request.URL.String()}).Error("Redfish request failed")
 				} else {
 					op.Status = model.PowerCapOpStatusSucceeded
+					if op.Component.Limits != nil {
+						recordPowerCapabilities(op.Component.Xname, *op.Component.Limits)
+					}
 				}
 			}
 			err = (*GLOB.DSP).StorePowerCapOperation(op)
@@ -1127,6 +1146,11 @@ func compressAndCompleteTask(task model.PowerCapTask) {
 		// Don't delete the operations if we were unsuccessful storing the task.
 		return
 	}
+	callbackURL := ""
+	if task.PatchParameters != nil {
+		callbackURL = task.PatchParameters.CallbackURL
+	}
+	enqueueWebhooks(model.WebhookEventPowerCapComplete, callbackURL, buildPowerCapResponse(task, nil, false))
 	for _, op := range ops {
 		err = (*GLOB.DSP).DeletePowerCapOperation(task.TaskID, op.OperationID)
 		if err != nil {
@@ -156,7 +156,7 @@ func TestDoPowerCapTask(t *testing.T) {
 	expectedPowerCapTaskResp3Map[pctc.TaskID.String()] = expectedPowerCapTaskResp1
 	expectedPowerCapTaskResp3Map[pctc2.TaskID.String()] = expectedPowerCapTaskResp2
 
-	pb3 := GetPowerCap()
+	pb3 := GetPowerCap(nil)
 	if pb3.IsError {
 		t.Errorf("ERROR doPowerCapTask(3) failed - %s", pb3.Error.Detail)
 	}
@@ -177,6 +177,16 @@ func TestDoPowerCapTask(t *testing.T) {
 		}
 	}
 
+	// Neither task has labels, so a label selector excludes them.
+	pb3 = GetPowerCap([]model.LabelSelector{{Key: "rack", AnyValue: true}})
+	if pb3.IsError {
+		t.Errorf("ERROR doPowerCapTask(3) failed - %s", pb3.Error.Detail)
+	}
+	pctc3Array, ok = pb3.Obj.(model.PowerCapTaskRespArray)
+	if !ok || len(pctc3Array.Tasks) != 0 {
+		t.Errorf("ERROR doPowerCapTask(3) failed - Unexpected passback for label selector %v", pb3)
+	}
+
 	/////////
 	// Test 4 - Test reaper function.
 	// Tests:
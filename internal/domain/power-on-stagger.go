@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// A set of components in a batch that are powered on together, offset after
+// the batch starts.
+type staggerWave struct {
+	offset time.Duration
+	comps  []*TransitionComponent
+}
+
+// ConfigurePowerOnStaggers sets the power on stagger, each written as
+// componentType:groupBy=count/delaySeconds, that applies to every
+// transition. A transition's own power on stagger takes precedence over
+// these.
+func ConfigurePowerOnStaggers(specs []string) error {
+	var staggers model.PowerOnStaggerSlice
+	for _, spec := range specs {
+		stagger, err := model.ParsePowerOnStagger(spec)
+		if err != nil {
+			return err
+		}
+		staggers = append(staggers, stagger)
+	}
+	err := model.ValidatePowerOnStaggers(staggers)
+	if err != nil {
+		return err
+	}
+	GLOB.PowerOnStaggers = staggers
+	return nil
+}
+
+// Returns the power on stagger for compType components in tr, from tr's own
+// power on stagger, then the global one. Returns false if powering them on
+// isn't staggered.
+func powerOnStagger(tr model.Transition, compType xnametypes.HMSType) (model.PowerOnStagger, bool) {
+	stagger, ok := tr.PowerOnStagger.Lookup(compType.String())
+	if !ok {
+		stagger, ok = GLOB.PowerOnStaggers.Lookup(compType.String())
+	}
+	if !ok || stagger.DelaySeconds == 0 {
+		return stagger, false
+	}
+	return stagger, true
+}
+
+// Splits a batch into the waves it is powered on in. The components of each
+// type in each cabinet or PDU are powered on their stagger's count at a
+// time, a delay apart. Groups are staggered independently of each other, so
+// components in different cabinets or PDUs share waves. Actions other than
+// on are sent to the whole batch at once.
+func staggerComponents(tr model.Transition, powerAction string, compList []*TransitionComponent) []staggerWave {
+	if powerAction != "on" || len(compList) == 0 {
+		return []staggerWave{{comps: compList}}
+	}
+
+	offsets := make(map[time.Duration][]*TransitionComponent)
+	counts := make(map[string]int)
+	for _, comp := range compList {
+		var offset time.Duration
+		compType := xnametypes.GetHMSType(comp.Task.Xname)
+		stagger, ok := powerOnStagger(tr, compType)
+		if ok {
+			group := compType.String() + "/" + staggerGroup(comp, stagger.GroupBy)
+			offset = time.Duration(counts[group]/stagger.Count*stagger.DelaySeconds) * time.Second
+			counts[group]++
+		}
+		offsets[offset] = append(offsets[offset], comp)
+	}
+
+	waves := make([]staggerWave, 0, len(offsets))
+	for offset, comps := range offsets {
+		waves = append(waves, staggerWave{offset: offset, comps: comps})
+	}
+	sort.Slice(waves, func(i, j int) bool { return waves[i].offset < waves[j].offset })
+	return waves
+}
+
+// Returns the PDU or cabinet comp is grouped in for groupBy. A component fed
+// by more than one PDU is grouped by the first of its PDU connectors in HSM.
+// Components outside of a cabinet are all grouped together.
+func staggerGroup(comp *TransitionComponent, groupBy string) string {
+	if groupBy == model.PowerOnStaggerByPDU && comp.HSMData != nil {
+		for _, connector := range comp.HSMData.PoweredBy {
+			pdu := xnametypes.GetHMSCompParent(xnametypes.NormalizeHMSCompID(connector))
+			if xnametypes.GetHMSType(pdu) == xnametypes.CabinetPDU {
+				return pdu
+			}
+		}
+	}
+	return xnameCabinet(comp.Task.Xname)
+}
+
+// Returns the cabinet xname is in, or "" if it isn't in one.
+func xnameCabinet(xname string) string {
+	for x := xname; x != ""; x = xnametypes.GetHMSCompParent(x) {
+		switch xnametypes.GetHMSType(x) {
+		case xnametypes.Cabinet:
+			return x
+		case xnametypes.HMSTypeInvalid, xnametypes.System:
+			return ""
+		}
+	}
+	return ""
+}
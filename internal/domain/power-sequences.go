@@ -0,0 +1,369 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// DefaultPowerSequenceName is the name of the built-in PowerSequenceFull
+// sequence. Transitions that don't name a sequence use it.
+const DefaultPowerSequenceName = "default"
+
+var powerSequenceNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Power actions understood by sequenceComponents() and doTransition().
+var powerSequenceActions = map[string]bool{
+	"on":               true,
+	"gracefulshutdown": true,
+	"forceoff":         true,
+	"gracefulrestart":  true,
+}
+
+// Component types setupTransitionTasks() accepts for power control.
+var powerSequenceCompTypes = map[xnametypes.HMSType]bool{
+	xnametypes.ChassisBMC:               true,
+	xnametypes.NodeBMC:                  true,
+	xnametypes.RouterBMC:                true,
+	xnametypes.Node:                     true,
+	xnametypes.Chassis:                  true,
+	xnametypes.ComputeModule:            true,
+	xnametypes.RouterModule:             true,
+	xnametypes.MgmtSwitch:               true,
+	xnametypes.MgmtHLSwitch:             true,
+	xnametypes.CDUMgmtSwitch:            true,
+	xnametypes.CabinetPDUPowerConnector: true,
+}
+
+// LoadPowerSequences reads and validates the power sequences file and makes
+// its sequences available to transitions. An empty path loads nothing.
+func LoadPowerSequences(path string) error {
+	GLOB.PowerSequences = make(map[string]model.PowerSequence)
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Unable to read power sequences file %s: %w", path, err)
+	}
+	var seqArray model.PowerSequenceArray
+	err = json.Unmarshal(data, &seqArray)
+	if err != nil {
+		return fmt.Errorf("Unable to parse power sequences file %s: %w", path, err)
+	}
+
+	for _, seq := range seqArray.PowerSequences {
+		if _, ok := GLOB.PowerSequences[seq.Name]; ok {
+			return fmt.Errorf("Duplicate power sequence '%s' in %s", seq.Name, path)
+		}
+		seq, _, err = validatePowerSequence(seq)
+		if err != nil {
+			return fmt.Errorf("Invalid power sequence in %s: %w", path, err)
+		}
+		seq.Source = model.PowerSequenceSourceConfig
+		GLOB.PowerSequences[seq.Name] = seq
+	}
+	logger.Log.Infof("Loaded %d power sequences from %s", len(GLOB.PowerSequences), path)
+	return nil
+}
+
+// GetPowerSequences lists the built-in sequence followed by the configured
+// and API-defined sequences.
+func GetPowerSequences() (pb model.Passback) {
+	rsp := model.PowerSequenceArray{
+		PowerSequences: []model.PowerSequence{toModelPowerSequence(DefaultPowerSequenceName, PowerSequenceFull)},
+	}
+
+	var names []string
+	for name := range GLOB.PowerSequences {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rsp.PowerSequences = append(rsp.PowerSequences, GLOB.PowerSequences[name])
+	}
+
+	seqs, err := (*GLOB.DSP).GetAllPowerSequences()
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving power sequences")
+		return
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i].Name < seqs[j].Name })
+	for _, seq := range seqs {
+		seq.Source = model.PowerSequenceSourceAPI
+		rsp.PowerSequences = append(rsp.PowerSequences, seq)
+	}
+
+	pb = model.BuildSuccessPassback(http.StatusOK, rsp)
+	return
+}
+
+func GetPowerSequence(name string) (pb model.Passback) {
+	seq, err := lookupPowerSequence(name)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving power sequence")
+		return
+	}
+	pb = model.BuildSuccessPassback(http.StatusOK, seq)
+	return
+}
+
+// StorePowerSequence creates or replaces an API-defined power sequence. The
+// built-in and configured sequences can't be replaced.
+func StorePowerSequence(seq model.PowerSequence) (pb model.Passback) {
+	if seq.Name == DefaultPowerSequenceName {
+		err := errors.New("The default power sequence cannot be modified")
+		pb = model.BuildErrorPassback(http.StatusConflict, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing power sequence")
+		return
+	}
+	seq, _, err := validatePowerSequence(seq)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid power sequence")
+		return
+	}
+	if _, ok := GLOB.PowerSequences[seq.Name]; ok {
+		err = fmt.Errorf("Power sequence '%s' is defined in the power sequences file and cannot be modified", seq.Name)
+		pb = model.BuildErrorPassback(http.StatusConflict, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing power sequence")
+		return
+	}
+
+	seq.Source = ""
+	err = (*GLOB.DSP).StorePowerSequence(seq)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing power sequence")
+		return
+	}
+	seq.Source = model.PowerSequenceSourceAPI
+	pb = model.BuildSuccessPassback(http.StatusOK, seq)
+	return
+}
+
+// DeletePowerSequence removes an API-defined power sequence. Running
+// transitions keep the copy they resolved when they started.
+func DeletePowerSequence(name string) (pb model.Passback) {
+	if name == DefaultPowerSequenceName {
+		err := errors.New("The default power sequence cannot be deleted")
+		pb = model.BuildErrorPassback(http.StatusConflict, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error deleting power sequence")
+		return
+	}
+	if _, ok := GLOB.PowerSequences[name]; ok {
+		err := fmt.Errorf("Power sequence '%s' is defined in the power sequences file and cannot be deleted", name)
+		pb = model.BuildErrorPassback(http.StatusConflict, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error deleting power sequence")
+		return
+	}
+
+	_, err := (*GLOB.DSP).GetPowerSequence(name)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving power sequence")
+		return
+	}
+	err = (*GLOB.DSP).DeletePowerSequence(name)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error deleting power sequence")
+		return
+	}
+	pb = model.BuildSuccessPassback(http.StatusNoContent, nil)
+	return
+}
+
+///////////////////////////
+// Non-exported functions (helpers, utils, etc)
+///////////////////////////
+
+// Finds a power sequence by name. An empty name is the default sequence.
+func lookupPowerSequence(name string) (model.PowerSequence, error) {
+	if name == "" || name == DefaultPowerSequenceName {
+		return toModelPowerSequence(DefaultPowerSequenceName, PowerSequenceFull), nil
+	}
+	if seq, ok := GLOB.PowerSequences[name]; ok {
+		return seq, nil
+	}
+	seq, err := (*GLOB.DSP).GetPowerSequence(name)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return seq, fmt.Errorf("Power sequence '%s' does not exist", name)
+		}
+		return seq, err
+	}
+	seq.Source = model.PowerSequenceSourceAPI
+	return seq, nil
+}
+
+// Resolves a power sequence name into the tiers doTransition() walks.
+func getPowerSequence(name string) ([]PowerSeqElem, error) {
+	if name == "" || name == DefaultPowerSequenceName {
+		return PowerSequenceFull, nil
+	}
+	seq, err := lookupPowerSequence(name)
+	if err != nil {
+		return nil, err
+	}
+	_, elems, err := validatePowerSequence(seq)
+	return elems, err
+}
+
+// Checks a power sequence for problems that would leave components stranded
+// mid-transition and normalizes its actions and component types.
+//
+// Rules:
+//   - Names are alphanumeric plus '_', '.', and '-' and can't be "default".
+//   - Actions are on, gracefulshutdown, forceoff, or gracefulrestart.
+//   - Component types must be types PCS can power control.
+//   - An action may only be applied to a component type once.
+//   - A gracefulshutdown must be followed by a forceoff for the same type
+//     because components that time out are handed to the later forceoff.
+//   - An on can't come before the gracefulshutdown or forceoff of the same type.
+func validatePowerSequence(seq model.PowerSequence) (model.PowerSequence, []PowerSeqElem, error) {
+	var elems []PowerSeqElem
+
+	if seq.Name == DefaultPowerSequenceName {
+		return seq, nil, fmt.Errorf("Power sequence name '%s' is reserved", DefaultPowerSequenceName)
+	}
+	if !powerSequenceNameRegex.MatchString(seq.Name) {
+		return seq, nil, fmt.Errorf("Invalid power sequence name '%s'", seq.Name)
+	}
+	if len(seq.Steps) == 0 {
+		return seq, nil, fmt.Errorf("Power sequence '%s' has no steps", seq.Name)
+	}
+
+	// tier each (action, type) pair appears in
+	tiers := make(map[string]map[xnametypes.HMSType]int)
+	for action := range powerSequenceActions {
+		tiers[action] = make(map[xnametypes.HMSType]int)
+	}
+	steps := make(model.PowerSequenceStepSlice, 0, len(seq.Steps))
+	for i, step := range seq.Steps {
+		action := strings.ToLower(step.Action)
+		if !powerSequenceActions[action] {
+			return seq, nil, fmt.Errorf("Power sequence '%s' step %d has invalid action '%s'", seq.Name, i, step.Action)
+		}
+		if len(step.ComponentTypes) == 0 {
+			return seq, nil, fmt.Errorf("Power sequence '%s' step %d has no component types", seq.Name, i)
+		}
+		elem := PowerSeqElem{Action: action}
+		normStep := model.PowerSequenceStep{Action: action}
+		for _, typeStr := range step.ComponentTypes {
+			compType := xnametypes.ToHMSType(typeStr)
+			if !powerSequenceCompTypes[compType] {
+				return seq, nil, fmt.Errorf("Power sequence '%s' step %d has unsupported component type '%s'", seq.Name, i, typeStr)
+			}
+			if _, ok := tiers[action][compType]; ok {
+				return seq, nil, fmt.Errorf("Power sequence '%s' applies %s to %s more than once", seq.Name, action, compType.String())
+			}
+			tiers[action][compType] = i
+			elem.CompTypes = append(elem.CompTypes, compType)
+			normStep.ComponentTypes = append(normStep.ComponentTypes, compType.String())
+		}
+		elems = append(elems, elem)
+		steps = append(steps, normStep)
+	}
+
+	for compType, gsTier := range tiers["gracefulshutdown"] {
+		foTier, ok := tiers["forceoff"][compType]
+		if !ok || foTier < gsTier {
+			return seq, nil, fmt.Errorf("Power sequence '%s' needs a forceoff step for %s after its gracefulshutdown step", seq.Name, compType.String())
+		}
+	}
+	for compType, onTier := range tiers["on"] {
+		for _, offAction := range []string{"gracefulshutdown", "forceoff"} {
+			if offTier, ok := tiers[offAction][compType]; ok && offTier > onTier {
+				return seq, nil, fmt.Errorf("Power sequence '%s' turns %s on before its %s step", seq.Name, compType.String(), offAction)
+			}
+		}
+	}
+
+	seq.Steps = steps
+	return seq, elems, nil
+}
+
+// Converts the tiers doTransition() walks into the API representation.
+func toModelPowerSequence(name string, elems []PowerSeqElem) model.PowerSequence {
+	seq := model.PowerSequence{
+		Name:        name,
+		Description: "Built-in power sequence",
+		Steps:       model.PowerSequenceStepSlice{},
+		Source:      model.PowerSequenceSourceBuiltIn,
+	}
+	for _, elem := range elems {
+		step := model.PowerSequenceStep{
+			Action:         elem.Action,
+			ComponentTypes: []string{},
+		}
+		for _, compType := range elem.CompTypes {
+			step.ComponentTypes = append(step.ComponentTypes, compType.String())
+		}
+		seq.Steps = append(seq.Steps, step)
+	}
+	return seq
+}
+
+// Fails components that need an action their power sequence doesn't apply
+// to their type. Without this they would never leave in-progress. Nothing is
+// stored if dryRun is set.
+func failUnsequencedComps(seqName string, powerSeq []PowerSeqElem, seqMap map[string]map[xnametypes.HMSType][]*TransitionComponent, xnameMap map[string]*TransitionComponent, dryRun bool) {
+	covered := make(map[string]map[xnametypes.HMSType]bool)
+	for _, elem := range powerSeq {
+		if covered[elem.Action] == nil {
+			covered[elem.Action] = make(map[xnametypes.HMSType]bool)
+		}
+		for _, compType := range elem.CompTypes {
+			covered[elem.Action][compType] = true
+		}
+	}
+
+	for action, typeMap := range seqMap {
+		for compType, compList := range typeMap {
+			if covered[action][compType] {
+				continue
+			}
+			for _, comp := range compList {
+				if comp.Task.Status != model.TransitionTaskStatusNew &&
+					comp.Task.Status != model.TransitionTaskStatusInProgress {
+					continue
+				}
+				comp.Task.Status = model.TransitionTaskStatusFailed
+				comp.Task.Error = fmt.Sprintf("Power sequence '%s' does not apply %s to %s", seqName, action, compType.String())
+				comp.Task.StatusDesc = "Failed to achieve transition"
+				depErrMsg := fmt.Sprintf("Power sequence '%s' does not apply %s to dependency, %s.", seqName, action, comp.Task.Xname)
+				if dryRun {
+					continue
+				}
+				failDependentComps(xnameMap, action, comp.Task.Xname, depErrMsg)
+				err := storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
+			}
+		}
+	}
+}
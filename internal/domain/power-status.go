@@ -885,6 +885,13 @@ func monitorHW() {
 			continue
 		}
 
+		//Power states may have been changed by Redfish events received by
+		//any instance since the last sample.
+
+		if GLOB.RedfishEventsURL != "" {
+			syncHWStateMapFromStore()
+		}
+
 		//Update the current power states of all components in the component
 		//map by reading the actual hardware.
 
@@ -898,6 +905,17 @@ func monitorHW() {
 			continue
 		}
 
+		//Make sure BMCs are sending us their power state events.
+
+		if GLOB.RedfishEventsURL != "" {
+			subscribeRedfishEvents()
+		}
+
+		//Put components that have drifted from their desired power states
+		//back.
+
+		reconcileDesiredPowerStates()
+
 		// Find which components have been updated so we can perform
 		// a bulk update on SMD. Note that we may get some false positives as
 		// the the status field may not be the property updated, but for now
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/hsm"
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// The components the protected selectors cover, each with the selector
+// that covers it.
+type protectedSet struct {
+	// Xnames protected by xname, group, or role.
+	xnames map[string]model.ProtectedSelector
+	// Component types protected by type.
+	types map[xnametypes.HMSType]model.ProtectedSelector
+	// Ancestors of protected xnames.
+	above map[string]model.ProtectedSelector
+}
+
+// ConfigureProtectedComponents sets the selectors, each written as
+// kind=value, for components that transitions may only power off or
+// restart when forced, and the token scope needed to force them.
+func ConfigureProtectedComponents(specs []string, forceScope string) error {
+	var selectors []model.ProtectedSelector
+	for _, spec := range specs {
+		selector, err := model.ParseProtectedSelector(spec)
+		if err != nil {
+			return err
+		}
+		selectors = append(selectors, selector)
+	}
+	if forceScope == "" {
+		return errors.New("force scope cannot be empty")
+	}
+	GLOB.ProtectedSelectors = selectors
+	GLOB.ForceScope = forceScope
+	return nil
+}
+
+///////////////////////////
+// Non-exported functions (helpers, utils, etc)
+///////////////////////////
+
+// Reports whether op may power components off.
+func powersOff(op model.Operation) bool {
+	return op != model.Operation_On && op != model.Operation_Nil
+}
+
+// Checks that a request asking to force a transition comes from a token
+// with the force scope.
+func authorizeForce(force bool, requester model.Requester) (pb model.Passback) {
+	if !force || requester.HasScope(GLOB.ForceScope) {
+		return
+	}
+	err := fmt.Errorf("force requires a token with the %s scope", GLOB.ForceScope)
+	pb = model.BuildErrorPassback(http.StatusForbidden, err)
+	logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Unauthorized force")
+	return
+}
+
+// Builds the set of components the protected selectors cover. Group and
+// role members are looked up in HSM.
+func loadProtectedSet() (protectedSet, error) {
+	protected := protectedSet{
+		xnames: make(map[string]model.ProtectedSelector),
+		types:  make(map[xnametypes.HMSType]model.ProtectedSelector),
+		above:  make(map[string]model.ProtectedSelector),
+	}
+	for _, selector := range GLOB.ProtectedSelectors {
+		var xnames []string
+		switch {
+		case selector.Xname != "":
+			xnames = []string{selector.Xname}
+		case selector.ComponentType != "":
+			protected.types[selector.ComponentType] = selector
+		default:
+			members, err := (*GLOB.HSM).GetSelectedComponents(hsm.ComponentSelector{
+				Group:   selector.Group,
+				Role:    selector.Role,
+				SubRole: selector.SubRole,
+			})
+			if err != nil {
+				return protected, fmt.Errorf("error resolving protected selector %s: %w", selector.String(), err)
+			}
+			xnames = members
+		}
+		for _, xname := range xnames {
+			xname = xnametypes.NormalizeHMSCompID(xname)
+			protected.xnames[xname] = selector
+			for _, x := range xnameLineage(xname) {
+				if x != xname {
+					protected.above[x] = selector
+				}
+			}
+		}
+	}
+	return protected, nil
+}
+
+// Returns the selector protecting xname, if it is protected or has
+// protected components below it. Components protected by type are only
+// matched themselves.
+func (p protectedSet) blocking(xname string) (model.ProtectedSelector, bool) {
+	if selector, ok := p.xnames[xname]; ok {
+		return selector, true
+	}
+	if selector, ok := p.types[xnametypes.GetHMSType(xname)]; ok {
+		return selector, true
+	}
+	selector, ok := p.above[xname]
+	return selector, ok
+}
+
+func describeProtected(xname string, selector model.ProtectedSelector) string {
+	return fmt.Sprintf("%s (%s)", xname, selector.String())
+}
+
+// Returns the components of tr, in xname order, that it would power off
+// while they, or components below them, are protected. Returns none if tr
+// powers components on or is forced.
+func checkProtectedComponents(tr model.Transition) ([]string, error) {
+	if len(GLOB.ProtectedSelectors) == 0 || tr.Force || !powersOff(tr.Operation) {
+		return nil, nil
+	}
+	protected, err := loadProtectedSet()
+	if err != nil {
+		return nil, err
+	}
+	requested, _ := xnameHierarchy(tr.Location, nil)
+	xnames := make([]string, 0, len(requested))
+	for xname := range requested {
+		xnames = append(xnames, xname)
+	}
+	sort.Strings(xnames)
+	var found []string
+	for _, xname := range xnames {
+		if selector, ok := protected.blocking(xname); ok {
+			found = append(found, describeProtected(xname, selector))
+		}
+	}
+	return found, nil
+}
+
+// Builds the error for a transition that would power off protected
+// components without being forced.
+func protectedComponentsError(found []string) error {
+	return fmt.Errorf("Transition would power off protected components: %s. "+
+		"Set force with a token that has the %s scope to proceed", strings.Join(found, ", "), GLOB.ForceScope)
+}
+
+// Rejects tr with a 403 if it would power off protected components.
+func rejectProtectedComponents(tr model.Transition) (pb model.Passback) {
+	protected, err := checkProtectedComponents(tr)
+	if err != nil {
+		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error checking protected components")
+		return
+	}
+	if len(protected) > 0 {
+		err = protectedComponentsError(protected)
+		pb = model.BuildErrorPassback(http.StatusForbidden, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Protected components")
+		return
+	}
+	return
+}
+
+// Fails the tasks of protected components, and of components with protected
+// components below them, unless tr is forced. Catches protected components
+// that selectors resolved to when a scheduled transition started. If the
+// protected selectors can't be resolved, every remaining task is failed.
+func failProtectedComps(tr model.Transition, xnameMap map[string]*TransitionComponent) {
+	if len(GLOB.ProtectedSelectors) == 0 || tr.Force || !powersOff(tr.Operation) {
+		return
+	}
+	protected, err := loadProtectedSet()
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Cannot check protected components, cannot continue")
+	}
+	for xname, comp := range xnameMap {
+		if comp.Task.Status != model.TransitionTaskStatusNew &&
+			comp.Task.Status != model.TransitionTaskStatusInProgress {
+			continue
+		}
+		if err != nil {
+			comp.Task.StatusDesc = "Error checking protected components"
+			comp.Task.Error = err.Error()
+		} else if selector, ok := protected.blocking(xname); ok {
+			comp.Task.StatusDesc = "Protected component"
+			comp.Task.Error = fmt.Sprintf("%s is protected and the transition is not forced", describeProtected(xname, selector))
+		} else {
+			continue
+		}
+		comp.Task.Status = model.TransitionTaskStatusFailed
+		if storeErr := storeTransitionTask(comp.Task); storeErr != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": storeErr}).Error("Error storing transition task")
+		}
+	}
+}
@@ -0,0 +1,473 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/OpenCHAMI/smd/v2/pkg/redfish"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+const redfishSubscriptionsURI = "/redfish/v1/EventService/Subscriptions"
+
+// Redfish event tuning.
+var (
+	redfishEventTypes = []string{"Alert", "ResourceEvent"}
+	// How long a BMC is trusted to keep its subscription before it is checked
+	// again, in case it was reset and lost it.
+	redfishEventRecheck = time.Hour
+	// How often the components events are resolved to may be reloaded from
+	// HSM when an event comes from an unknown component.
+	redfishEventReload = time.Minute
+)
+
+// When each BMC, by FQDN, was last found to be subscribed. Only the power
+// status master uses this.
+var redfishEventSubscribed = make(map[string]time.Time)
+
+// The xnames events are resolved to, by BMC FQDN and power status URI.
+var (
+	redfishEventXnames     map[string]map[string]string
+	redfishEventXnamesTime time.Time
+	redfishEventXnamesLock sync.Mutex
+)
+
+// The subscription PCS asks BMCs to create. The context is the BMC's FQDN
+// and a secret kept in storage, so events can be traced back to the BMC and
+// told apart from forged ones.
+type redfishEventSubscription struct {
+	Destination string   `json:"Destination"`
+	Protocol    string   `json:"Protocol"`
+	Context     string   `json:"Context"`
+	EventTypes  []string `json:"EventTypes"`
+}
+
+// The outcome of a request sent by sendRedfishRequests.
+type redfishResult struct {
+	statusCode int
+	body       []byte
+	err        error
+}
+
+// ConfigureRedfishEvents sets the URL BMCs send power state events to. An
+// empty URL leaves Redfish events disabled and power states are only polled.
+func ConfigureRedfishEvents(eventsURL string) error {
+	if err := model.ValidateCallbackURL(eventsURL); err != nil {
+		return fmt.Errorf("invalid Redfish events URL '%s': %w", eventsURL, err)
+	}
+	GLOB.RedfishEventsURL = eventsURL
+	return nil
+}
+
+// IngestRedfishEvent records the power state changes reported in an event
+// from a BMC that PCS subscribed to. Events without the context the BMC was
+// given when subscribed are rejected. Records that aren't power state
+// changes, or that come from unknown components, are ignored.
+func IngestRedfishEvent(event rf.Event) (pb model.Passback) {
+	if GLOB.RedfishEventsURL == "" {
+		err := fmt.Errorf("Redfish events are not enabled")
+		pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Redfish event rejected")
+		return
+	}
+
+	// Newer BMCs put the context on the event, older ones on each record.
+	// Check every context before storing anything.
+	contexts := make(map[string]string)
+	for _, record := range event.Events {
+		context := event.Context
+		if context == "" {
+			context = record.Context
+		}
+		if _, ok := contexts[context]; ok {
+			continue
+		}
+		fqdn, ok, err := authenticateRedfishEvent(context)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving Redfish event subscription")
+			return
+		}
+		if !ok {
+			err = fmt.Errorf("unrecognized Redfish event context")
+			pb = model.BuildErrorPassback(http.StatusUnauthorized, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode, "BMC": fqdn}).Error("Redfish event rejected")
+			return
+		}
+		contexts[context] = fqdn
+	}
+
+	var updated []model.PowerStatusComponent
+	for _, record := range event.Events {
+		powerState := redfishEventPowerState(record)
+		if powerState == model.PowerStateFilter_Undefined {
+			continue
+		}
+		fqdn := contexts[event.Context]
+		if event.Context == "" {
+			fqdn = contexts[record.Context]
+		}
+		xname := redfishEventXname(fqdn, record.OriginOfCondition.Oid)
+		if xname == "" {
+			logger.Log.Debugf("Ignoring Redfish event %s from unknown component %s%s",
+				record.MessageId, fqdn, record.OriginOfCondition.Oid)
+			continue
+		}
+
+		psc, err := (*GLOB.DSP).GetPowerStatus(xname)
+		if err != nil {
+			if strings.Contains(err.Error(), "does not exist") {
+				// The power status master hasn't found it yet.
+				continue
+			}
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving power status")
+			return
+		}
+		powerStateStr := strings.ToLower(powerState.String())
+		mgmtStateStr := strings.ToLower(model.ManagementStateFilter_available.String())
+		if psc.PowerState == powerStateStr && psc.ManagementState == mgmtStateStr {
+			continue
+		}
+		psc.PowerState = powerStateStr
+		psc.ManagementState = mgmtStateStr
+		psc.Error = ""
+		psc.LastUpdated = time.Now()
+		err = (*GLOB.DSP).StorePowerStatus(psc)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing power status")
+			return
+		}
+		logger.Log.Debugf("Redfish event %s set %s %s", record.MessageId, xname, powerStateStr)
+		updated = append(updated, psc)
+	}
+
+	if len(updated) > 0 {
+		err := updateSmdPowerState(updated)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error updating SMD power states from Redfish event")
+		}
+	}
+	pb = model.BuildSuccessPassback(http.StatusNoContent, nil)
+	return
+}
+
+// Returns the FQDN of the BMC named in an event's context, and whether that
+// BMC was given context when it was subscribed.
+func authenticateRedfishEvent(context string) (string, bool, error) {
+	fqdn := model.RedfishEventContextBMC(context)
+	if fqdn == "" {
+		return "", false, nil
+	}
+	sub, err := (*GLOB.DSP).GetRedfishEventSubscription(fqdn)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return fqdn, false, nil
+		}
+		return fqdn, false, err
+	}
+	return fqdn, sub.Matches(context), nil
+}
+
+// Returns the power state a Redfish event record reports, or undefined if
+// it isn't a power state change. Transitional states are left to polling.
+func redfishEventPowerState(record rf.EventRecord) model.PowerStateFilter {
+	// MessageIds are registry.version.message
+	parts := strings.Split(record.MessageId, ".")
+	switch parts[len(parts)-1] {
+	case "ResourcePoweredOn", "ServerPoweredOn", "SystemPowerOn", "ChassisPowerOn":
+		return model.PowerStateFilter_On
+	case "ResourcePoweredOff", "ServerPoweredOff", "SystemPowerOff", "ChassisPowerOff":
+		return model.PowerStateFilter_Off
+	case "ResourcePowerStateChanged":
+		// The new state is the last argument.
+		if len(record.MessageArgs) == 0 {
+			break
+		}
+		switch strings.ToLower(record.MessageArgs[len(record.MessageArgs)-1]) {
+		case "on":
+			return model.PowerStateFilter_On
+		case "off":
+			return model.PowerStateFilter_Off
+		}
+	}
+	return model.PowerStateFilter_Undefined
+}
+
+// Returns the xname of the component whose power status URI is uri on the
+// BMC at fqdn, or an empty string if there isn't one. The components are
+// reloaded from HSM when they're missing, at most once per
+// redfishEventReload.
+func redfishEventXname(fqdn string, uri string) string {
+	if fqdn == "" || uri == "" {
+		return ""
+	}
+	uri = strings.TrimSuffix(uri, "/")
+	redfishEventXnamesLock.Lock()
+	defer redfishEventXnamesLock.Unlock()
+
+	xname, ok := redfishEventXnames[fqdn][uri]
+	if ok || time.Since(redfishEventXnamesTime) < redfishEventReload {
+		return xname
+	}
+	redfishEventXnamesTime = time.Now()
+	compMap, err := (*GLOB.HSM).FillHSMData([]string{"all"})
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error fetching HSM data for Redfish events")
+		return ""
+	}
+	xnames := make(map[string]map[string]string)
+	for id, comp := range compMap {
+		if comp.RfFQDN == "" || comp.PowerStatusURI == "" {
+			continue
+		}
+		if xnames[comp.RfFQDN] == nil {
+			xnames[comp.RfFQDN] = make(map[string]string)
+		}
+		xnames[comp.RfFQDN][strings.TrimSuffix(comp.PowerStatusURI, "/")] = id
+	}
+	redfishEventXnames = xnames
+	return xnames[fqdn][uri]
+}
+
+// Makes sure every reachable BMC in the component map has a subscription
+// sending events to the Redfish events URL with the BMC's stored context,
+// creating the ones that are missing. Subscriptions to the URL with any other
+// context, such as those made before the BMC's context was stored, are
+// replaced. BMCs found subscribed aren't checked again for
+// redfishEventRecheck.
+func subscribeRedfishEvents() {
+	fname := "subscribeRedfishEvents"
+	now := time.Now()
+	available := strings.ToLower(model.ManagementStateFilter_available.String())
+
+	// Any of a BMC's components will do for its address and credentials.
+	bmcs := make(map[string]*componentPowerInfo)
+	for _, comp := range hwStateMap {
+		fqdn := comp.HSMData.RfFQDN
+		if fqdn == "" || comp.PSComp.ManagementState != available {
+			continue
+		}
+		if now.Sub(redfishEventSubscribed[fqdn]) < redfishEventRecheck {
+			continue
+		}
+		if _, ok := bmcs[fqdn]; !ok {
+			bmcs[fqdn] = comp
+		}
+	}
+	if len(bmcs) == 0 {
+		return
+	}
+
+	// List the existing subscriptions.
+	var fqdns []string
+	var reqs []*http.Request
+	for fqdn, comp := range bmcs {
+		fqdns = append(fqdns, fqdn)
+		reqs = append(reqs, newRedfishRequest(http.MethodGet, comp, redfishSubscriptionsURI, nil))
+	}
+	memberFqdns := []string{}
+	memberOids := []string{}
+	reqs2 := []*http.Request{}
+	found := make(map[string]bool)
+	unknown := make(map[string]bool)
+	for i, result := range sendRedfishRequests(reqs) {
+		var collection rf.EventDestinationCollection
+		err := readRedfishResult(result, &collection)
+		if err != nil {
+			logger.Log.Warnf("%s: Unable to list event subscriptions on %s: %v", fname, fqdns[i], err)
+			delete(bmcs, fqdns[i])
+			continue
+		}
+		for _, member := range collection.Members {
+			memberFqdns = append(memberFqdns, fqdns[i])
+			memberOids = append(memberOids, member.Oid)
+			reqs2 = append(reqs2, newRedfishRequest(http.MethodGet, bmcs[fqdns[i]], member.Oid, nil))
+		}
+	}
+
+	// The contexts the BMCs were given, if they have been subscribed before.
+	subs := make(map[string]model.RedfishEventSubscription)
+	for fqdn := range bmcs {
+		sub, err := (*GLOB.DSP).GetRedfishEventSubscription(fqdn)
+		if err != nil {
+			if !strings.Contains(err.Error(), "does not exist") {
+				logger.Log.Warnf("%s: Unable to retrieve event subscription for %s: %v", fname, fqdn, err)
+				unknown[fqdn] = true
+			}
+			continue
+		}
+		subs[fqdn] = sub
+	}
+
+	// Look for ours among them.
+	staleFqdns := []string{}
+	reqs = []*http.Request{}
+	for i, result := range sendRedfishRequests(reqs2) {
+		var subscription rf.EventDestination
+		err := readRedfishResult(result, &subscription)
+		if err != nil {
+			// Don't risk a duplicate subscription.
+			logger.Log.Warnf("%s: Unable to read event subscription on %s: %v", fname, memberFqdns[i], err)
+			unknown[memberFqdns[i]] = true
+			continue
+		}
+		if subscription.Destination != GLOB.RedfishEventsURL {
+			continue
+		}
+		sub, ok := subs[memberFqdns[i]]
+		if ok && sub.Matches(subscription.Context) {
+			found[memberFqdns[i]] = true
+			redfishEventSubscribed[memberFqdns[i]] = now
+			continue
+		}
+		// Events from it would be rejected.
+		staleFqdns = append(staleFqdns, memberFqdns[i])
+		reqs = append(reqs, newRedfishRequest(http.MethodDelete, bmcs[memberFqdns[i]], memberOids[i], nil))
+	}
+	for i, result := range sendRedfishRequests(reqs) {
+		err := readRedfishResult(result, nil)
+		if err != nil {
+			// Try again next time rather than adding a second subscription.
+			logger.Log.Warnf("%s: Unable to remove stale event subscription on %s: %v", fname, staleFqdns[i], err)
+			unknown[staleFqdns[i]] = true
+		}
+	}
+
+	// Subscribe the rest.
+	fqdns = []string{}
+	reqs = []*http.Request{}
+	for fqdn, comp := range bmcs {
+		if found[fqdn] || unknown[fqdn] {
+			continue
+		}
+		sub, ok := subs[fqdn]
+		if !ok {
+			var err error
+			sub, err = model.NewRedfishEventSubscription(fqdn)
+			if err == nil {
+				err = (*GLOB.DSP).StoreRedfishEventSubscription(sub)
+			}
+			if err != nil {
+				logger.Log.Warnf("%s: Unable to store event subscription for %s: %v", fname, fqdn, err)
+				continue
+			}
+		}
+		body, _ := json.Marshal(redfishEventSubscription{
+			Destination: GLOB.RedfishEventsURL,
+			Protocol:    "Redfish",
+			Context:     sub.Context(),
+			EventTypes:  redfishEventTypes,
+		})
+		fqdns = append(fqdns, fqdn)
+		reqs = append(reqs, newRedfishRequest(http.MethodPost, comp, redfishSubscriptionsURI, body))
+	}
+	for i, result := range sendRedfishRequests(reqs) {
+		err := readRedfishResult(result, nil)
+		if err != nil {
+			logger.Log.Warnf("%s: Unable to subscribe to events from %s: %v", fname, fqdns[i], err)
+			continue
+		}
+		logger.Log.Infof("%s: Subscribed to events from %s", fname, fqdns[i])
+		redfishEventSubscribed[fqdns[i]] = now
+	}
+}
+
+// Copies power states stored from Redfish events, which any instance may
+// have received, into the component map so it matches the store.
+func syncHWStateMapFromStore() {
+	stored, err := (*GLOB.DSP).GetAllPowerStatus()
+	if err != nil {
+		logger.Log.Errorf("syncHWStateMapFromStore: ERROR retrieving power status: %v", err)
+		return
+	}
+	for _, psc := range stored.Status {
+		comp, ok := hwStateMap[psc.XName]
+		if !ok || !psc.LastUpdated.After(comp.PSComp.LastUpdated) {
+			continue
+		}
+		comp.PSComp.PowerState = psc.PowerState
+		comp.PSComp.ManagementState = psc.ManagementState
+		comp.PSComp.Error = psc.Error
+		comp.PSComp.LastUpdated = psc.LastUpdated
+	}
+}
+
+// Creates a request to uri on the BMC of comp.
+func newRedfishRequest(method string, comp *componentPowerInfo, uri string, body []byte) *http.Request {
+	req, _ := http.NewRequest(method, "https://"+comp.HSMData.RfFQDN+uri, bytes.NewReader(body))
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Add("HMS-Service", GLOB.BaseTRSTask.ServiceName)
+	if comp.BmcUsername != "" || comp.BmcPassword != "" {
+		req.SetBasicAuth(comp.BmcUsername, comp.BmcPassword)
+	}
+	return req
+}
+
+// Sends reqs through TRS and returns their results in the same order.
+func sendRedfishRequests(reqs []*http.Request) []redfishResult {
+	results := make([]redfishResult, len(reqs))
+	if len(reqs) == 0 {
+		return results
+	}
+	taskList := (*GLOB.RFTloc).CreateTaskList(GLOB.BaseTRSTask, len(reqs))
+	taskIdx := make(map[uuid.UUID]int)
+	for i, req := range reqs {
+		taskList[i].Request = req
+		taskIdx[taskList[i].GetID()] = i
+	}
+	rchan, err := (*GLOB.RFTloc).Launch(&taskList)
+	if err != nil {
+		for i := range results {
+			results[i].err = err
+		}
+		(*GLOB.RFTloc).Close(&taskList)
+		return results
+	}
+	for range taskList {
+		tdone := <-rchan
+		result := &results[taskIdx[tdone.GetID()]]
+		if tdone.Err != nil && *tdone.Err != nil {
+			result.err = *tdone.Err
+			base.DrainAndCloseResponseBody(tdone.Request.Response)
+			continue
+		}
+		if tdone.Request.Response == nil {
+			result.err = fmt.Errorf("no response")
+			continue
+		}
+		result.statusCode = tdone.Request.Response.StatusCode
+		result.body, result.err = io.ReadAll(tdone.Request.Response.Body)
+		base.DrainAndCloseResponseBody(tdone.Request.Response)
+	}
+	(*GLOB.RFTloc).Close(&taskList)
+	close(rchan)
+	return results
+}
+
+// Checks that result succeeded and decodes its body into v, if v isn't nil.
+func readRedfishResult(result redfishResult, v interface{}) error {
+	if result.err != nil {
+		return result.err
+	}
+	if result.statusCode < 200 || result.statusCode >= 300 {
+		return fmt.Errorf("bad status code: %d", result.statusCode)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(result.body, v)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// ConfigureTaskDeadlines sets the task deadlines, each written as
+// action:componentType=minutes, that apply to every transition. A
+// transition's own task deadlines take precedence over these.
+func ConfigureTaskDeadlines(specs []string) error {
+	var deadlines model.TaskDeadlineSlice
+	for _, spec := range specs {
+		deadline, err := model.ParseTaskDeadline(spec)
+		if err != nil {
+			return err
+		}
+		deadlines = append(deadlines, deadline)
+	}
+	GLOB.TaskDeadlines = deadlines
+	return nil
+}
+
+// Returns how long compType components have to finish action in tr. The
+// most specific of tr's task deadlines is used, then the most specific of
+// the global ones, then tr's task deadline, or DefaultWaitForBMCDeadline for
+// the wait for BMCs. Returns false if there is no limit.
+func taskDeadline(tr model.Transition, action string, compType xnametypes.HMSType) (time.Duration, bool) {
+	minutes, ok := tr.TaskDeadlines.Lookup(action, compType.String())
+	if !ok {
+		minutes, ok = GLOB.TaskDeadlines.Lookup(action, compType.String())
+	}
+	if !ok {
+		if action == model.TaskDeadlineWaitForBMC {
+			minutes = model.DefaultWaitForBMCDeadline
+		} else {
+			minutes = tr.TaskDeadline
+		}
+	}
+	if minutes < 0 {
+		return 0, false
+	}
+	return time.Duration(minutes) * time.Minute, true
+}
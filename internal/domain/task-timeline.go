@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// Records the current state of task in its timeline, stores it, and tells
+// the transition's watchers.
+func storeTransitionTask(task *model.TransitionTask) error {
+	action := ""
+	if task.State != model.TaskState_GatherData {
+		action = getPowerActionForOp(task.Operation)
+	}
+	task.RecordTimeline(time.Now(), action)
+	err := (*GLOB.DSP).StoreTransitionTask(*task)
+	if err != nil {
+		return err
+	}
+	publishTransitionEvent(task.TransitionID, model.TransitionEvent{Event: model.TransitionEventTask, Data: taskEvent(*task)})
+	return nil
+}
+
+// The reverse of getOpForPowerAction().
+func getPowerActionForOp(op model.Operation) string {
+	switch op {
+	case model.Operation_Off:
+		return "gracefulshutdown"
+	case model.Operation_SoftRestart:
+		return "gracefulrestart"
+	case model.Operation_ForceOff:
+		return "forceoff"
+	case model.Operation_HardRestart:
+		return "forcerestart"
+	case model.Operation_On:
+		return "on"
+	}
+	return ""
+}
+
+// Works out how long each of tasks spent in each tier of tr's power sequence
+// from their timelines.
+func addTierDurations(tr model.Transition, tasks []model.TransitionTaskResp) {
+	powerSeq, err := getPowerSequence(tr.PowerSequence)
+	if err != nil {
+		// The power sequence may have been deleted since the transition ran.
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Warnf("Unable to find tiers for transition %s", tr.TransitionID.String())
+		return
+	}
+	for i := range tasks {
+		compType := xnametypes.GetHMSType(tasks[i].Xname)
+		tasks[i].TierDurations = tasks[i].Timeline.TierDurations(func(action string) (int, bool) {
+			for tier, elm := range powerSeq {
+				if elm.Action != action {
+					continue
+				}
+				for _, t := range elm.CompTypes {
+					if t == compType {
+						return tier, true
+					}
+				}
+			}
+			return 0, false
+		})
+	}
+}
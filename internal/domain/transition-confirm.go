@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// ConfigureConfirmPolling sets how often, in seconds, transitions that don't
+// set confirmPollSeconds ask BMCs for power states while confirming their
+// actions. Zero leaves them to the stored power status.
+func ConfigureConfirmPolling(seconds int) error {
+	if seconds < 0 || (seconds > 0 && seconds < model.MinConfirmPollSeconds) {
+		return fmt.Errorf("confirm poll interval must be 0 or at least %d seconds", model.MinConfirmPollSeconds)
+	}
+	GLOB.ConfirmPollSeconds = seconds
+	return nil
+}
+
+// The power state of a component as reported by its BMC.
+type polledPowerState struct {
+	PowerState string `json:"PowerState"`
+}
+
+// How often, in seconds, tr asks BMCs for power states while confirming its
+// actions, or 0 to use the stored power status. Transitions stored before the
+// minimum was enforced are held to it here.
+func confirmPollSeconds(tr model.Transition) int {
+	seconds := tr.ConfirmPollSeconds
+	if seconds == 0 {
+		seconds = GLOB.ConfirmPollSeconds
+	}
+	if seconds > 0 && seconds < model.MinConfirmPollSeconds {
+		seconds = model.MinConfirmPollSeconds
+	}
+	return seconds
+}
+
+// Asks the BMCs of comps for their current power states through TRS,
+// bypassing the stored power status. Returns the lowercase power state of
+// each component that answered, keyed by xname. Components that couldn't be
+// asked, or didn't answer with a power state, are left out.
+func pollPowerStates(comps []*TransitionComponent) map[string]string {
+	fname := "pollPowerStates"
+	states := make(map[string]string)
+
+	taskList := (*GLOB.RFTloc).CreateTaskList(GLOB.BaseTRSTask, len(comps))
+	taskComps := make(map[uuid.UUID]*TransitionComponent)
+	taskIdx := 0
+	for _, comp := range comps {
+		if comp.HSMData == nil || comp.HSMData.RfFQDN == "" || comp.HSMData.PowerStatusURI == "" {
+			continue
+		}
+		req, err := http.NewRequest(http.MethodGet, "https://"+comp.HSMData.RfFQDN+comp.HSMData.PowerStatusURI, nil)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("%s: Unable to create request for %s", fname, comp.Task.Xname)
+			continue
+		}
+		req.Header.Set("Accept", "*/*")
+		req.Header.Add("HMS-Service", GLOB.BaseTRSTask.ServiceName)
+		if GLOB.VaultEnabled {
+			user, pw, err := (*GLOB.CS).GetControllerCredentials(comp.Task.Xname)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Unable to get credentials for " + comp.Task.Xname)
+			}
+			if !(user == "" && pw == "") {
+				req.SetBasicAuth(user, pw)
+			}
+		}
+		taskList[taskIdx].Request = req
+		taskComps[taskList[taskIdx].GetID()] = comp
+		taskIdx++
+	}
+	taskList = taskList[:taskIdx]
+	if len(taskList) == 0 {
+		(*GLOB.RFTloc).Close(&taskList)
+		return states
+	}
+
+	rchan, err := (*GLOB.RFTloc).Launch(&taskList)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("%s: TRS Launch() error", fname)
+		(*GLOB.RFTloc).Close(&taskList)
+		return states
+	}
+	for range taskList {
+		tdone := <-rchan
+		comp := taskComps[tdone.GetID()]
+		state, err := readPolledPowerState(comp.Task.Xname, tdone.Request.Response, tdone.Err)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "URI": tdone.Request.URL.String()}).Warnf("%s: Unable to poll power state of %s", fname, comp.Task.Xname)
+			continue
+		}
+		states[comp.Task.Xname] = state
+	}
+	(*GLOB.RFTloc).Close(&taskList)
+	close(rchan)
+	return states
+}
+
+// Reads the power state of xname from its BMC's response. Any successful
+// response from a BMC means the BMC is on.
+func readPolledPowerState(xname string, rsp *http.Response, taskErr *error) (string, error) {
+	if taskErr != nil && *taskErr != nil {
+		base.DrainAndCloseResponseBody(rsp)
+		return "", *taskErr
+	}
+	if rsp == nil {
+		return "", fmt.Errorf("no response")
+	}
+	body, err := io.ReadAll(rsp.Body)
+	base.DrainAndCloseResponseBody(rsp)
+	if err != nil {
+		return "", err
+	}
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return "", fmt.Errorf("bad status code: %d", rsp.StatusCode)
+	}
+	switch xnametypes.GetHMSType(xname) {
+	case xnametypes.NodeBMC, xnametypes.RouterBMC, xnametypes.ChassisBMC:
+		return "on", nil
+	}
+	var info polledPowerState
+	err = json.Unmarshal(body, &info)
+	if err != nil {
+		return "", err
+	}
+	if info.PowerState == "" {
+		return "", fmt.Errorf("no PowerState in response")
+	}
+	return strings.ToLower(info.PowerState), nil
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// Statuses of transitions that hold their components, or are about to.
+var activeTransitionStatuses = []string{
+	model.TransitionStatusNew,
+	model.TransitionStatusInProgress,
+	model.TransitionStatusPaused,
+	model.TransitionStatusAbortSignaled,
+}
+
+// Returns the IDs of the transitions tr has to wait for: active transitions,
+// and queued ones requested before it, that cover any of tr's components or
+// their parents or children. Locations aborted from a running transition
+// don't count.
+func findConflictingTransitions(tr model.Transition) ([]uuid.UUID, error) {
+	var conflicts []uuid.UUID
+	requested, withAncestors := xnameHierarchy(tr.Location, nil)
+	if len(requested) == 0 {
+		return conflicts, nil
+	}
+
+	filter := model.TransitionFilter{
+		Statuses: append([]string{model.TransitionStatusQueued}, activeTransitionStatuses...),
+		Order:    model.TransitionOrderAsc,
+	}
+	transitions, _, err := (*GLOB.DSP).GetTransitions(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range transitions {
+		if other.TransitionID == tr.TransitionID {
+			continue
+		}
+		if other.Status == model.TransitionStatusQueued && !other.CreateTime.Before(tr.CreateTime) {
+			continue
+		}
+		// Large transitions may keep some of their locations in other pages.
+		full, _, err := (*GLOB.DSP).GetTransition(other.TransitionID)
+		if err != nil {
+			if strings.Contains(err.Error(), "does not exist") {
+				// Finished and reaped since it was listed.
+				continue
+			}
+			return nil, err
+		}
+		if locationsOverlap(requested, withAncestors, full.Location, full.AbortedXnames) {
+			conflicts = append(conflicts, other.TransitionID)
+		}
+	}
+	return conflicts, nil
+}
+
+// Builds the set of xnames in locations, less those in exclude, and the set
+// of those xnames plus all of their ancestors.
+func xnameHierarchy(locations []model.LocationParameter, exclude []string) (map[string]bool, map[string]bool) {
+	excluded := make(map[string]bool)
+	for _, xname := range exclude {
+		excluded[xnametypes.NormalizeHMSCompID(xname)] = true
+	}
+	requested := make(map[string]bool)
+	withAncestors := make(map[string]bool)
+	for _, loc := range locations {
+		xname := xnametypes.NormalizeHMSCompID(loc.Xname)
+		if xname == "" || excluded[xname] {
+			continue
+		}
+		requested[xname] = true
+		for _, x := range xnameLineage(xname) {
+			withAncestors[x] = true
+		}
+	}
+	return requested, withAncestors
+}
+
+// Reports whether any of locations is one of the requested xnames, or an
+// ancestor or descendant of one.
+func locationsOverlap(requested, withAncestors map[string]bool, locations []model.LocationParameter, exclude []string) bool {
+	otherRequested, _ := xnameHierarchy(locations, exclude)
+	for xname := range otherRequested {
+		// One of ours is the same as or below this one.
+		if withAncestors[xname] {
+			return true
+		}
+		// This one is below one of ours.
+		for _, x := range xnameLineage(xname) {
+			if requested[x] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns xname followed by each of its ancestors.
+func xnameLineage(xname string) []string {
+	lineage := []string{}
+	for x := xname; x != ""; {
+		lineage = append(lineage, x)
+		parent := xnametypes.GetHMSCompParent(x)
+		if len(parent) >= len(x) {
+			break
+		}
+		x = parent
+	}
+	return lineage
+}
+
+// Starts each queued transition, oldest first, once nothing it conflicts
+// with is still active and it fits in the power budgets. Queued transitions
+// that expire are aborted.
+func startQueuedTransitions() {
+	filter := model.TransitionFilter{
+		Statuses: []string{model.TransitionStatusQueued},
+		Order:    model.TransitionOrderAsc,
+	}
+	queued, _, err := (*GLOB.DSP).GetTransitions(filter)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retreiving queued transitions")
+		return
+	}
+	for _, transition := range queued {
+		transitionOld := transition
+		if transition.AutomaticExpirationTime.Before(time.Now()) {
+			// Nothing has been started so there is nothing to wait on.
+			transition.Status = model.TransitionStatusAborted
+			transition.IsCompressed = true
+		} else {
+			conflicts, err := findConflictingTransitions(transition)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error checking queued transition, %s, for conflicts.", transition.TransitionID.String())
+				continue
+			}
+			if len(conflicts) > 0 {
+				continue
+			}
+			exceeded, err := admitPowerBudget(&transition)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error checking queued transition, %s, against power budgets.", transition.TransitionID.String())
+				continue
+			}
+			if exceeded != "" {
+				continue
+			}
+			transition.Status = model.TransitionStatusNew
+			transition.LastActiveTime = time.Now()
+		}
+		// Use test and set so only one instance starts the transition.
+		ok, err := (*GLOB.DSP).TASTransition(transition, transitionOld)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error starting queued transition, %s.", transition.TransitionID.String())
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if transition.Status == model.TransitionStatusNew {
+			logger.Log.Infof("Queued Transition %s is clear to start (%s)",
+				transition.TransitionID.String(), GLOB.PodName)
+			go doTransition(transition.TransitionID)
+		} else {
+			logger.Log.Infof("Queued Transition %s expired before it could start (%s)",
+				transition.TransitionID.String(), GLOB.PodName)
+		}
+	}
+}
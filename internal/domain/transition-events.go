@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// How often WatchTransition reads a transition from storage when it hasn't
+// heard about it from this instance, as when another instance is running it.
+var transitionEventFallbackInterval = 15 * time.Second
+
+// How many events a watcher may fall behind before it rereads the
+// transition from storage instead.
+const transitionWatcherBuffer = 256
+
+// A WatchTransition call's view of the changes this instance makes to a
+// transition.
+type transitionWatcher struct {
+	events chan model.TransitionEvent
+	// Signaled when events were dropped because the watcher fell behind.
+	missed chan struct{}
+}
+
+var (
+	transitionWatchersMu sync.Mutex
+	transitionWatchers   = make(map[uuid.UUID]map[*transitionWatcher]struct{})
+)
+
+// WatchTransition calls send with a task event each time one of the
+// transition's tasks is seen with a new status or status description, and
+// with a complete event once the transition has finished. The first set of
+// task events describes every task that exists when the watch starts.
+//
+// Changes made by this instance are sent as they are stored. The transition
+// is only read from storage when the watch starts, when the watcher falls
+// behind, and every transitionEventFallbackInterval without a change from
+// this instance, so transitions run by other instances are followed too.
+// Changes made between two reads are reported as one event.
+//
+// Returns nil after the complete event, or the first error from storage,
+// send, or ctx.
+func WatchTransition(ctx context.Context, transitionID uuid.UUID, send func(model.TransitionEvent) error) error {
+	watcher, unwatch := watchTransition(transitionID)
+	defer unwatch()
+
+	seen := make(map[string]model.TransitionTaskResp)
+	sendTask := func(task model.TransitionTaskResp) error {
+		last, ok := seen[task.Xname]
+		if ok && last.TaskStatus == task.TaskStatus && last.TaskStatusDesc == task.TaskStatusDesc {
+			return nil
+		}
+		seen[task.Xname] = task
+		return send(model.TransitionEvent{Event: model.TransitionEventTask, Data: task})
+	}
+	sendComplete := func(rsp model.TransitionResp) error {
+		// Catch up on anything that changed since the last event.
+		for _, task := range rsp.Tasks {
+			task.Timeline = nil
+			err := sendTask(task)
+			if err != nil {
+				return err
+			}
+		}
+		rsp.Tasks = nil
+		return send(model.TransitionEvent{Event: model.TransitionEventComplete, Data: rsp})
+	}
+
+	// Reads the transition from storage. Returns true once it has completed.
+	poll := func() (bool, error) {
+		tasks, err := (*GLOB.DSP).GetAllTasksForTransition(transitionID)
+		if err != nil {
+			return false, err
+		}
+		for _, task := range tasks {
+			err = sendTask(taskEvent(task))
+			if err != nil {
+				return false, err
+			}
+		}
+
+		// Completing a transition moves its tasks into the transition record
+		// and deletes them. Only look at the (possibly paged) transition
+		// itself once that may have happened, or before it has any tasks.
+		if len(tasks) > 0 && len(tasks) >= len(seen) {
+			return false, nil
+		}
+		transition, _, err := (*GLOB.DSP).GetTransition(transitionID)
+		if err != nil {
+			return false, err
+		}
+		if transition.TransitionID != transitionID {
+			return false, errors.New("TransitionID does not exist")
+		}
+		if !transition.IsCompressed {
+			return false, nil
+		}
+		return true, sendComplete(model.ToTransitionResp(transition, nil, true))
+	}
+
+	done, err := poll()
+	if done || err != nil {
+		return err
+	}
+	ticker := time.NewTicker(transitionEventFallbackInterval)
+	defer ticker.Stop()
+	heard := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-watcher.events:
+			heard = time.Now()
+			if event.Event == model.TransitionEventComplete {
+				return sendComplete(event.Data.(model.TransitionResp))
+			}
+			err = sendTask(event.Data.(model.TransitionTaskResp))
+		case <-watcher.missed:
+			done, err = poll()
+		case <-ticker.C:
+			if time.Since(heard) < transitionEventFallbackInterval {
+				continue
+			}
+			done, err = poll()
+		}
+		if done || err != nil {
+			return err
+		}
+	}
+}
+
+///////////////////////////
+// Non-exported functions (helpers, utils, etc)
+///////////////////////////
+
+// Registers a watcher for the changes this instance makes to a transition.
+// The returned func unregisters it.
+func watchTransition(transitionID uuid.UUID) (*transitionWatcher, func()) {
+	watcher := &transitionWatcher{
+		events: make(chan model.TransitionEvent, transitionWatcherBuffer),
+		missed: make(chan struct{}, 1),
+	}
+	transitionWatchersMu.Lock()
+	defer transitionWatchersMu.Unlock()
+	if transitionWatchers[transitionID] == nil {
+		transitionWatchers[transitionID] = make(map[*transitionWatcher]struct{})
+	}
+	transitionWatchers[transitionID][watcher] = struct{}{}
+	return watcher, func() {
+		transitionWatchersMu.Lock()
+		defer transitionWatchersMu.Unlock()
+		delete(transitionWatchers[transitionID], watcher)
+		if len(transitionWatchers[transitionID]) == 0 {
+			delete(transitionWatchers, transitionID)
+		}
+	}
+}
+
+// Hands event to the transition's watchers without waiting on them. A
+// watcher that has fallen behind is told to reread the transition instead.
+func publishTransitionEvent(transitionID uuid.UUID, event model.TransitionEvent) {
+	transitionWatchersMu.Lock()
+	defer transitionWatchersMu.Unlock()
+	for watcher := range transitionWatchers[transitionID] {
+		select {
+		case watcher.events <- event:
+		default:
+			select {
+			case watcher.missed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func taskEvent(task model.TransitionTask) model.TransitionTaskResp {
+	return model.TransitionTaskResp{
+		Xname:          task.Xname,
+		TaskStatus:     task.Status,
+		TaskStatusDesc: task.StatusDesc,
+		Error:          task.Error,
+	}
+}
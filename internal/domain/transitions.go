@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -62,6 +63,12 @@ type PowerSupply struct {
 	State model.PowerStateFilter
 }
 
+// How often a paused transition checks whether it has been resumed.
+var pausePollInterval = time.Duration(model.TransitionKeepAliveInterval) * time.Second
+
+// How often waitForBMC checks whether BMCs have become ready.
+var waitForBMCInterval = 15 * time.Second
+
 var PowerSequenceFull = []PowerSeqElem{
 	{
 		Action:    "gracefulshutdown",
@@ -113,7 +120,9 @@ var PowerSequenceFull = []PowerSeqElem{
 	},
 }
 
-func GetTransition(transitionID uuid.UUID) (pb model.Passback) {
+// GetTransition returns a transition with all of its tasks. With timeline
+// set, each task's timeline and the time it spent in each tier are included.
+func GetTransition(transitionID uuid.UUID, timeline bool) (pb model.Passback) {
 	var tasks []model.TransitionTask
 	// Get the transition
 	transition, _, err := (*GLOB.DSP).GetTransition(transitionID)
@@ -145,14 +154,21 @@ func GetTransition(transitionID uuid.UUID) (pb model.Passback) {
 
 	// Build the response struct
 	rsp := model.ToTransitionResp(transition, tasks, true)
+	if timeline {
+		addTierDurations(transition, rsp.Tasks)
+	} else {
+		for i := range rsp.Tasks {
+			rsp.Tasks[i].Timeline = nil
+		}
+	}
 
 	pb = model.BuildSuccessPassback(http.StatusOK, rsp)
 	return
 }
 
-func GetTransitionStatuses() (pb model.Passback) {
-	// Get all transitions
-	transitions, err := (*GLOB.DSP).GetAllTransitions()
+func GetTransitionStatuses(filter model.TransitionFilter) (pb model.Passback) {
+	// Get the matching transitions
+	transitions, nextCursor, err := (*GLOB.DSP).GetTransitions(filter)
 	if err != nil {
 		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
 		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transitions")
@@ -161,6 +177,7 @@ func GetTransitionStatuses() (pb model.Passback) {
 
 	rsp := model.TransitionRespArray{
 		Transitions: []model.TransitionResp{},
+		NextCursor:  nextCursor,
 	}
 	// Get the tasks for each transition
 	for _, transition := range transitions {
@@ -203,7 +220,8 @@ func AbortTransitionID(transitionID uuid.UUID) (pb model.Passback) {
 			pb = model.BuildErrorPassback(http.StatusNotFound, err)
 			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition")
 		}
-		if transition.Status == model.TransitionStatusCompleted {
+		if transition.Status == model.TransitionStatusCompleted ||
+			transition.Status == model.TransitionStatusHalted {
 			err := errors.New("Transition is already finished and cannot be aborted.")
 			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
 			return
@@ -213,7 +231,15 @@ func AbortTransitionID(transitionID uuid.UUID) (pb model.Passback) {
 			pb = model.BuildSuccessPassback(http.StatusAccepted, abortResp)
 			return
 		}
-		transition.Status = model.TransitionStatusAbortSignaled
+		if transition.Status == model.TransitionStatusScheduled ||
+			transition.Status == model.TransitionStatusQueued {
+			// Nothing has been started so there is nothing to wait on. Aborting
+			// a recurring transition also stops any further occurrences.
+			transition.Status = model.TransitionStatusAborted
+			transition.IsCompressed = true
+		} else {
+			transition.Status = model.TransitionStatusAbortSignaled
+		}
 		// Use test and set to prevent overwriting another thread's store operation.
 		ok, err := (*GLOB.DSP).TASTransition(transition, transitionFirstPage)
 		if err != nil {
@@ -234,23 +260,421 @@ func AbortTransitionID(transitionID uuid.UUID) (pb model.Passback) {
 	return
 }
 
+// AbortTransitionTask removes a single component from a transition. The
+// instance running the transition aborts the component's task, releases its
+// reservation, and fails anything that depends on it.
+func AbortTransitionTask(transitionID uuid.UUID, xname string) (pb model.Passback) {
+	for retry := 0; retry < 3; retry++ {
+		// Get the transition
+		transition, transitionFirstPage, err := (*GLOB.DSP).GetTransition(transitionID)
+		if err != nil {
+			if strings.Contains(err.Error(), "does not exist") {
+				pb = model.BuildErrorPassback(http.StatusNotFound, err)
+			} else {
+				pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			}
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition")
+			return
+		}
+		if transition.TransitionID.String() != transitionID.String() {
+			err := errors.New("TransitionID does not exist")
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition")
+			return
+		}
+		if transition.Status == model.TransitionStatusCompleted ||
+			transition.Status == model.TransitionStatusAborted ||
+			transition.Status == model.TransitionStatusHalted {
+			err := errors.New("Transition is already finished and cannot be aborted.")
+			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+			return
+		}
+		abortResp := model.TransitionAbortResp{AbortStatus: "Accepted - abort initiated"}
+		if transition.Status == model.TransitionStatusAbortSignaled {
+			// The whole transition is already being aborted.
+			pb = model.BuildSuccessPassback(http.StatusAccepted, abortResp)
+			return
+		}
+
+		// Tasks are created for the requested components, and any that get
+		// added, once the transition starts.
+		found := false
+		tasks, err := (*GLOB.DSP).GetAllTasksForTransition(transitionID)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition tasks")
+			return
+		}
+		for _, task := range tasks {
+			if task.Xname != xname {
+				continue
+			}
+			if task.Status != model.TransitionTaskStatusNew &&
+				task.Status != model.TransitionTaskStatusInProgress {
+				err := errors.New("Task is already finished and cannot be aborted.")
+				pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+				return
+			}
+			found = true
+		}
+		for _, loc := range transition.Location {
+			if loc.Xname == xname {
+				found = true
+			}
+		}
+		if !found {
+			err := fmt.Errorf("Component, %s, is not part of the transition", xname)
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+			return
+		}
+		for _, aborted := range transition.AbortedXnames {
+			if aborted == xname {
+				pb = model.BuildSuccessPassback(http.StatusAccepted, abortResp)
+				return
+			}
+		}
+
+		transition.AbortedXnames = append(transition.AbortedXnames, xname)
+		// Use test and set to prevent overwriting another thread's store operation.
+		ok, err := (*GLOB.DSP).TASTransition(transition, transitionFirstPage)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing transition")
+			return
+		}
+		if ok {
+			pb = model.BuildSuccessPassback(http.StatusAccepted, abortResp)
+			return
+		}
+	}
+
+	err := errors.New("Failed to signal abort")
+	pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+	logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing aborted component")
+	return
+}
+
+// PauseTransition signals a transition to stop sending power actions. The
+// transition holds its reservations and finishes confirming actions already
+// sent, but won't start another tier or batch until it is resumed.
+func PauseTransition(transitionID uuid.UUID) (pb model.Passback) {
+	return setTransitionPaused(transitionID, true)
+}
+
+// ResumeTransition lets a paused transition carry on where it left off.
+func ResumeTransition(transitionID uuid.UUID) (pb model.Passback) {
+	return setTransitionPaused(transitionID, false)
+}
+
+func setTransitionPaused(transitionID uuid.UUID, pause bool) (pb model.Passback) {
+	for retry := 0; retry < 3; retry++ {
+		// Get the transition
+		transition, transitionFirstPage, err := (*GLOB.DSP).GetTransition(transitionID)
+		if err != nil {
+			if strings.Contains(err.Error(), "does not exist") {
+				pb = model.BuildErrorPassback(http.StatusNotFound, err)
+			} else {
+				pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			}
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition")
+			return
+		}
+		if transition.TransitionID.String() != transitionID.String() {
+			err := errors.New("TransitionID does not exist")
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition")
+			return
+		}
+
+		var resp model.TransitionPauseResp
+		if pause {
+			resp.PauseStatus = "Accepted - pause initiated"
+			switch transition.Status {
+			case model.TransitionStatusPaused:
+				pb = model.BuildSuccessPassback(http.StatusAccepted, resp)
+				return
+			case model.TransitionStatusNew, model.TransitionStatusInProgress:
+				transition.Status = model.TransitionStatusPaused
+			default:
+				err := fmt.Errorf("Transition is %s and cannot be paused.", transition.Status)
+				pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+				return
+			}
+		} else {
+			resp.PauseStatus = "Accepted - resumed"
+			if transition.Status != model.TransitionStatusPaused {
+				err := errors.New("Transition is not paused.")
+				pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+				return
+			}
+			transition.Status = model.TransitionStatusInProgress
+		}
+
+		// Use test and set to prevent overwriting another thread's store operation.
+		ok, err := (*GLOB.DSP).TASTransition(transition, transitionFirstPage)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing transition")
+			return
+		}
+		if ok {
+			pb = model.BuildSuccessPassback(http.StatusAccepted, resp)
+			return
+		}
+	}
+
+	err := errors.New("Failed to store transition status")
+	pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+	logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing paused status")
+	return
+}
+
+// CreateTransition starts transition, built from parameters. A repeat of a
+// request made with the same Idempotency-Key gets the original response
+// instead of starting another transition.
+func CreateTransition(parameters model.TransitionParameter, transition model.Transition) (pb model.Passback) {
+	pb = authorizeForce(parameters.Force, parameters.Requester)
+	if pb.IsError {
+		return
+	}
+	pb = checkCallbackURL(parameters.CallbackURL)
+	if pb.IsError {
+		return
+	}
+	return startOnce(model.IdempotencyScopeTransition, parameters.Requester, parameters.IdempotencyKey, parameters,
+		decodeTransitionCreation, func() model.Passback {
+			return TriggerTransition(transition)
+		})
+}
+
 func TriggerTransition(transition model.Transition) (pb model.Passback) {
+	// Make sure the power sequence exists before accepting the transition
+	_, err := getPowerSequence(transition.PowerSequence)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving power sequence")
+		return
+	}
+
+	// Catch transitions on the same components now rather than when their
+	// reservations fail. Scheduled transitions are resolved and checked when
+	// they're due, but the xnames they name are checked for protected
+	// components now so the caller finds out right away.
+	if transition.Status == model.TransitionStatusScheduled {
+		named := transition
+		named.Location = nil
+		for _, loc := range transition.Location {
+			if len(loc.Expand) == 0 {
+				named.Location = append(named.Location, loc)
+			}
+		}
+		pb = rejectProtectedComponents(named)
+		if pb.IsError {
+			return
+		}
+	} else {
+		pb = resolveTransitionLocations(&transition)
+		if pb.IsError {
+			return
+		}
+		frozen, err := checkTransitionFreezes(transition)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error checking freezes")
+			return
+		}
+		if frozen != "" {
+			err = fmt.Errorf("Components are frozen: %s", frozen)
+			pb = model.BuildErrorPassback(http.StatusConflict, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Frozen components")
+			return
+		}
+		pb = rejectProtectedComponents(transition)
+		if pb.IsError {
+			return
+		}
+		conflicts, err := findConflictingTransitions(transition)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error checking for conflicting transitions")
+			return
+		}
+		if len(conflicts) > 0 {
+			if transition.ConflictPolicy != model.TransitionConflictQueue {
+				ids := make([]string, 0, len(conflicts))
+				for _, id := range conflicts {
+					ids = append(ids, id.String())
+				}
+				err = fmt.Errorf("Components are in use by active transitions: %s", strings.Join(ids, ", "))
+				pb = model.BuildErrorPassback(http.StatusConflict, err)
+				logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Conflicting transition")
+				return
+			}
+			transition.Status = model.TransitionStatusQueued
+		} else {
+			exceeded, err := admitPowerBudget(&transition)
+			if err != nil {
+				pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+				logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error checking power budgets")
+				return
+			}
+			if exceeded != "" {
+				if transition.BudgetPolicy != model.PowerBudgetPolicyQueue {
+					err = fmt.Errorf("Transition would exceed power budget: %s", exceeded)
+					pb = model.BuildErrorPassback(http.StatusConflict, err)
+					logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Power budget exceeded")
+					return
+				}
+				transition.Status = model.TransitionStatusQueued
+			}
+		}
+	}
 
 	// Store transition
-	err := (*GLOB.DSP).StoreTransition(transition)
+	err = (*GLOB.DSP).StoreTransition(transition)
 	if err != nil {
 		pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
 		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error storing new transition")
 		return
 	}
 
-	// Start transition
-	go doTransition(transition.TransitionID)
-
 	rsp := model.TransitionCreation{
-		TransitionID: transition.TransitionID,
-		Operation:    transition.Operation.String(),
+		TransitionID:    transition.TransitionID,
+		Operation:       transition.Operation.String(),
+		BudgetUnchecked: transition.BudgetUnchecked,
+	}
+
+	// Scheduled transitions get started by the reaper once they're due, and
+	// queued ones once their conflicts finish and they fit in the power
+	// budgets.
+	if transition.Status == model.TransitionStatusScheduled {
+		rsp.TransitionStatus = transition.Status
+		rsp.StartAt = transition.StartAt
+	} else if transition.Status == model.TransitionStatusQueued {
+		rsp.TransitionStatus = transition.Status
+	} else {
+		go doTransition(transition.TransitionID)
+	}
+	pb = model.BuildSuccessPassback(http.StatusOK, rsp)
+	return
+}
+
+// RetryTransition creates and starts a new transition for the components
+// whose tasks failed in a finished transition. Only failed tasks with an
+// error containing errorFilter are retried, if it is set.
+func RetryTransition(transitionID uuid.UUID, parameters model.TransitionRetryParameter) (pb model.Passback) {
+	pb = authorizeForce(parameters.Force, parameters.Requester)
+	if pb.IsError {
+		return
+	}
+	transition, _, err := (*GLOB.DSP).GetTransition(transitionID)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusNotFound, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition")
+		return
+	}
+	if transition.Status != model.TransitionStatusCompleted &&
+		transition.Status != model.TransitionStatusAborted &&
+		transition.Status != model.TransitionStatusHalted {
+		err := errors.New("Transition is not finished and cannot be retried.")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		return
+	}
+
+	// Compressed transitions carry their tasks with them. Otherwise they are
+	// still stored separately.
+	tasks := transition.Tasks
+	if !transition.IsCompressed {
+		taskList, err := (*GLOB.DSP).GetAllTasksForTransition(transition.TransitionID)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving transition tasks")
+			return
+		}
+		tasks = model.ToTransitionResp(transition, taskList, true).Tasks
+	}
+
+	deputyKeys := make(map[string]string)
+	for _, loc := range transition.Location {
+		deputyKeys[loc.Xname] = loc.DeputyKey
+	}
+	var location []model.LocationParameter
+	for _, task := range tasks {
+		if task.TaskStatus != model.TransitionTaskStatusFailed {
+			continue
+		}
+		if parameters.ErrorFilter != "" && !strings.Contains(task.Error, parameters.ErrorFilter) {
+			continue
+		}
+		location = append(location, model.LocationParameter{
+			Xname:     task.Xname,
+			DeputyKey: deputyKeys[task.Xname],
+		})
+	}
+	if len(location) == 0 {
+		err := errors.New("Transition has no failed tasks to retry.")
+		pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		return
+	}
+
+	retry := model.NewRetryTransition(transition, location, GLOB.ExpireTimeMins)
+	retry.RequesterSubject = parameters.Requester.Subject
+	retry.RequesterIssuer = parameters.Requester.Issuer
+	retry.FreezeOverride = parameters.Requester.FreezeOverride
+	retry.Force = parameters.Force
+	logger.Log.Infof("Retrying %d failed components of Transition %s as Transition %s",
+		len(location), transition.TransitionID.String(), retry.TransitionID.String())
+	return TriggerTransition(retry)
+}
+
+// PlanTransition computes what a transition would do without reserving
+// components, storing anything, or sending any Redfish requests.
+func PlanTransition(transition model.Transition) (pb model.Passback) {
+	var (
+		missing []string
+		seqMap  map[string]map[xnametypes.HMSType][]*TransitionComponent
+	)
+
+	powerSeq, err := getPowerSequence(transition.PowerSequence)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving power sequence")
+		return
+	}
+
+	pb = resolveTransitionLocations(&transition)
+	if pb.IsError {
+		return
+	}
+
+	xnameMap, xnames := setupTransitionTasks(&transition, true)
+	if len(xnames) > 0 {
+		var found bool
+		missing, found, err = gatherTransitionData(&transition, xnameMap, xnames, true)
+		if err != nil {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error retrieving power states")
+			return
+		}
+		if found {
+			seqMap, _ = sequenceComponents(transition.Operation, xnameMap, true)
+			failUnsequencedComps(transition.PowerSequence, powerSeq, seqMap, xnameMap, true)
+		}
 	}
+
+	rsp := buildTransitionPlan(transition, powerSeq, xnameMap, seqMap, missing)
 	pb = model.BuildSuccessPassback(http.StatusOK, rsp)
 	return
 }
@@ -259,13 +683,26 @@ func TriggerTransition(transition model.Transition) (pb model.Passback) {
 // Non-exported functions (helpers, utils, etc)
 ///////////////////////////
 
+// Resolves the location selectors of a transition about to be started or
+// planned, returning an error passback if they can't be resolved.
+func resolveTransitionLocations(transition *model.Transition) (pb model.Passback) {
+	err := resolveLocationSelectors(transition)
+	if err != nil {
+		if errors.Is(err, errEmptySelection) {
+			pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+		} else {
+			pb = model.BuildErrorPassback(http.StatusInternalServerError, err)
+		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Error resolving location selectors")
+	}
+	return
+}
+
 // Main worker for executing transitions
 func doTransition(transitionID uuid.UUID) {
 	var (
-		xnameHierarchy []string
-		isSoft         bool
-		noWait         bool
-		waitForever    bool
+		isSoft bool
+		noWait bool
 	)
 
 	fname := "doTransition"
@@ -284,8 +721,11 @@ func doTransition(transitionID uuid.UUID) {
 		logger.Log.Infof("Restarting Transition %s (%s)",
 			tr.TransitionID.String(), GLOB.PodName)
 		if tr.Status == model.TransitionStatusCompleted ||
-			tr.Status == model.TransitionStatusAborted {
-			// Shouldn't pick up completed Transitions anyway
+			tr.Status == model.TransitionStatusAborted ||
+			tr.Status == model.TransitionStatusHalted ||
+			tr.Status == model.TransitionStatusScheduled ||
+			tr.Status == model.TransitionStatusQueued {
+			// Shouldn't pick up completed, not yet due, or queued Transitions anyway
 			return
 		}
 	} else {
@@ -304,13 +744,11 @@ func doTransition(transitionID uuid.UUID) {
 
 	if tr.TaskDeadline == 0 {
 		noWait = true
-	} else if tr.TaskDeadline < 0 {
-		waitForever = true
 	}
 
 	// Vet and turn the list of requested xnames into a map. This also
 	// checks for previously created tasks for restarted transitions.
-	xnameMap, xnames := setupTransitionTasks(&tr)
+	xnameMap, xnames := setupTransitionTasks(&tr, false)
 
 	if len(xnames) == 0 {
 		// All xnames were invalid
@@ -320,229 +758,61 @@ func doTransition(transitionID uuid.UUID) {
 		return
 	}
 
-	// Store the transition with its initial set of tasks. May have more added later.
-	tr.Status = model.TransitionStatusInProgress
-	abortSignaled, err := storeTransition(tr)
+	// Resolve the power sequence now so a sequence that was deleted while
+	// this transition was waiting to be restarted fails it cleanly.
+	powerSeq, err := getPowerSequence(tr.PowerSequence)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition")
-	}
-	if abortSignaled {
-		doAbort(tr, xnameMap)
-		return
-	}
-
-	///////////////////////////////////////////////////////////////////////////
-	// o Vet XNames with our internal stored Power Status. Should have everything
-	//   HSM has plus a recently captured power state from hardware.
-	///////////////////////////////////////////////////////////////////////////
-
-	// Expand the list of xnames to include power controlled subcomponents. This way we
-	// already have the information for additional components we might need to add.
-	pStates, missingXnames, err := getPowerStateHierarchy(xnames)
-	if err != nil {
-		// This failed to an ETCD error. Likely because we couldn't reach it
-		// which means we really don't have a way to inform anyone about this
-		// failed job because we'd need to do a STORE to mark the job as failed.
-		// TODO: Maybe retry indefinitely? For now exit. Another instance
-		//       (possibly us) will restart this transition later.
-		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Cannot retrieve Power States, cannot continue")
-		cancelChan <- true
-		return
-	}
-
-	// Finish out tasks for components that were not found or we cannot power control.
-	if len(missingXnames) > 0 {
-		logrus.WithFields(logrus.Fields{"ERROR": err, "xnames": missingXnames}).Error("Missing xnames detected")
-		for _, xname := range missingXnames {
-			comp, ok := xnameMap[xname]
-			if !ok {
-				// We don't care about xnames not in our list
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Cannot retrieve power sequence, cannot continue")
+		for _, comp := range xnameMap {
+			if comp.Task.Status != model.TransitionTaskStatusNew &&
+				comp.Task.Status != model.TransitionTaskStatusInProgress {
 				continue
 			}
-			// Set failures for each listed xname
 			comp.Task.Status = model.TransitionTaskStatusFailed
-			compType := xnametypes.GetHMSType(xname)
-			if compType != xnametypes.Chassis &&
-				compType != xnametypes.ComputeModule &&
-				compType != xnametypes.Node &&
-				compType != xnametypes.RouterModule &&
-				compType != xnametypes.CabinetPDUPowerConnector &&
-				compType != xnametypes.ChassisBMC &&
-				compType != xnametypes.NodeBMC &&
-				compType != xnametypes.RouterBMC &&
-				compType != xnametypes.MgmtSwitch &&
-				compType != xnametypes.MgmtHLSwitch &&
-				compType != xnametypes.CDUMgmtSwitch {
-				comp.Task.Error = "No power control for component type " + compType.String()
-			} else {
-				comp.Task.Error = "Missing xname"
-			}
-			comp.Task.StatusDesc = "Failed to achieve transition"
-			err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
+			comp.Task.Error = err.Error()
+			comp.Task.StatusDesc = "Error retrieving power sequence"
+			err = storeTransitionTask(comp.Task)
 			if err != nil {
 				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 			}
 		}
-	}
-	if len(pStates) == 0 {
-		// No xnames found
-		err = errors.New("No xnames to operate on")
-		logrus.WithFields(logrus.Fields{"ERROR": err}).Error("No xnames to operate on")
 		compressAndCompleteTransition(tr, model.TransitionStatusCompleted)
 		return
 	}
 
-	for xname := range pStates {
-		xnameHierarchy = append(xnameHierarchy, xname)
-	}
-
-	///////////////////////////////////////////////////////////////////////////
-	// o Get the component state and ComponentEndpoint data from HSM.
-	///////////////////////////////////////////////////////////////////////////
-
-	hsmData, err := (*GLOB.HSM).FillHSMData(xnameHierarchy)
+	// Store the transition with its initial set of tasks. May have more added later.
+	tr.Status = model.TransitionStatusInProgress
+	abortSignaled, err := storeTransition(tr)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving HSM data")
-		// Failed to get data from HSM. Fail everything.
-		for _, xname := range xnames {
-			comp, ok := xnameMap[xname]
-			if !ok {
-				// We don't care about xnames not in our list
-				continue
-			}
-			if comp.Task.Status != model.TransitionTaskStatusNew &&
-				comp.Task.Status != model.TransitionTaskStatusInProgress {
-				// Skip it if it is already complete
-				continue
-			}
-			comp.Task.Status = model.TransitionTaskStatusFailed
-			comp.Task.Error = "Error retrieving HSM data"
-			comp.Task.StatusDesc = "Failed to achieve transition"
-			err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-			if err != nil {
-				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-			}
-		}
-	} else {
-		// Check to see if we got everything back.
-		if len(hsmData) != len(xnameHierarchy) {
-			for _, xname := range xnames {
-				// This xname was not found in the response from HSM.
-				// Set a failed "Not found" task for it.
-				if _, ok := hsmData[xname]; !ok {
-					comp, ok := xnameMap[xname]
-					if !ok {
-						// We don't care about xnames not in our list
-						continue
-					}
-					if comp.Task.Status != model.TransitionTaskStatusNew &&
-						comp.Task.Status != model.TransitionTaskStatusInProgress {
-						// Skip it if it is already complete
-						continue
-					}
-
-					comp.Task.Status = model.TransitionTaskStatusFailed
-					comp.Task.Error = "Xname not found in HSM"
-					comp.Task.StatusDesc = "Failed to achieve transition"
-					err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-					if err != nil {
-						logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-					}
-				}
-			}
-		}
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition")
+	}
+	if abortSignaled {
+		doAbort(tr, xnameMap)
+		return
 	}
 
 	///////////////////////////////////////////////////////////////////////////
-	// o Get the power maps data from HSM.
+	// o Vet XNames with our internal stored Power Status and get the
+	//   component state and ComponentEndpoint data from HSM.
 	///////////////////////////////////////////////////////////////////////////
-	err = (*GLOB.HSM).FillPowerMapData(hsmData)
+
+	_, found, err := gatherTransitionData(&tr, xnameMap, xnames, false)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving HSM power maps data")
-		// Failed to get data from HSM. Fail everything.
-		for _, xname := range xnames {
-			comp, ok := xnameMap[xname]
-			if !ok {
-				// We don't care about xnames not in our list
-				continue
-			}
-			if comp.Task.Status != model.TransitionTaskStatusNew &&
-				comp.Task.Status != model.TransitionTaskStatusInProgress {
-				// Skip it if it is already complete
-				continue
-			}
-			comp.Task.Status = model.TransitionTaskStatusFailed
-			comp.Task.Error = "Error retrieving HSM data"
-			comp.Task.StatusDesc = "Failed to achieve transition"
-			err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-			if err != nil {
-				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-			}
-		}
+		// This failed to an ETCD error. Likely because we couldn't reach it
+		// which means we really don't have a way to inform anyone about this
+		// failed job because we'd need to do a STORE to mark the job as failed.
+		// TODO: Maybe retry indefinitely? For now exit. Another instance
+		//       (possibly us) will restart this transition later.
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Cannot retrieve Power States, cannot continue")
+		cancelChan <- true
+		return
 	}
-
-	// Attach collected data and add any dependent components (i.e. Rosettas).
-	for _, xname := range xnames {
-		comp, ok := xnameMap[xname]
-		if !ok {
-			// We don't care about xnames not in our list
-			continue
-		}
-		if comp.Task.Status != model.TransitionTaskStatusNew &&
-			comp.Task.Status != model.TransitionTaskStatusInProgress {
-			continue
-		}
-		ps, ok := pStates[xname]
-		if !ok {
-			continue
-		}
-		hData, ok := hsmData[xname]
-		if !ok {
-			continue
-		}
-
-		actions := make(map[string]string)
-		for _, action := range hData.AllowableActions {
-			actions[strings.ToLower(action)] = action
-		}
-
-		comp.PState = &ps
-		comp.HSMData = hData
-		comp.Actions = actions
-		comp.PowerSupplies = getPowerSupplies(hData)
-
-		// Add any Rosettas if we're powering off RouterModules
-		if (xnametypes.GetHMSType(xname) == xnametypes.RouterModule) &&
-			((hData.BaseData.Class == base.ClassHill.String()) || (hData.BaseData.Class == base.ClassMountain.String())) &&
-			(tr.Operation != model.Operation_On) {
-			switchXname := xname + "e0"
-			_, compOk := xnameMap[switchXname]
-			switchPs, psOk := pStates[switchXname]
-			switchHData, hsmOk := hsmData[switchXname]
-			// Skip if the rosetta is already in our list. The below
-			// will be or has been already done for that component.
-			if psOk && hsmOk && !compOk {
-				task := model.NewTransitionTask(tr.TransitionID, tr.Operation)
-				task.Xname = switchXname
-				task.StatusDesc = "Gathering data"
-				tr.TaskIDs = append(tr.TaskIDs, task.TaskID)
-				err = (*GLOB.DSP).StoreTransitionTask(task)
-				if err != nil {
-					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-				}
-				switchActions := make(map[string]string)
-				for _, action := range switchHData.AllowableActions {
-					actions[strings.ToLower(action)] = action
-				}
-				xnameMap[switchXname] = &TransitionComponent{
-					Task:          &task,
-					PState:        &switchPs,
-					HSMData:       switchHData,
-					Actions:       switchActions,
-					PowerSupplies: getPowerSupplies(switchHData),
-				}
-			}
-		}
+	if !found {
+		// No xnames found
+		err = errors.New("No xnames to operate on")
+		logrus.WithFields(logrus.Fields{"ERROR": err}).Error("No xnames to operate on")
+		compressAndCompleteTransition(tr, model.TransitionStatusCompleted)
+		return
 	}
 
 	abortSignaled, err = storeTransition(tr)
@@ -554,8 +824,16 @@ func doTransition(transitionID uuid.UUID) {
 		return
 	}
 
+	// Components that would exceed a power budget aren't powered on.
+	failBudgetDeferredComps(tr, xnameMap)
+	// Nor are frozen ones, if a freeze started while the transition waited.
+	failFrozenComps(tr, xnameMap)
+	// Protected components aren't powered off unless the transition is forced.
+	failProtectedComps(tr, xnameMap)
+
 	// Sort components into groups so they can follow a proper power sequence
-	seqMap, reservationData := sequenceComponents(tr.Operation, xnameMap)
+	seqMap, reservationData := sequenceComponents(tr.Operation, xnameMap, false)
+	failUnsequencedComps(tr.PowerSequence, powerSeq, seqMap, xnameMap, false)
 
 	///////////////////////////////////////////////////////////////////////////
 	// o Reserve components. This will make sure we aren't already operating on
@@ -581,7 +859,7 @@ func doTransition(transitionID uuid.UUID) {
 			comp.Task.Status = model.TransitionTaskStatusFailed
 			comp.Task.Error = err.Error()
 			comp.Task.StatusDesc = "Error acquiring reservations"
-			err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
+			err = storeTransitionTask(comp.Task)
 			if err != nil {
 				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 			}
@@ -642,7 +920,7 @@ func doTransition(transitionID uuid.UUID) {
 					}
 				}
 				failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
-				err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
+				err = storeTransitionTask(comp.Task)
 				if err != nil {
 					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 				}
@@ -651,7 +929,7 @@ func doTransition(transitionID uuid.UUID) {
 				comp := xnameMap[res.XName]
 				comp.Task.ReservationKey = reservation.ReservationKey
 				comp.Task.DeputyKey = reservation.DeputyKey
-				err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
+				err = storeTransitionTask(comp.Task)
 				if err != nil {
 					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 				}
@@ -664,8 +942,9 @@ func doTransition(transitionID uuid.UUID) {
 	// o seqMap[Action][Comptype] has by now been sorted to include what action(s)
 	//   each component needs to have applied.
 	//
-	// o Power sequencing is controlled by the PowerSequenceFull array that defines
-	//   an order of Actions to perform on a set of component types. The order is:
+	// o Power sequencing is controlled by the transition's power sequence that
+	//   defines an order of Actions to perform on a set of component types.
+	//   Without one, PowerSequenceFull is used. Its order is:
 	//   1) GracefulShutdown/Off on Nodes
 	//   2) ForceOff on Nodes
 	//   3) GracefulShutdown/Off on Router+Compute Modules
@@ -684,10 +963,9 @@ func doTransition(transitionID uuid.UUID) {
 	///////////////////////////////////////////////////////////////////////////
 
 	waitForBMCPower := false
-	for _, elm := range PowerSequenceFull {
+	for _, elm := range powerSeq {
 		var compList []*TransitionComponent
 		powerAction := elm.Action
-		powerActionOp := getOpForPowerAction(powerAction)
 		compTypes := elm.CompTypes
 		// Get the list of components we'll be acting on
 		for _, compType := range compTypes {
@@ -704,15 +982,21 @@ func doTransition(transitionID uuid.UUID) {
 		// The previous power operation resulted in supplied power
 		// to child BMCs. Give the BMCs time to power on.
 		if waitForBMCPower {
-			waitForBMC(compList)
+			waitForBMC(tr, compList)
 			waitForBMCPower = false
 		}
 
+		waitWhilePaused(tr)
 		abort, _ := checkAbort(tr)
 		if abort {
 			doAbort(tr, xnameMap)
 			return
 		}
+		checkComponentAborts(tr, powerAction, xnameMap, reservationData, nil)
+		if failureThresholdExceeded(tr, xnameMap) {
+			doHalt(tr, xnameMap)
+			return
+		}
 
 		// Check reservations are good
 		err := (*GLOB.HSM).CheckDeputyKeys(reservationData)
@@ -734,7 +1018,7 @@ func doTransition(transitionID uuid.UUID) {
 					comp.Task.StatusDesc = "Failed to achieve transition"
 					depErrMsg := fmt.Sprintf("Reservation expired for dependency, %s.", comp.Task.Xname)
 					failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
-					err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
+					err = storeTransitionTask(comp.Task)
 					if err != nil {
 						logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 					}
@@ -742,249 +1026,76 @@ func doTransition(transitionID uuid.UUID) {
 			}
 		}
 
-		// Repeated and frequent power transitions to the same BMCs is not
-		// common so we use the default TRS configuration provided by the
-		// default BaseTRSTask task prototype.  It may be beneficial to
-		// consider sharing the PCS TRS client in the future as requesting
-		// power state transitions generally shares the same set of BMC targets
-		// we want to talk to
-
-		// Create TRS task list
+		// Send the tier's action in batches. Without a batch size the whole
+		// tier is a single batch.
 		trsTaskMap := make(map[uuid.UUID]*TransitionComponent)
-		trsTaskList := (*GLOB.RFTloc).CreateTaskList(GLOB.BaseTRSTask, len(compList))
-		trsTaskIdx := 0
-		for _, comp := range compList {
-			if comp.Task.Status == model.TransitionTaskStatusFailed {
-				continue
+		batches := batchComponents(compList, tr.BatchSize, tr.BatchSizePercent)
+		for batchIdx, batch := range batches {
+			if batchIdx > 0 {
+				if tr.BatchDelaySeconds > 0 {
+					time.Sleep(time.Duration(tr.BatchDelaySeconds) * time.Second)
+				}
+				waitWhilePaused(tr)
+				abort, _ := checkAbort(tr)
+				if abort {
+					doAbort(tr, xnameMap)
+					return
+				}
+				checkComponentAborts(tr, powerAction, xnameMap, reservationData, trsTaskMap)
+				if failureThresholdExceeded(tr, xnameMap) {
+					doHalt(tr, xnameMap)
+					return
+				}
 			}
-			if comp.Task.State == model.TaskState_Waiting &&
-				comp.Task.Operation == powerActionOp {
-				// Restarted task that we just need to wait to confirm transition.
-				// Add it to the trsTaskMap but don't add it to the trsTaskList to
-				// avoid resending the command.
-				trsTaskMap[uuid.New()] = comp
-				continue
+			if len(batches) > 1 {
+				logger.Log.Infof("%s: Transition %s sending %s batch %d/%d (%s)", fname,
+					tr.TransitionID.String(), powerAction, batchIdx+1, len(batches), GLOB.PodName)
 			}
-			payload, err := generateTransitionPayload(comp, powerAction)
-			if err != nil {
-				comp.Task.Status = model.TransitionTaskStatusFailed
-				comp.Task.StatusDesc = "Failed to construct payload"
-				comp.Task.Error = err.Error()
-				depErrMsg := fmt.Sprintf("Failed to apply transition, %s, to dependency, %s.", powerAction, comp.Task.Xname)
-				failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
-				err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-				if err != nil {
-					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+			// Space out powering on the batch to limit inrush current.
+			waves := staggerComponents(tr, powerAction, batch)
+			for waveIdx, wave := range waves {
+				if waveIdx > 0 {
+					time.Sleep(wave.offset - waves[waveIdx-1].offset)
+					waitWhilePaused(tr)
+					abort, _ := checkAbort(tr)
+					if abort {
+						doAbort(tr, xnameMap)
+						return
+					}
+					checkComponentAborts(tr, powerAction, xnameMap, reservationData, trsTaskMap)
+					if failureThresholdExceeded(tr, xnameMap) {
+						doHalt(tr, xnameMap)
+						return
+					}
 				}
-				continue
-			}
-
-			comp.Task.StatusDesc = "Applying transition, " + powerAction
-			comp.Task.State = model.TaskState_Sending
-			comp.Task.Operation = powerActionOp
-			trsTaskMap[trsTaskList[trsTaskIdx].GetID()] = comp
-			trsTaskList[trsTaskIdx].CPolicy.Retry.Retries = 3
-			trsTaskList[trsTaskIdx].Request, _ = http.NewRequest("POST", "https://"+comp.HSMData.RfFQDN+comp.HSMData.PowerActionURI, bytes.NewBuffer([]byte(payload)))
-			trsTaskList[trsTaskIdx].Request.Header.Set("Content-Type", "application/json")
-			trsTaskList[trsTaskIdx].Request.Header.Add("HMS-Service", GLOB.BaseTRSTask.ServiceName)
-			// Vault enabled?
-			if GLOB.VaultEnabled {
-				user, pw, err := (*GLOB.CS).GetControllerCredentials(comp.PState.XName)
-				if err != nil {
-					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Unable to get credentials for " + comp.PState.XName)
-				} // Retry credentials? Fail operation here? For now, just let it fail with empty credentials
-				if !(user == "" && pw == "") {
-					trsTaskList[trsTaskIdx].Request.SetBasicAuth(user, pw)
+				if len(waves) > 1 {
+					logger.Log.Infof("%s: Transition %s sending staggered %s to %d components, %s after batch start (%s)", fname,
+						tr.TransitionID.String(), powerAction, len(wave.comps), wave.offset.String(), GLOB.PodName)
 				}
+				sendTransitionRequests(wave.comps, powerAction, noWait, xnameMap, trsTaskMap)
 			}
-			trsTaskIdx++
-			err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-			if err != nil {
-				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+			// Hold the next batch until this one has been confirmed.
+			if tr.BatchWaitForConfirmation && !noWait && batchIdx < len(batches)-1 && len(trsTaskMap) > 0 {
+				aborted := confirmTransitionRequests(tr, powerAction, isSoft, xnameMap, seqMap, reservationData, trsTaskMap)
+				if aborted {
+					return
+				}
 			}
 		}
-		// Shrink the taskList to size incase we were left with empty ones
-		trsTaskList = trsTaskList[:trsTaskIdx]
 
-		// Launch the TRS tasks and wait to hear back
-		if len(trsTaskList) > 0 {
-			logger.Log.Infof("%s: Initiating %d/%d transition requests to "+
-				"BMCs (timeout %v) (%s)", fname, trsTaskIdx,
-				len(compList), GLOB.BaseTRSTask.Timeout,
-				GLOB.PodName)
-
-			rchan, err := (*GLOB.RFTloc).Launch(&trsTaskList)
-			if err != nil {
-				logrus.Error(err)
+		// TRS section for getting power state for confirmation.
+		if len(trsTaskMap) > 0 || !noWait {
+			aborted := confirmTransitionRequests(tr, powerAction, isSoft, xnameMap, seqMap, reservationData, trsTaskMap)
+			if aborted {
+				return
 			}
-			for range trsTaskList {
-				var taskErr error
-				tdone := <-rchan
-				comp := trsTaskMap[tdone.GetID()]
-				for i := 0; i < 1; i++ {
-
-					if *tdone.Err != nil {
-						taskErr = *tdone.Err
-
-						base.DrainAndCloseResponseBody(tdone.Request.Response)
-
-						break
-					}
-					if tdone.Request.Response.StatusCode < 200 && tdone.Request.Response.StatusCode >= 300 {
-						taskErr = errors.New("bad status code: " + strconv.Itoa(tdone.Request.Response.StatusCode))
-
-						base.DrainAndCloseResponseBody(tdone.Request.Response)
-
-						break
-					}
-					if tdone.Request.Response.Body == nil {
-						taskErr = errors.New("empty body")
-						break
-					}
-					_, err := io.ReadAll(tdone.Request.Response.Body)
-
-					// Must always close response bodies
-					base.DrainAndCloseResponseBody(tdone.Request.Response)
-
-					if err != nil {
-						taskErr = err
-						break
-					}
-				}
-				if taskErr != nil {
-					comp.Task.Status = model.TransitionTaskStatusFailed
-					comp.Task.Error = taskErr.Error()
-					comp.Task.StatusDesc = "Failed to apply transition, " + powerAction
-					logger.Log.WithFields(logrus.Fields{"ERROR": taskErr, "URI": tdone.Request.URL.String()}).Error("Redfish request failed")
-					delete(trsTaskMap, tdone.GetID())
-					depErrMsg := fmt.Sprintf("Failed to apply transition, %s, to dependency, %s.", powerAction, comp.Task.Xname)
-					failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
-				} else if noWait {
-					comp.ActionCount--
-					if comp.ActionCount == 0 {
-						comp.Task.Status = model.TransitionTaskStatusSucceeded
-						comp.Task.StatusDesc = fmt.Sprintf("Transition applied, %s. Not confirming.", powerAction)
-						comp.Task.State = model.TaskState_Confirmed
-					} else {
-						comp.Task.Status = model.TransitionTaskStatusInProgress
-						comp.Task.StatusDesc = fmt.Sprintf("Transition applied, %s. Waiting for next transition.", powerAction)
-						comp.Task.State = model.TaskState_Confirmed
-					}
-				} else {
-					comp.Task.Status = model.TransitionTaskStatusInProgress
-					comp.Task.StatusDesc = "Confirming successful transition, " + powerAction
-					comp.Task.State = model.TaskState_Waiting
-				}
-				err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-				if err != nil {
-					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-				}
-			}
-			(*GLOB.RFTloc).Close(&trsTaskList)
-			close(rchan)
-			logger.Log.Infof("%s: Done processing BMC responses (%s)",
-				fname, GLOB.PodName)
-		} else {
-			// Free up this memory
-			(*GLOB.RFTloc).Close(&trsTaskList)
-		}
-
-		// TRS section for getting power state for confirmation.
-		if len(trsTaskMap) > 0 || !noWait {
-			var waitExpireTime time.Time
-			if !waitForever {
-				waitExpireTime = time.Now().Add(time.Duration(tr.TaskDeadline) * time.Minute)
-			}
-			endState := ""
-			switch powerAction {
-			case "gracefulshutdown":
-				fallthrough
-			case "forceoff":
-				endState = "off"
-			case "gracefulrestart":
-				fallthrough
-			case "on":
-				endState = "on"
-			}
-			for {
-				abort, _ := checkAbort(tr)
-				if abort {
-					doAbort(tr, xnameMap)
-					return
-				}
-
-				// The update interval for power status in ETCD is 30 seconds but we could get an update sooner.
-				time.Sleep(15 * time.Second)
-				for trsTaskID, comp := range trsTaskMap {
-					// Get the state from ETCD
-					pState, err := (*GLOB.DSP).GetPowerStatus(comp.Task.Xname)
-					if err != nil {
-						comp.Task.Status = model.TransitionTaskStatusFailed
-						comp.Task.Error = err.Error()
-						comp.Task.StatusDesc = "Failed to confirm transition"
-						if !strings.Contains(err.Error(), "does not exist") {
-							// Database error
-							logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error getting power status from database")
-						}
-						delete(trsTaskMap, trsTaskID)
-						depErrMsg := fmt.Sprintf("Failed to confirm transition, %s, to dependency, %s.", powerAction, comp.Task.Xname)
-						failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
-						err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-						if err != nil {
-							logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-						}
-					} else if strings.ToLower(pState.PowerState) == endState {
-						comp.ActionCount--
-						comp.Task.State = model.TaskState_Confirmed
-						if comp.ActionCount == 0 {
-							comp.Task.Status = model.TransitionTaskStatusSucceeded
-							comp.Task.StatusDesc = "Transition confirmed, " + powerAction
-						} else {
-							comp.Task.StatusDesc = "Transition confirmed, " + powerAction + ". Waiting for next transition"
-						}
-						delete(trsTaskMap, trsTaskID)
-						err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-						if err != nil {
-							logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-						}
-					}
-				}
-				// The map is either empty because everything in this tier has been confirmed or has failed.
-				if len(trsTaskMap) == 0 {
-					break
-				}
-				// Check to see if the time has expired.
-				if !waitForever && time.Now().After(waitExpireTime) {
-					for _, comp := range trsTaskMap {
-						_, hasForceOff := comp.Actions["forceoff"]
-						if powerAction == "gracefulshutdown" && !isSoft && hasForceOff {
-							// Add components that timed out to the ForceOff list (if we're doing ForceOff)
-							compType := xnametypes.GetHMSType(comp.Task.Xname)
-							seqMap["forceoff"][compType] = append(seqMap["forceoff"][compType], comp)
-						} else {
-							// We have timed out and we have either tried ForceOff or are not doing a ForceOff.
-							// Fail the leftover components.
-							comp.Task.Status = model.TransitionTaskStatusFailed
-							comp.Task.Error = fmt.Sprintf("Timeout waiting for transition, %s.", powerAction)
-							comp.Task.StatusDesc = "Failed to achieve transition"
-							depErrMsg := fmt.Sprintf("Timeout waiting for transition, %s, on dependency, %s.", powerAction, comp.Task.Xname)
-							failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
-							err = (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-							if err != nil {
-								logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-							}
-						}
-					}
-					break
-				}
-			}
-			// If we might be powering on child components, we'll
-			// want to give their BMCs some time to become ready.
-			if powerAction == "on" {
-				for _, compType := range compTypes {
-					if compType == xnametypes.RouterModule ||
-						compType == xnametypes.ComputeModule {
-						waitForBMCPower = true
+			// If we might be powering on child components, we'll
+			// want to give their BMCs some time to become ready.
+			if powerAction == "on" {
+				for _, compType := range compTypes {
+					if compType == xnametypes.RouterModule ||
+						compType == xnametypes.ComputeModule {
+						waitForBMCPower = true
 					}
 				}
 			} else if powerAction == "gracefulrestart" {
@@ -1015,6 +1126,330 @@ func doTransition(transitionID uuid.UUID) {
 	return
 }
 
+// Sends powerAction to a batch of components through TRS. Components that
+// need their transition confirmed are added to trsTaskMap.
+func sendTransitionRequests(compList []*TransitionComponent, powerAction string, noWait bool, xnameMap map[string]*TransitionComponent, trsTaskMap map[uuid.UUID]*TransitionComponent) {
+	fname := "sendTransitionRequests"
+	powerActionOp := getOpForPowerAction(powerAction)
+
+	// Repeated and frequent power transitions to the same BMCs is not
+	// common so we use the default TRS configuration provided by the
+	// default BaseTRSTask task prototype.  It may be beneficial to
+	// consider sharing the PCS TRS client in the future as requesting
+	// power state transitions generally shares the same set of BMC targets
+	// we want to talk to
+
+	// Create TRS task list
+	trsTaskList := (*GLOB.RFTloc).CreateTaskList(GLOB.BaseTRSTask, len(compList))
+	trsTaskIdx := 0
+	for _, comp := range compList {
+		if comp.Task.Status == model.TransitionTaskStatusFailed ||
+			comp.Task.Status == model.TransitionTaskStatusAborted {
+			continue
+		}
+		if comp.Task.State == model.TaskState_Waiting &&
+			comp.Task.Operation == powerActionOp {
+			// Restarted task that we just need to wait to confirm transition.
+			// Add it to the trsTaskMap but don't add it to the trsTaskList to
+			// avoid resending the command.
+			trsTaskMap[uuid.New()] = comp
+			continue
+		}
+		payload, err := generateTransitionPayload(comp, powerAction)
+		if err != nil {
+			comp.Task.Status = model.TransitionTaskStatusFailed
+			comp.Task.StatusDesc = "Failed to construct payload"
+			comp.Task.Error = err.Error()
+			depErrMsg := fmt.Sprintf("Failed to apply transition, %s, to dependency, %s.", powerAction, comp.Task.Xname)
+			failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
+			err = storeTransitionTask(comp.Task)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+			}
+			continue
+		}
+
+		comp.Task.StatusDesc = "Applying transition, " + powerAction
+		comp.Task.State = model.TaskState_Sending
+		comp.Task.Operation = powerActionOp
+		trsTaskMap[trsTaskList[trsTaskIdx].GetID()] = comp
+		trsTaskList[trsTaskIdx].CPolicy.Retry.Retries = 3
+		trsTaskList[trsTaskIdx].Request, _ = http.NewRequest("POST", "https://"+comp.HSMData.RfFQDN+comp.HSMData.PowerActionURI, bytes.NewBuffer([]byte(payload)))
+		trsTaskList[trsTaskIdx].Request.Header.Set("Content-Type", "application/json")
+		trsTaskList[trsTaskIdx].Request.Header.Add("HMS-Service", GLOB.BaseTRSTask.ServiceName)
+		// Vault enabled?
+		if GLOB.VaultEnabled {
+			user, pw, err := (*GLOB.CS).GetControllerCredentials(comp.PState.XName)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Unable to get credentials for " + comp.PState.XName)
+			} // Retry credentials? Fail operation here? For now, just let it fail with empty credentials
+			if !(user == "" && pw == "") {
+				trsTaskList[trsTaskIdx].Request.SetBasicAuth(user, pw)
+			}
+		}
+		trsTaskIdx++
+		err = storeTransitionTask(comp.Task)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+		}
+	}
+	// Shrink the taskList to size incase we were left with empty ones
+	trsTaskList = trsTaskList[:trsTaskIdx]
+
+	// Launch the TRS tasks and wait to hear back
+	if len(trsTaskList) > 0 {
+		logger.Log.Infof("%s: Initiating %d/%d transition requests to "+
+			"BMCs (timeout %v) (%s)", fname, trsTaskIdx,
+			len(compList), GLOB.BaseTRSTask.Timeout,
+			GLOB.PodName)
+
+		rchan, err := (*GLOB.RFTloc).Launch(&trsTaskList)
+		if err != nil {
+			logrus.Error(err)
+		}
+		for range trsTaskList {
+			var taskErr error
+			tdone := <-rchan
+			comp := trsTaskMap[tdone.GetID()]
+			for i := 0; i < 1; i++ {
+
+				if *tdone.Err != nil {
+					taskErr = *tdone.Err
+
+					base.DrainAndCloseResponseBody(tdone.Request.Response)
+
+					break
+				}
+				if tdone.Request.Response.StatusCode < 200 && tdone.Request.Response.StatusCode >= 300 {
+					taskErr = errors.New("bad status code: " + strconv.Itoa(tdone.Request.Response.StatusCode))
+
+					base.DrainAndCloseResponseBody(tdone.Request.Response)
+
+					break
+				}
+				if tdone.Request.Response.Body == nil {
+					taskErr = errors.New("empty body")
+					break
+				}
+				_, err := io.ReadAll(tdone.Request.Response.Body)
+
+				// Must always close response bodies
+				base.DrainAndCloseResponseBody(tdone.Request.Response)
+
+				if err != nil {
+					taskErr = err
+					break
+				}
+			}
+			if taskErr != nil {
+				comp.Task.Status = model.TransitionTaskStatusFailed
+				comp.Task.Error = taskErr.Error()
+				comp.Task.StatusDesc = "Failed to apply transition, " + powerAction
+				logger.Log.WithFields(logrus.Fields{"ERROR": taskErr, "URI": tdone.Request.URL.String()}).Error("Redfish request failed")
+				delete(trsTaskMap, tdone.GetID())
+				depErrMsg := fmt.Sprintf("Failed to apply transition, %s, to dependency, %s.", powerAction, comp.Task.Xname)
+				failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
+			} else if noWait {
+				comp.ActionCount--
+				if comp.ActionCount == 0 {
+					comp.Task.Status = model.TransitionTaskStatusSucceeded
+					comp.Task.StatusDesc = fmt.Sprintf("Transition applied, %s. Not confirming.", powerAction)
+					comp.Task.State = model.TaskState_Confirmed
+				} else {
+					comp.Task.Status = model.TransitionTaskStatusInProgress
+					comp.Task.StatusDesc = fmt.Sprintf("Transition applied, %s. Waiting for next transition.", powerAction)
+					comp.Task.State = model.TaskState_Confirmed
+				}
+			} else {
+				comp.Task.Status = model.TransitionTaskStatusInProgress
+				comp.Task.StatusDesc = "Confirming successful transition, " + powerAction
+				comp.Task.State = model.TaskState_Waiting
+			}
+			err = storeTransitionTask(comp.Task)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+			}
+		}
+		(*GLOB.RFTloc).Close(&trsTaskList)
+		close(rchan)
+		logger.Log.Infof("%s: Done processing BMC responses (%s)",
+			fname, GLOB.PodName)
+	} else {
+		// Free up this memory
+		(*GLOB.RFTloc).Close(&trsTaskList)
+	}
+}
+
+// Waits for the components in trsTaskMap to reach the end state of
+// powerAction, failing those that don't make it before the task deadline for
+// their component type. Components that time out during a gracefulshutdown
+// are handed to the forceoff tier if the operation allows it. Confirmed,
+// failed, and handed off components are removed from trsTaskMap.
+//
+// Returns true if the transition was aborted.
+func confirmTransitionRequests(tr model.Transition, powerAction string, isSoft bool, xnameMap map[string]*TransitionComponent, seqMap map[string]map[xnametypes.HMSType][]*TransitionComponent, reservationData []hsm.ReservationData, trsTaskMap map[uuid.UUID]*TransitionComponent) bool {
+	// Components without an entry have no deadline.
+	waitExpireTimes := make(map[uuid.UUID]time.Time)
+	start := time.Now()
+	for trsTaskID, comp := range trsTaskMap {
+		deadline, ok := taskDeadline(tr, powerAction, xnametypes.GetHMSType(comp.Task.Xname))
+		if ok {
+			waitExpireTimes[trsTaskID] = start.Add(deadline)
+		}
+	}
+	endState := ""
+	switch powerAction {
+	case "gracefulshutdown":
+		fallthrough
+	case "forceoff":
+		endState = "off"
+	case "gracefulrestart":
+		fallthrough
+	case "on":
+		endState = "on"
+	}
+	for {
+		abort, _ := checkAbort(tr)
+		if abort {
+			doAbort(tr, xnameMap)
+			return true
+		}
+		checkComponentAborts(tr, powerAction, xnameMap, reservationData, trsTaskMap)
+
+		var polled map[string]string
+		if pollSeconds := confirmPollSeconds(tr); pollSeconds > 0 {
+			// Ask the BMCs directly rather than waiting on the power status monitor.
+			time.Sleep(time.Duration(pollSeconds) * time.Second)
+			comps := make([]*TransitionComponent, 0, len(trsTaskMap))
+			for _, comp := range trsTaskMap {
+				comps = append(comps, comp)
+			}
+			polled = pollPowerStates(comps)
+		} else {
+			// The update interval for power status in ETCD is 30 seconds but we could get an update sooner.
+			time.Sleep(15 * time.Second)
+		}
+		for trsTaskID, comp := range trsTaskMap {
+			var (
+				pState model.PowerStatusComponent
+				err    error
+			)
+			if state, ok := polled[comp.Task.Xname]; ok {
+				pState.PowerState = state
+			} else {
+				// Get the state from ETCD. Components that couldn't be polled
+				// fall back to this too.
+				pState, err = (*GLOB.DSP).GetPowerStatus(comp.Task.Xname)
+			}
+			if err != nil {
+				comp.Task.Status = model.TransitionTaskStatusFailed
+				comp.Task.Error = err.Error()
+				comp.Task.StatusDesc = "Failed to confirm transition"
+				if !strings.Contains(err.Error(), "does not exist") {
+					// Database error
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error getting power status from database")
+				}
+				delete(trsTaskMap, trsTaskID)
+				depErrMsg := fmt.Sprintf("Failed to confirm transition, %s, to dependency, %s.", powerAction, comp.Task.Xname)
+				failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
+				err = storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
+			} else if strings.ToLower(pState.PowerState) == endState {
+				comp.ActionCount--
+				comp.Task.State = model.TaskState_Confirmed
+				if comp.ActionCount == 0 {
+					comp.Task.Status = model.TransitionTaskStatusSucceeded
+					comp.Task.StatusDesc = "Transition confirmed, " + powerAction
+				} else {
+					comp.Task.StatusDesc = "Transition confirmed, " + powerAction + ". Waiting for next transition"
+				}
+				delete(trsTaskMap, trsTaskID)
+				err = storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
+			}
+		}
+		// The map is either empty because everything in this tier has been confirmed or has failed.
+		if len(trsTaskMap) == 0 {
+			break
+		}
+		// Check to see if the time has expired.
+		now := time.Now()
+		for trsTaskID, comp := range trsTaskMap {
+			waitExpireTime, ok := waitExpireTimes[trsTaskID]
+			if !ok || !now.After(waitExpireTime) {
+				continue
+			}
+			// Later batches share trsTaskMap, so don't leave it to be confirmed again.
+			delete(trsTaskMap, trsTaskID)
+			_, hasForceOff := comp.Actions["forceoff"]
+			if powerAction == "gracefulshutdown" && !isSoft && hasForceOff {
+				// Add components that timed out to the ForceOff list (if we're doing ForceOff)
+				compType := xnametypes.GetHMSType(comp.Task.Xname)
+				seqMap["forceoff"][compType] = append(seqMap["forceoff"][compType], comp)
+				// Mark when the fallback began in the task's timeline.
+				comp.Task.StatusDesc = "Timeout waiting for transition, gracefulshutdown. Falling back to forceoff"
+				err := storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
+			} else {
+				// We have timed out and we have either tried ForceOff or are not doing a ForceOff.
+				// Fail the leftover components.
+				comp.Task.Status = model.TransitionTaskStatusFailed
+				comp.Task.Error = fmt.Sprintf("Timeout waiting for transition, %s.", powerAction)
+				comp.Task.StatusDesc = "Failed to achieve transition"
+				depErrMsg := fmt.Sprintf("Timeout waiting for transition, %s, on dependency, %s.", powerAction, comp.Task.Xname)
+				failDependentComps(xnameMap, powerAction, comp.Task.Xname, depErrMsg)
+				err := storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
+			}
+		}
+		if len(trsTaskMap) == 0 {
+			break
+		}
+	}
+	return false
+}
+
+// Splits a tier's components into batches of at most batchSize components,
+// or batchSizePercent percent of the tier, rounded up. Components that have
+// already failed are left out. Without either limit the whole tier is one
+// batch.
+func batchComponents(compList []*TransitionComponent, batchSize int, batchSizePercent int) [][]*TransitionComponent {
+	var active []*TransitionComponent
+	for _, comp := range compList {
+		if comp.Task.Status == model.TransitionTaskStatusFailed ||
+			comp.Task.Status == model.TransitionTaskStatusAborted {
+			continue
+		}
+		active = append(active, comp)
+	}
+
+	size := batchSize
+	if batchSizePercent > 0 {
+		size = (len(active)*batchSizePercent + 99) / 100
+	}
+	if size <= 0 || size >= len(active) {
+		return [][]*TransitionComponent{active}
+	}
+
+	var batches [][]*TransitionComponent
+	for start := 0; start < len(active); start += size {
+		end := start + size
+		if end > len(active) {
+			end = len(active)
+		}
+		batches = append(batches, active[start:end])
+	}
+	return batches
+}
+
 // Checks for AbortSignaled before storing the transition using test-and-set to
 // avoid overwriting statuses set by other instances. Retries the TAS operation
 // a few times upon failure so it may eventually happen.
@@ -1043,7 +1478,12 @@ func storeTransition(tr model.Transition) (bool, error) {
 		if trOld.Status == model.TransitionStatusAbortSignaled {
 			tr.Status = model.TransitionStatusAbortSignaled
 			abort = true
+		} else if trOld.Status == model.TransitionStatusPaused {
+			// Stay paused until resumed.
+			tr.Status = model.TransitionStatusPaused
 		}
+		// Keep components that were removed while we weren't looking.
+		tr.AbortedXnames = trOld.AbortedXnames
 		// Use test and set to prevent overwriting another thread's store operation.
 		ok, err := (*GLOB.DSP).TASTransition(tr, trOld)
 		if err != nil {
@@ -1081,6 +1521,86 @@ func checkAbort(tr model.Transition) (bool, error) {
 	return false, nil
 }
 
+// Blocks while the transition is paused. Returns once it has been resumed or
+// anything else, like an abort, has changed its status. The keep alive thread
+// keeps running in the meantime so the transition isn't considered abandoned.
+func waitWhilePaused(tr model.Transition) {
+	logged := false
+	for {
+		transition, _, err := (*GLOB.DSP).GetTransition(tr.TransitionID)
+		if err != nil {
+			if !strings.Contains(err.Error(), "does not exist") {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error getting transition")
+			}
+			return
+		}
+		if transition.Status != model.TransitionStatusPaused {
+			if logged {
+				logger.Log.Infof("Transition %s resumed (%s)", tr.TransitionID.String(), GLOB.PodName)
+			}
+			return
+		}
+		if !logged {
+			logger.Log.Infof("Transition %s paused (%s)", tr.TransitionID.String(), GLOB.PodName)
+			logged = true
+		}
+		time.Sleep(pausePollInterval)
+	}
+}
+
+// Aborts the tasks of any components that have been removed from the
+// transition since it started. Their reservations are released, they are
+// dropped from trsTaskMap so they aren't confirmed, and any components that
+// depend on them for powerAction are failed.
+func checkComponentAborts(tr model.Transition, powerAction string, xnameMap map[string]*TransitionComponent, reservationData []hsm.ReservationData, trsTaskMap map[uuid.UUID]*TransitionComponent) {
+	transition, _, err := (*GLOB.DSP).GetTransition(tr.TransitionID)
+	if err != nil {
+		if !strings.Contains(err.Error(), "does not exist") {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error getting transition")
+		}
+		return
+	}
+	for _, xname := range transition.AbortedXnames {
+		comp, ok := xnameMap[xname]
+		if !ok || (comp.Task.Status != model.TransitionTaskStatusNew &&
+			comp.Task.Status != model.TransitionTaskStatusInProgress) {
+			continue
+		}
+		logger.Log.Infof("Aborting %s in Transition %s (%s)", xname,
+			tr.TransitionID.String(), GLOB.PodName)
+		abortTask(comp.Task)
+		for trsTaskID, trsComp := range trsTaskMap {
+			if trsComp == comp {
+				delete(trsTaskMap, trsTaskID)
+			}
+		}
+		for i, res := range reservationData {
+			if res.XName != xname {
+				continue
+			}
+			// Releasing clears ReservationOwner so the deferred release of
+			// the whole transition skips it.
+			_, err = (*GLOB.HSM).ReleaseComponents(reservationData[i : i+1])
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error releasing reservation for %s", xname)
+			}
+		}
+		depErrMsg := fmt.Sprintf("Dependency, %s, was aborted.", xname)
+		failDependentComps(xnameMap, powerAction, xname, depErrMsg)
+	}
+}
+
+// Sets a task's status to aborted and stores it.
+func abortTask(task *model.TransitionTask) {
+	task.Status = model.TransitionTaskStatusAborted
+	task.StatusDesc = "Aborted"
+	task.Error = "Component was removed from the transition"
+	err := storeTransitionTask(task)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+	}
+}
+
 // Fail any tasks that have not finished and mark the transition as "Aborted".
 func doAbort(tr model.Transition, xnameMap map[string]*TransitionComponent) {
 	for _, comp := range xnameMap {
@@ -1089,7 +1609,7 @@ func doAbort(tr model.Transition, xnameMap map[string]*TransitionComponent) {
 			comp.Task.Status = model.TransitionTaskStatusFailed
 			comp.Task.Error = "Transition aborted"
 			comp.Task.StatusDesc = "Aborted. Last status - " + comp.Task.StatusDesc
-			err := (*GLOB.DSP).StoreTransitionTask(*comp.Task)
+			err := storeTransitionTask(comp.Task)
 			if err != nil {
 				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 			}
@@ -1098,6 +1618,48 @@ func doAbort(tr model.Transition, xnameMap map[string]*TransitionComponent) {
 	compressAndCompleteTransition(tr, model.TransitionStatusAborted)
 }
 
+// Checks if more of the transition's tasks have failed than its maxFailures
+// or maxFailurePercent allow.
+func failureThresholdExceeded(tr model.Transition, xnameMap map[string]*TransitionComponent) bool {
+	if tr.MaxFailures <= 0 && tr.MaxFailurePercent <= 0 {
+		return false
+	}
+	failed := 0
+	for _, comp := range xnameMap {
+		if comp.Task.Status == model.TransitionTaskStatusFailed {
+			failed++
+		}
+	}
+	if tr.MaxFailures > 0 && failed > tr.MaxFailures {
+		return true
+	}
+	if tr.MaxFailurePercent > 0 && failed*100 > tr.MaxFailurePercent*len(xnameMap) {
+		return true
+	}
+	return false
+}
+
+// Stops a transition that has exceeded its failure threshold. Tasks that
+// haven't finished are failed and the transition is marked halted so it
+// can be told apart from a requested abort.
+func doHalt(tr model.Transition, xnameMap map[string]*TransitionComponent) {
+	logger.Log.Warnf("Transition %s exceeded its failure threshold. Halting (%s)",
+		tr.TransitionID.String(), GLOB.PodName)
+	for _, comp := range xnameMap {
+		if comp.Task.Status == model.TransitionTaskStatusNew ||
+			comp.Task.Status == model.TransitionTaskStatusInProgress {
+			comp.Task.Status = model.TransitionTaskStatusFailed
+			comp.Task.Error = "Transition halted, too many failures"
+			comp.Task.StatusDesc = "Halted. Last status - " + comp.Task.StatusDesc
+			err := storeTransitionTask(comp.Task)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+			}
+		}
+	}
+	compressAndCompleteTransition(tr, model.TransitionStatusHalted)
+}
+
 // Periodically updates the LastActiveTime field of the given transition. Will kill itself
 // if the transition moves to the Completed or Aborted state or gets deleted.
 func transitionKeepAlive(transitionID uuid.UUID, cancelChan chan bool) {
@@ -1130,7 +1692,8 @@ func transitionKeepAlive(transitionID uuid.UUID, cancelChan chan bool) {
 
 				// End states
 				if transition.Status == model.TransitionStatusAborted ||
-					transition.Status == model.TransitionStatusCompleted {
+					transition.Status == model.TransitionStatusCompleted ||
+					transition.Status == model.TransitionStatusHalted {
 					logger.Log.Infof("Transition %s is finished. Stopping keep alive thread", transitionID.String())
 					return
 				}
@@ -1211,113 +1774,368 @@ func getPowerStateHierarchy(xnames []string) (map[string]model.PowerStatusCompon
 						}
 					}
 				}
-				if !found {
-					badList = append(badList, xname)
-				}
-			default:
-				badList = append(badList, xname)
+				if !found {
+					badList = append(badList, xname)
+				}
+			default:
+				badList = append(badList, xname)
+			}
+		}
+	}
+	return xnameMap, badList, nil
+}
+
+// Create an initial set of transition tasks from the transition parameters.
+// This checks for previously existing tasks for the transition and adds them
+// too. Nothing is looked up or stored if dryRun is set.
+func setupTransitionTasks(tr *model.Transition, dryRun bool) (map[string]*TransitionComponent, []string) {
+	var (
+		xnames []string
+		tasks  []model.TransitionTask
+		err    error
+	)
+	xnameMap := make(map[string]*TransitionComponent)
+
+	// Get any tasks that may have previously been created for our operation.
+	if !dryRun {
+		tasks, err = (*GLOB.DSP).GetAllTasksForTransition(tr.TransitionID)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving tasks for transition, " + tr.TransitionID.String())
+		}
+	}
+	// Rebuild our xnameMap based on the previous tasks
+	for i, task := range tasks {
+		xnameMap[task.Xname] = &TransitionComponent{
+			Task: &tasks[i],
+		}
+	}
+	// Make sure all the previously created tasks are in the transition's task array.
+	if len(xnameMap) > 0 {
+		taskMap := make(map[uuid.UUID]bool)
+		for _, taskID := range tr.TaskIDs {
+			taskMap[taskID] = true
+		}
+		for _, comp := range xnameMap {
+			if _, ok := taskMap[comp.Task.TaskID]; !ok {
+				tr.TaskIDs = append(tr.TaskIDs, comp.Task.TaskID)
+			}
+			if comp.Task.Status == model.TransitionTaskStatusNew ||
+				comp.Task.Status == model.TransitionTaskStatusInProgress {
+				xnames = append(xnames, comp.Task.Xname)
+			}
+		}
+	}
+
+	// Vet and turn the list of requested xnames into a map
+	for _, loc := range tr.Location {
+		if _, ok := xnameMap[loc.Xname]; ok {
+			continue
+		}
+
+		// Create tasks for everything requested so we can
+		// communicate reasons for failures.
+		task := model.NewTransitionTask(tr.TransitionID, tr.Operation)
+		task.Xname = loc.Xname
+		task.DeputyKey = loc.DeputyKey
+
+		// Weed out invalid xnames and components we can't power control here.
+		compType := xnametypes.GetHMSType(loc.Xname)
+		switch compType {
+		case xnametypes.ChassisBMC:
+			fallthrough
+		case xnametypes.NodeBMC:
+			fallthrough
+		case xnametypes.RouterBMC:
+			fallthrough
+		case xnametypes.Node:
+			fallthrough
+		case xnametypes.Chassis:
+			fallthrough
+		case xnametypes.ComputeModule:
+			fallthrough
+		case xnametypes.RouterModule:
+			fallthrough
+		case xnametypes.MgmtSwitch:
+			fallthrough
+		case xnametypes.MgmtHLSwitch:
+			fallthrough
+		case xnametypes.CDUMgmtSwitch:
+			fallthrough
+		case xnametypes.CabinetPDUPowerConnector:
+			task.StatusDesc = "Gathering data"
+		case xnametypes.HMSTypeInvalid:
+			task.Status = model.TransitionTaskStatusFailed
+			task.Error = "Invalid xname"
+			task.StatusDesc = "Failed to achieve transition"
+		default:
+			task.Status = model.TransitionTaskStatusUnsupported
+			task.Error = "No power control for component type " + compType.String()
+			task.StatusDesc = "Failed to achieve transition"
+		}
+		tr.TaskIDs = append(tr.TaskIDs, task.TaskID)
+		if !dryRun {
+			err = storeTransitionTask(&task)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+			}
+		}
+		xnameMap[loc.Xname] = &TransitionComponent{Task: &task}
+		if task.Status == model.TransitionTaskStatusNew {
+			xnames = append(xnames, loc.Xname)
+		}
+	}
+
+	// Components removed from the transition before it got to them don't
+	// need to be reserved or acted on at all.
+	if len(tr.AbortedXnames) > 0 {
+		aborted := make(map[string]bool)
+		for _, xname := range tr.AbortedXnames {
+			aborted[xname] = true
+		}
+		var remaining []string
+		for _, xname := range xnames {
+			if !aborted[xname] {
+				remaining = append(remaining, xname)
+				continue
+			}
+			if !dryRun {
+				abortTask(xnameMap[xname].Task)
+			}
+		}
+		xnames = remaining
+	}
+	return xnameMap, xnames
+}
+
+// Vets the transition's components against our internal stored Power Status
+// (which should have everything HSM has plus a recently captured power state
+// from hardware) and attaches the HSM component state, ComponentEndpoint, and
+// power map data to each component. Tasks are failed for components that
+// can't be found and any dependent components (i.e. Rosettas) are added.
+//
+// Returns the list of xnames that could not be found, whether there is
+// anything left to operate on, and any database error encountered.
+func gatherTransitionData(tr *model.Transition, xnameMap map[string]*TransitionComponent, xnames []string, dryRun bool) ([]string, bool, error) {
+	var (
+		xnameHierarchy []string
+		missing        []string
+	)
+
+	// Expand the list of xnames to include power controlled subcomponents. This way we
+	// already have the information for additional components we might need to add.
+	pStates, missingXnames, err := getPowerStateHierarchy(xnames)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Finish out tasks for components that were not found or we cannot power control.
+	if len(missingXnames) > 0 {
+		logrus.WithFields(logrus.Fields{"xnames": missingXnames}).Error("Missing xnames detected")
+		for _, xname := range missingXnames {
+			comp, ok := xnameMap[xname]
+			if !ok {
+				// We don't care about xnames not in our list
+				continue
+			}
+			// Set failures for each listed xname
+			comp.Task.Status = model.TransitionTaskStatusFailed
+			compType := xnametypes.GetHMSType(xname)
+			if compType != xnametypes.Chassis &&
+				compType != xnametypes.ComputeModule &&
+				compType != xnametypes.Node &&
+				compType != xnametypes.RouterModule &&
+				compType != xnametypes.CabinetPDUPowerConnector &&
+				compType != xnametypes.ChassisBMC &&
+				compType != xnametypes.NodeBMC &&
+				compType != xnametypes.RouterBMC &&
+				compType != xnametypes.MgmtSwitch &&
+				compType != xnametypes.MgmtHLSwitch &&
+				compType != xnametypes.CDUMgmtSwitch {
+				comp.Task.Error = "No power control for component type " + compType.String()
+			} else {
+				comp.Task.Error = "Missing xname"
+				missing = append(missing, xname)
+			}
+			comp.Task.StatusDesc = "Failed to achieve transition"
+			if !dryRun {
+				err = storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
+			}
+		}
+	}
+	if len(pStates) == 0 {
+		return missing, false, nil
+	}
+
+	for xname := range pStates {
+		xnameHierarchy = append(xnameHierarchy, xname)
+	}
+
+	///////////////////////////////////////////////////////////////////////////
+	// o Get the component state and ComponentEndpoint data from HSM.
+	///////////////////////////////////////////////////////////////////////////
+
+	hsmData, err := (*GLOB.HSM).FillHSMData(xnameHierarchy)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving HSM data")
+		// Failed to get data from HSM. Fail everything.
+		for _, xname := range xnames {
+			comp, ok := xnameMap[xname]
+			if !ok {
+				// We don't care about xnames not in our list
+				continue
+			}
+			if comp.Task.Status != model.TransitionTaskStatusNew &&
+				comp.Task.Status != model.TransitionTaskStatusInProgress {
+				// Skip it if it is already complete
+				continue
+			}
+			comp.Task.Status = model.TransitionTaskStatusFailed
+			comp.Task.Error = "Error retrieving HSM data"
+			comp.Task.StatusDesc = "Failed to achieve transition"
+			if !dryRun {
+				err = storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
+			}
+		}
+	} else {
+		// Check to see if we got everything back.
+		if len(hsmData) != len(xnameHierarchy) {
+			for _, xname := range xnames {
+				// This xname was not found in the response from HSM.
+				// Set a failed "Not found" task for it.
+				if _, ok := hsmData[xname]; !ok {
+					comp, ok := xnameMap[xname]
+					if !ok {
+						// We don't care about xnames not in our list
+						continue
+					}
+					if comp.Task.Status != model.TransitionTaskStatusNew &&
+						comp.Task.Status != model.TransitionTaskStatusInProgress {
+						// Skip it if it is already complete
+						continue
+					}
+
+					comp.Task.Status = model.TransitionTaskStatusFailed
+					comp.Task.Error = "Xname not found in HSM"
+					comp.Task.StatusDesc = "Failed to achieve transition"
+					missing = append(missing, xname)
+					if !dryRun {
+						err = storeTransitionTask(comp.Task)
+						if err != nil {
+							logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+						}
+					}
+				}
 			}
 		}
 	}
-	return xnameMap, badList, nil
-}
 
-// Create an initial set of transition tasks from the transition parameters.
-// This checks for previously existing tasks for the transition and adds them
-// too.
-func setupTransitionTasks(tr *model.Transition) (map[string]*TransitionComponent, []string) {
-	var xnames []string
-	xnameMap := make(map[string]*TransitionComponent)
-
-	// Get any tasks that may have previously been created for our operation.
-	tasks, err := (*GLOB.DSP).GetAllTasksForTransition(tr.TransitionID)
+	///////////////////////////////////////////////////////////////////////////
+	// o Get the power maps data from HSM.
+	///////////////////////////////////////////////////////////////////////////
+	err = (*GLOB.HSM).FillPowerMapData(hsmData)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving tasks for transition, " + tr.TransitionID.String())
-	}
-	// Rebuild our xnameMap based on the previous tasks
-	for i, task := range tasks {
-		xnameMap[task.Xname] = &TransitionComponent{
-			Task: &tasks[i],
-		}
-	}
-	// Make sure all the previously created tasks are in the transition's task array.
-	if len(xnameMap) > 0 {
-		taskMap := make(map[uuid.UUID]bool)
-		for _, taskID := range tr.TaskIDs {
-			taskMap[taskID] = true
-		}
-		for _, comp := range xnameMap {
-			if _, ok := taskMap[comp.Task.TaskID]; !ok {
-				tr.TaskIDs = append(tr.TaskIDs, comp.Task.TaskID)
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retrieving HSM power maps data")
+		// Failed to get data from HSM. Fail everything.
+		for _, xname := range xnames {
+			comp, ok := xnameMap[xname]
+			if !ok {
+				// We don't care about xnames not in our list
+				continue
 			}
-			if comp.Task.Status == model.TransitionTaskStatusNew ||
-				comp.Task.Status == model.TransitionTaskStatusInProgress {
-				xnames = append(xnames, comp.Task.Xname)
+			if comp.Task.Status != model.TransitionTaskStatusNew &&
+				comp.Task.Status != model.TransitionTaskStatusInProgress {
+				// Skip it if it is already complete
+				continue
+			}
+			comp.Task.Status = model.TransitionTaskStatusFailed
+			comp.Task.Error = "Error retrieving HSM data"
+			comp.Task.StatusDesc = "Failed to achieve transition"
+			if !dryRun {
+				err = storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
 			}
 		}
 	}
 
-	// Vet and turn the list of requested xnames into a map
-	for _, loc := range tr.Location {
-		if _, ok := xnameMap[loc.Xname]; ok {
+	// Attach collected data and add any dependent components (i.e. Rosettas).
+	for _, xname := range xnames {
+		comp, ok := xnameMap[xname]
+		if !ok {
+			// We don't care about xnames not in our list
 			continue
 		}
-
-		// Create tasks for everything requested so we can
-		// communicate reasons for failures.
-		task := model.NewTransitionTask(tr.TransitionID, tr.Operation)
-		task.Xname = loc.Xname
-		task.DeputyKey = loc.DeputyKey
-
-		// Weed out invalid xnames and components we can't power control here.
-		compType := xnametypes.GetHMSType(loc.Xname)
-		switch compType {
-		case xnametypes.ChassisBMC:
-			fallthrough
-		case xnametypes.NodeBMC:
-			fallthrough
-		case xnametypes.RouterBMC:
-			fallthrough
-		case xnametypes.Node:
-			fallthrough
-		case xnametypes.Chassis:
-			fallthrough
-		case xnametypes.ComputeModule:
-			fallthrough
-		case xnametypes.RouterModule:
-			fallthrough
-		case xnametypes.MgmtSwitch:
-			fallthrough
-		case xnametypes.MgmtHLSwitch:
-			fallthrough
-		case xnametypes.CDUMgmtSwitch:
-			fallthrough
-		case xnametypes.CabinetPDUPowerConnector:
-			task.StatusDesc = "Gathering data"
-		case xnametypes.HMSTypeInvalid:
-			task.Status = model.TransitionTaskStatusFailed
-			task.Error = "Invalid xname"
-			task.StatusDesc = "Failed to achieve transition"
-		default:
-			task.Status = model.TransitionTaskStatusUnsupported
-			task.Error = "No power control for component type " + compType.String()
-			task.StatusDesc = "Failed to achieve transition"
+		if comp.Task.Status != model.TransitionTaskStatusNew &&
+			comp.Task.Status != model.TransitionTaskStatusInProgress {
+			continue
 		}
-		tr.TaskIDs = append(tr.TaskIDs, task.TaskID)
-		err = (*GLOB.DSP).StoreTransitionTask(task)
-		if err != nil {
-			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+		ps, ok := pStates[xname]
+		if !ok {
+			continue
 		}
-		xnameMap[loc.Xname] = &TransitionComponent{Task: &task}
-		if task.Status == model.TransitionTaskStatusNew {
-			xnames = append(xnames, loc.Xname)
+		hData, ok := hsmData[xname]
+		if !ok {
+			continue
+		}
+
+		actions := make(map[string]string)
+		for _, action := range hData.AllowableActions {
+			actions[strings.ToLower(action)] = action
+		}
+
+		comp.PState = &ps
+		comp.HSMData = hData
+		comp.Actions = actions
+		comp.PowerSupplies = getPowerSupplies(hData)
+
+		// Add any Rosettas if we're powering off RouterModules
+		if (xnametypes.GetHMSType(xname) == xnametypes.RouterModule) &&
+			((hData.BaseData.Class == base.ClassHill.String()) || (hData.BaseData.Class == base.ClassMountain.String())) &&
+			(tr.Operation != model.Operation_On) {
+			switchXname := xname + "e0"
+			_, compOk := xnameMap[switchXname]
+			switchPs, psOk := pStates[switchXname]
+			switchHData, hsmOk := hsmData[switchXname]
+			// Skip if the rosetta is already in our list. The below
+			// will be or has been already done for that component.
+			if psOk && hsmOk && !compOk {
+				task := model.NewTransitionTask(tr.TransitionID, tr.Operation)
+				task.Xname = switchXname
+				task.StatusDesc = "Gathering data"
+				tr.TaskIDs = append(tr.TaskIDs, task.TaskID)
+				if !dryRun {
+					err = storeTransitionTask(&task)
+					if err != nil {
+						logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+					}
+				}
+				switchActions := make(map[string]string)
+				for _, action := range switchHData.AllowableActions {
+					actions[strings.ToLower(action)] = action
+				}
+				xnameMap[switchXname] = &TransitionComponent{
+					Task:          &task,
+					PState:        &switchPs,
+					HSMData:       switchHData,
+					Actions:       switchActions,
+					PowerSupplies: getPowerSupplies(switchHData),
+				}
+			}
 		}
 	}
-	return xnameMap, xnames
+	return missing, true, nil
 }
 
 // Sorts components into groups by power action then comptype so they can follow a proper power sequence.
-func sequenceComponents(operation model.Operation, xnameMap map[string]*TransitionComponent) (map[string]map[xnametypes.HMSType][]*TransitionComponent, []hsm.ReservationData) {
+// Task updates are not stored if dryRun is set.
+func sequenceComponents(operation model.Operation, xnameMap map[string]*TransitionComponent, dryRun bool) (map[string]map[xnametypes.HMSType][]*TransitionComponent, []hsm.ReservationData) {
 	var resData []hsm.ReservationData
 	seqMap := map[string]map[xnametypes.HMSType][]*TransitionComponent{
 		"on":               make(map[xnametypes.HMSType][]*TransitionComponent),
@@ -1356,9 +2174,11 @@ func sequenceComponents(operation model.Operation, xnameMap map[string]*Transiti
 			comp.Task.Status = model.TransitionTaskStatusUnsupported
 			comp.Task.StatusDesc = fmt.Sprintf("Component does not support the specified transition operation, %s", operation.String())
 			comp.Task.Error = "Unsupported for transition operation"
-			err := (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-			if err != nil {
-				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+			if !dryRun {
+				err := storeTransitionTask(comp.Task)
+				if err != nil {
+					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+				}
 			}
 			continue
 		}
@@ -1560,7 +2380,10 @@ func sequenceComponents(operation model.Operation, xnameMap map[string]*Transiti
 			}
 			resData = append(resData, res)
 		}
-		err := (*GLOB.DSP).StoreTransitionTask(*comp.Task)
+		if dryRun {
+			continue
+		}
+		err := storeTransitionTask(comp.Task)
 		if err != nil {
 			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 		}
@@ -1568,6 +2391,75 @@ func sequenceComponents(operation model.Operation, xnameMap map[string]*Transiti
 	return seqMap, resData
 }
 
+// Assembles a TransitionPlan from sequenced components. Each component's steps
+// are the power sequence tiers, and batches within them, it would be acted on
+// in, with any power on stagger delay. Components that time
+// out during gracefulshutdown may additionally get a forceoff at run time.
+func buildTransitionPlan(tr model.Transition, powerSeq []PowerSeqElem, xnameMap map[string]*TransitionComponent, seqMap map[string]map[xnametypes.HMSType][]*TransitionComponent, missing []string) model.TransitionPlan {
+	plan := model.TransitionPlan{
+		Operation:   tr.Operation.String(),
+		Components:  []model.TransitionPlanComponent{},
+		Unsupported: []string{},
+		Missing:     []string{},
+	}
+
+	steps := make(map[string][]model.TransitionPlanStep)
+	for tier, elm := range powerSeq {
+		var compList []*TransitionComponent
+		for _, compType := range elm.CompTypes {
+			compList = append(compList, seqMap[elm.Action][compType]...)
+		}
+		for batch, batchList := range batchComponents(compList, tr.BatchSize, tr.BatchSizePercent) {
+			for _, wave := range staggerComponents(tr, elm.Action, batchList) {
+				for _, comp := range wave.comps {
+					step := model.TransitionPlanStep{
+						Tier:         tier,
+						Batch:        batch,
+						Action:       elm.Action,
+						DelaySeconds: int(wave.offset / time.Second),
+					}
+					steps[comp.Task.Xname] = append(steps[comp.Task.Xname], step)
+				}
+			}
+		}
+	}
+
+	requested := make(map[string]bool)
+	for _, loc := range tr.Location {
+		requested[loc.Xname] = true
+	}
+
+	xnames := make([]string, 0, len(xnameMap))
+	for xname := range xnameMap {
+		xnames = append(xnames, xname)
+	}
+	sort.Strings(xnames)
+	for _, xname := range xnames {
+		comp := xnameMap[xname]
+		if comp.Task.Status == model.TransitionTaskStatusUnsupported {
+			plan.Unsupported = append(plan.Unsupported, xname)
+		}
+		planComp := model.TransitionPlanComponent{
+			Xname:          xname,
+			AutoAdded:      !requested[xname],
+			TaskStatus:     comp.Task.Status,
+			TaskStatusDesc: comp.Task.StatusDesc,
+			Error:          comp.Task.Error,
+			Steps:          steps[xname],
+		}
+		if comp.Task.Status == model.TransitionTaskStatusFailed {
+			// Failed components are skipped by every tier.
+			planComp.Steps = nil
+		} else if len(planComp.Steps) > 0 {
+			planComp.TaskStatusDesc = "Planned"
+		}
+		plan.Components = append(plan.Components, planComp)
+	}
+	plan.Missing = append(plan.Missing, missing...)
+	sort.Strings(plan.Missing)
+	return plan
+}
+
 // Builds a json payload for the redfish command to apply the given power action.
 // The power action comes from the sequence array and gets translated into a redfish
 // value the hardware supports.
@@ -1676,7 +2568,7 @@ func failDependentComps(xnameMap map[string]*TransitionComponent, powerAction st
 				pComp.Task.Status = model.TransitionTaskStatusFailed
 				pComp.Task.Error = errMsg
 				pComp.Task.StatusDesc = errMsg
-				err := (*GLOB.DSP).StoreTransitionTask(*pComp.Task)
+				err := storeTransitionTask(pComp.Task)
 				if err != nil {
 					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
 				}
@@ -1706,11 +2598,25 @@ func transitionsReaper() {
 
 	numComplete := 0
 	for _, transition := range transitions {
+		if transition.Status == model.TransitionStatusScheduled {
+			// Scheduled transitions aren't active so they can't be abandoned
+			// and don't expire until after they're due.
+			if transition.StartAt == nil || !transition.StartAt.After(time.Now()) {
+				startScheduledTransition(transition)
+			}
+			continue
+		}
+		if transition.Status == model.TransitionStatusQueued {
+			// Queued transitions aren't active either. They're started, or
+			// expired, by startQueuedTransitions().
+			continue
+		}
 		expired := transition.AutomaticExpirationTime.Before(time.Now())
 		abandoned := transition.LastActiveTime.Before(time.Now().Add(time.Duration(model.TransitionKeepAliveInterval) * -3 * time.Second))
 		if expired {
 			if transition.Status == model.TransitionStatusAborted ||
-				transition.Status == model.TransitionStatusCompleted {
+				transition.Status == model.TransitionStatusCompleted ||
+				transition.Status == model.TransitionStatusHalted {
 				deleteTransition(transition.TransitionID)
 			} else {
 				transitionOld := transition
@@ -1732,7 +2638,8 @@ func transitionsReaper() {
 			}
 		} else if abandoned &&
 			transition.Status != model.TransitionStatusAborted &&
-			transition.Status != model.TransitionStatusCompleted {
+			transition.Status != model.TransitionStatusCompleted &&
+			transition.Status != model.TransitionStatusHalted {
 			// Assume the transition has been abandoned if it has been 3 times
 			// the keep alive interval since it was last active.
 			// Pick up an abandoned transition by first refreshing its LastActiveTime
@@ -1749,7 +2656,8 @@ func transitionsReaper() {
 				go doTransition(transition.TransitionID)
 			}
 		} else if transition.Status == model.TransitionStatusAborted ||
-			transition.Status == model.TransitionStatusCompleted {
+			transition.Status == model.TransitionStatusCompleted ||
+			transition.Status == model.TransitionStatusHalted {
 			numComplete++
 			// Compress the completed transition if the it was not previuosly compressed
 			// upon completion (probably because it was stored by an older version
@@ -1759,6 +2667,7 @@ func transitionsReaper() {
 			}
 		}
 	}
+	startQueuedTransitions()
 
 	if GLOB.MaxNumCompleted <= 0 {
 		// No limit
@@ -1772,7 +2681,8 @@ func transitionsReaper() {
 		tToDelete := make([]*model.Transition, numDelete)
 		for t, transition := range transitions {
 			if transition.Status != model.TransitionStatusAborted &&
-				transition.Status != model.TransitionStatusCompleted {
+				transition.Status != model.TransitionStatusCompleted &&
+				transition.Status != model.TransitionStatusHalted {
 				continue
 			}
 			for i := 0; i < numDelete; i++ {
@@ -1825,18 +2735,31 @@ func deleteTransition(transitionID uuid.UUID) error {
 }
 
 // Wait for BMCs to become responsive. This waits for the component's
-// ManagementState to become available.
-func waitForBMC(compList []*TransitionComponent) {
-	// Wait a max of ~5mins. As of 01/12/2023 the wait time is ~3mins.
-	for retry := 0; retry < 20; retry++ {
-		isWaiting := false
-		for _, comp := range compList {
-			if retry == 0 {
-				comp.Task.StatusDesc = "Waiting for controller to be ready"
-				err := (*GLOB.DSP).StoreTransitionTask(*comp.Task)
-				if err != nil {
-					logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
-				}
+// ManagementState to become available, or for the waitforbmc task deadline
+// for its component type to pass.
+func waitForBMC(tr model.Transition, compList []*TransitionComponent) {
+	waitExpireTimes := make(map[*TransitionComponent]time.Time)
+	start := time.Now()
+	for _, comp := range compList {
+		deadline, ok := taskDeadline(tr, model.TaskDeadlineWaitForBMC, xnametypes.GetHMSType(comp.Task.Xname))
+		if ok {
+			waitExpireTimes[comp] = start.Add(deadline)
+		}
+		comp.Task.StatusDesc = "Waiting for controller to be ready"
+		err := storeTransitionTask(comp.Task)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error storing transition task")
+		}
+	}
+	waiting := compList
+	for len(waiting) > 0 {
+		var stillWaiting []*TransitionComponent
+		now := time.Now()
+		for _, comp := range waiting {
+			if waitExpireTime, ok := waitExpireTimes[comp]; ok && now.After(waitExpireTime) {
+				// Let the tier's action find out whether it is ready.
+				logger.Log.Warnf("Timed out waiting for controller of %s to be ready", comp.Task.Xname)
+				continue
 			}
 			// Get the state from ETCD
 			pState, err := (*GLOB.DSP).GetPowerStatus(comp.Task.Xname)
@@ -1844,13 +2767,13 @@ func waitForBMC(compList []*TransitionComponent) {
 				// If everything ends up being an error. We'll just stop waiting.
 				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error getting power status from database for %s", comp.Task.Xname)
 			} else if strings.ToLower(pState.ManagementState) != model.ManagementStateFilter_available.String() {
-				isWaiting = true
+				stillWaiting = append(stillWaiting, comp)
 			}
 		}
-		if !isWaiting {
-			break
+		waiting = stillWaiting
+		if len(waiting) > 0 {
+			time.Sleep(waitForBMCInterval)
 		}
-		time.Sleep(15 * time.Second)
 	}
 }
 
@@ -1870,6 +2793,103 @@ func getPowerSupplies(hData *hsm.HsmData) (powerSupplies []PowerSupply) {
 	return
 }
 
+// How many times the reaper tries to start a due scheduled transition before
+// aborting it. Selectors that match no components abort it straight away.
+const maxScheduledStartAttempts = 3
+
+// Failed attempts to start each due scheduled transition. Only the reaper
+// uses this, so it needs no lock.
+var scheduledStartAttempts = make(map[uuid.UUID]int)
+
+// Starts a scheduled transition that is due. A transition that can't be
+// started is aborted, with the error, after maxScheduledStartAttempts tries.
+// For recurring transitions, the next occurrence is stored as a new
+// scheduled transition once this one has started, been queued or aborted.
+func startScheduledTransition(transition model.Transition) {
+	transitionOld := transition
+	conflicts, exceeded, err := admitScheduledTransition(&transition)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error starting scheduled transition, %s.", transition.TransitionID.String())
+		scheduledStartAttempts[transition.TransitionID]++
+		if !errors.Is(err, errEmptySelection) && scheduledStartAttempts[transition.TransitionID] < maxScheduledStartAttempts {
+			// Try again the next time the reaper runs.
+			return
+		}
+		transition.Status = model.TransitionStatusAborted
+		transition.IsCompressed = true
+		transition.Error = err.Error()
+	} else if len(conflicts) > 0 || exceeded != "" {
+		// There's no request to reject, so wait for the conflicts to finish
+		// or for room in the power budgets.
+		transition.Status = model.TransitionStatusQueued
+	} else {
+		transition.Status = model.TransitionStatusNew
+	}
+	transition.LastActiveTime = time.Now()
+	// Use test and set so only one instance starts the transition.
+	ok, err := (*GLOB.DSP).TASTransition(transition, transitionOld)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error starting scheduled transition, %s.", transition.TransitionID.String())
+		return
+	}
+	delete(scheduledStartAttempts, transition.TransitionID)
+	if !ok {
+		return
+	}
+	if transition.Status == model.TransitionStatusAborted {
+		logger.Log.Warnf("Scheduled Transition %s is due but was aborted: %s (%s)",
+			transition.TransitionID.String(), transition.Error, GLOB.PodName)
+		enqueueWebhooks(model.WebhookEventTransitionComplete, transition.CallbackURL, model.ToTransitionResp(transition, nil, false))
+	} else if transition.Status == model.TransitionStatusQueued && len(conflicts) > 0 {
+		logger.Log.Infof("Scheduled Transition %s is due but queued behind %d conflicting transitions (%s)",
+			transition.TransitionID.String(), len(conflicts), GLOB.PodName)
+	} else if transition.Status == model.TransitionStatusQueued {
+		logger.Log.Infof("Scheduled Transition %s is due but queued until it fits in the power budgets (%s)",
+			transition.TransitionID.String(), GLOB.PodName)
+	} else {
+		logger.Log.Infof("Scheduled Transition %s is due (%s)",
+			transition.TransitionID.String(), GLOB.PodName)
+		go doTransition(transition.TransitionID)
+	}
+
+	// The next occurrence gets the selectors, not this one's xnames.
+	next, ok := model.NextScheduledTransition(transitionOld, GLOB.ExpireTimeMins)
+	if !ok {
+		return
+	}
+	err = (*GLOB.DSP).StoreTransition(next)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error scheduling next occurrence of transition, %s.", transition.TransitionID.String())
+		return
+	}
+	logger.Log.Infof("Scheduled Transition %s for %s, next occurrence of %s",
+		next.TransitionID.String(), next.StartAt.String(), transition.TransitionID.String())
+}
+
+// Resolves a due scheduled transition's selectors and checks it for
+// conflicts and against the power budgets, returning the conflicting
+// transitions and the exceeded budget, if any.
+func admitScheduledTransition(transition *model.Transition) ([]uuid.UUID, string, error) {
+	// Selectors are resolved for each occurrence, so recurring transitions
+	// follow changes to group membership.
+	err := resolveLocationSelectors(transition)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving locations: %w", err)
+	}
+	conflicts, err := findConflictingTransitions(*transition)
+	if err != nil {
+		return nil, "", fmt.Errorf("checking for conflicts: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return conflicts, "", nil
+	}
+	exceeded, err := admitPowerBudget(transition)
+	if err != nil {
+		return nil, "", fmt.Errorf("checking power budgets: %w", err)
+	}
+	return nil, exceeded, nil
+}
+
 func compressAndCompleteTransition(transition model.Transition, status string) {
 	// Get the tasks for the transition
 	tasks, err := (*GLOB.DSP).GetAllTasksForTransition(transition.TransitionID)
@@ -1889,6 +2909,13 @@ func compressAndCompleteTransition(transition model.Transition, status string) {
 		// Don't delete the tasks if we were unsuccessful storing the transition.
 		return
 	}
+	publishTransitionEvent(transition.TransitionID, model.TransitionEvent{
+		Event: model.TransitionEventComplete,
+		Data:  model.ToTransitionResp(transition, nil, true),
+	})
+	enqueueWebhooks(model.WebhookEventTransitionComplete, transition.CallbackURL, model.ToTransitionResp(transition, nil, false))
+	// Transitions may have been queued waiting for this one to finish.
+	go startQueuedTransitions()
 	for _, task := range tasks {
 		err = (*GLOB.DSP).DeleteTransitionTask(transition.TransitionID, task.TaskID)
 		if err != nil {
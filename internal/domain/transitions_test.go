@@ -27,8 +27,16 @@
 package domain
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -37,6 +45,7 @@ import (
 	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
 	trsapi "github.com/rainest/hms-trs-app-api/v3/pkg/trs_http_api"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
+	rf "github.com/OpenCHAMI/smd/v2/pkg/redfish"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 
@@ -186,7 +195,7 @@ func (ts *Transitions_TS) TestDoTransition() {
 	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
 	(*GLOB.DSP).StoreTransition(testTransition)
 	doTransition(testTransition.TransitionID)
-	resultsPb = GetTransition(testTransition.TransitionID)
+	resultsPb = GetTransition(testTransition.TransitionID, false)
 	results = resultsPb.Obj.(model.TransitionResp)
 	ts.Assert().Equal(model.TransitionStatusCompleted, results.TransitionStatus,
 		"Test 1 failed with transition status, %s. Expected %s",
@@ -209,7 +218,7 @@ func (ts *Transitions_TS) TestDoTransition() {
 	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
 	(*GLOB.DSP).StoreTransition(testTransition)
 	doTransition(testTransition.TransitionID)
-	resultsPb = GetTransition(testTransition.TransitionID)
+	resultsPb = GetTransition(testTransition.TransitionID, false)
 	results = resultsPb.Obj.(model.TransitionResp)
 	ts.Assert().Equal(model.TransitionStatusCompleted, results.TransitionStatus,
 		"Test 2 failed with transition status, %s. Expected %s",
@@ -239,7 +248,7 @@ func (ts *Transitions_TS) TestDoTransition() {
 	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
 	(*GLOB.DSP).StoreTransition(testTransition)
 	doTransition(testTransition.TransitionID)
-	resultsPb = GetTransition(testTransition.TransitionID)
+	resultsPb = GetTransition(testTransition.TransitionID, false)
 	results = resultsPb.Obj.(model.TransitionResp)
 	ts.Assert().Equal(model.TransitionStatusCompleted, results.TransitionStatus,
 		"Test 3 failed with transition status, %s. Expected %s",
@@ -266,7 +275,7 @@ func (ts *Transitions_TS) TestDoTransition() {
 	for i := 0; i < 60; i++ {
 		time.Sleep(5 * time.Second)
 		getHWStatesFromHW()
-		resultsPb = GetTransition(testTransition.TransitionID)
+		resultsPb = GetTransition(testTransition.TransitionID, false)
 		results = resultsPb.Obj.(model.TransitionResp)
 		if results.TransitionStatus == model.TransitionStatusCompleted {
 			break
@@ -335,7 +344,7 @@ func (ts *Transitions_TS) TestDoTransition() {
 	for i := 0; i < 60; i++ {
 		time.Sleep(5 * time.Second)
 		getHWStatesFromHW()
-		resultsPb = GetTransition(testTransition.TransitionID)
+		resultsPb = GetTransition(testTransition.TransitionID, false)
 		results = resultsPb.Obj.(model.TransitionResp)
 		if results.TransitionStatus == model.TransitionStatusCompleted {
 			break
@@ -403,7 +412,7 @@ func (ts *Transitions_TS) TestAbortTransitionID() {
 	ts.Assert().Equal(http.StatusAccepted, resultsPb.StatusCode,
 		"Test 3 failed with status code, %d. Expected %d",
 		resultsPb.StatusCode, http.StatusAccepted)
-	resultsPb = GetTransition(testTransition.TransitionID)
+	resultsPb = GetTransition(testTransition.TransitionID, false)
 	results = resultsPb.Obj.(model.TransitionResp)
 	ts.Assert().Equal(model.TransitionStatusAbortSignaled, results.TransitionStatus,
 		"Test 3 failed with transition status, %s. Expected %s",
@@ -524,7 +533,7 @@ func (ts *Transitions_TS) TestDoAbort() {
 	}
 	doAbort(testTransition, testXnameMap)
 
-	resultsPb = GetTransition(testTransition.TransitionID)
+	resultsPb = GetTransition(testTransition.TransitionID, false)
 	results = resultsPb.Obj.(model.TransitionResp)
 	ts.Assert().Equal(model.TransitionStatusAborted, results.TransitionStatus,
 		"Test 1 failed with transition status, %s. Expected %s",
@@ -632,7 +641,7 @@ func (ts *Transitions_TS) TestSequenceComponents() {
 		},
 	}
 
-	resultsSeq, _ = sequenceComponents(testTransition.Operation, testXnameMap)
+	resultsSeq, _ = sequenceComponents(testTransition.Operation, testXnameMap, false)
 	ts.Assert().Equal(0, len(resultsSeq["on"]),
 		"Test 1 failed with sequence map 'on' len, %d. Expected %d",
 		len(resultsSeq["on"]), 0)
@@ -749,7 +758,7 @@ func (ts *Transitions_TS) TestSequenceComponents() {
 		},
 	}
 
-	resultsSeq, _ = sequenceComponents(testTransition.Operation, testXnameMap)
+	resultsSeq, _ = sequenceComponents(testTransition.Operation, testXnameMap, false)
 	ts.Assert().Equal(2, len(resultsSeq["on"]),
 		"Test 2 failed with sequence map 'on' len, %d. Expected %d",
 		len(resultsSeq["on"]), 2)
@@ -1078,3 +1087,2264 @@ func (ts *Transitions_TS) TestFailDependentComps() {
 		"Test 1 failed with powerConnector Task.Status, %s. Expected %s",
 		task4.Status, model.TransitionTaskStatusFailed)
 }
+
+func (ts *Transitions_TS) TestBuildTransitionPlan() {
+	var t *testing.T
+	t = ts.T()
+
+	/////////
+	// Test 1 - buildTransitionPlan() - Node hard-restart with a Rosetta and an unsupported component
+	/////////
+	t.Logf("Test 1 - buildTransitionPlan() - Node hard-restart with a Rosetta and an unsupported component")
+	transitionID := uuid.New()
+	tr := model.Transition{
+		TransitionID: transitionID,
+		Operation:    model.Operation_HardRestart,
+		Location: []model.LocationParameter{
+			{Xname: "x0c0s0b0n0"},
+			{Xname: "x0c0s0b0n0p0"},
+		},
+	}
+
+	task1 := model.NewTransitionTask(transitionID, model.Operation_HardRestart)
+	task1.Xname = "x0c0s0b0n0"
+	task2 := model.NewTransitionTask(transitionID, model.Operation_HardRestart)
+	task2.Xname = "x0c0s0b0n0p0"
+	task2.Status = model.TransitionTaskStatusUnsupported
+	task3 := model.NewTransitionTask(transitionID, model.Operation_HardRestart)
+	task3.Xname = "x0c0r1e0"
+
+	node := &TransitionComponent{Task: &task1}
+	rosetta := &TransitionComponent{Task: &task3}
+	testXnameMap := map[string]*TransitionComponent{
+		task1.Xname: node,
+		task2.Xname: {Task: &task2},
+		task3.Xname: rosetta,
+	}
+	seqMap := map[string]map[xnametypes.HMSType][]*TransitionComponent{
+		"gracefulshutdown": {
+			xnametypes.Node:          {node},
+			xnametypes.RouterModule:  {},
+			xnametypes.ComputeModule: {},
+		},
+		"on": {
+			xnametypes.Node: {node},
+		},
+	}
+
+	plan := buildTransitionPlan(tr, PowerSequenceFull, testXnameMap, seqMap, []string{"x0c0s1b0n0"})
+
+	ts.Assert().Equal(model.Operation_HardRestart.String(), plan.Operation)
+	ts.Assert().Equal([]string{"x0c0s0b0n0p0"}, plan.Unsupported)
+	ts.Assert().Equal([]string{"x0c0s1b0n0"}, plan.Missing)
+	ts.Require().Len(plan.Components, 3)
+	for _, comp := range plan.Components {
+		switch comp.Xname {
+		case task1.Xname:
+			ts.Assert().False(comp.AutoAdded)
+			ts.Assert().Equal([]model.TransitionPlanStep{
+				{Tier: 0, Action: "gracefulshutdown"},
+				{Tier: 13, Action: "on"},
+			}, comp.Steps)
+		case task2.Xname:
+			ts.Assert().False(comp.AutoAdded)
+			ts.Assert().Empty(comp.Steps)
+		case task3.Xname:
+			ts.Assert().True(comp.AutoAdded)
+			ts.Assert().Empty(comp.Steps)
+		}
+	}
+}
+
+func (ts *Transitions_TS) TestValidatePowerSequence() {
+	var t *testing.T
+	t = ts.T()
+
+	/////////
+	// Test 1 - validatePowerSequence() - Valid sequence is normalized
+	/////////
+	t.Logf("Test 1 - validatePowerSequence() - Valid sequence is normalized")
+	seq := model.PowerSequence{
+		Name: "nodes-only",
+		Steps: model.PowerSequenceStepSlice{
+			{Action: "GracefulShutdown", ComponentTypes: []string{"node"}},
+			{Action: "forceoff", ComponentTypes: []string{"Node"}},
+			{Action: "on", ComponentTypes: []string{"NODE"}},
+		},
+	}
+	seq, elems, err := validatePowerSequence(seq)
+	ts.Require().NoError(err)
+	ts.Assert().Equal(model.PowerSequenceStepSlice{
+		{Action: "gracefulshutdown", ComponentTypes: []string{"Node"}},
+		{Action: "forceoff", ComponentTypes: []string{"Node"}},
+		{Action: "on", ComponentTypes: []string{"Node"}},
+	}, seq.Steps)
+	ts.Assert().Equal([]PowerSeqElem{
+		{Action: "gracefulshutdown", CompTypes: []xnametypes.HMSType{xnametypes.Node}},
+		{Action: "forceoff", CompTypes: []xnametypes.HMSType{xnametypes.Node}},
+		{Action: "on", CompTypes: []xnametypes.HMSType{xnametypes.Node}},
+	}, elems)
+
+	/////////
+	// Test 2 - validatePowerSequence() - Invalid sequences
+	/////////
+	t.Logf("Test 2 - validatePowerSequence() - Invalid sequences")
+	invalid := map[string]model.PowerSequence{
+		"reserved name": {
+			Name:  DefaultPowerSequenceName,
+			Steps: model.PowerSequenceStepSlice{{Action: "on", ComponentTypes: []string{"Node"}}},
+		},
+		"bad name": {
+			Name:  "../nodes",
+			Steps: model.PowerSequenceStepSlice{{Action: "on", ComponentTypes: []string{"Node"}}},
+		},
+		"no steps": {
+			Name: "empty",
+		},
+		"bad action": {
+			Name:  "reboot",
+			Steps: model.PowerSequenceStepSlice{{Action: "reboot", ComponentTypes: []string{"Node"}}},
+		},
+		"unsupported type": {
+			Name:  "drives",
+			Steps: model.PowerSequenceStepSlice{{Action: "on", ComponentTypes: []string{"Drive"}}},
+		},
+		"duplicate step": {
+			Name: "twice",
+			Steps: model.PowerSequenceStepSlice{
+				{Action: "on", ComponentTypes: []string{"Node"}},
+				{Action: "on", ComponentTypes: []string{"Node"}},
+			},
+		},
+		"shutdown without forceoff": {
+			Name:  "soft",
+			Steps: model.PowerSequenceStepSlice{{Action: "gracefulshutdown", ComponentTypes: []string{"Node"}}},
+		},
+		"on before off": {
+			Name: "backwards",
+			Steps: model.PowerSequenceStepSlice{
+				{Action: "on", ComponentTypes: []string{"Node"}},
+				{Action: "forceoff", ComponentTypes: []string{"Node"}},
+			},
+		},
+	}
+	for desc, seq := range invalid {
+		_, _, err := validatePowerSequence(seq)
+		ts.Assert().Error(err, "Test 2 expected an error for %s", desc)
+	}
+
+	/////////
+	// Test 3 - toModelPowerSequence() - Default sequence round trips
+	/////////
+	t.Logf("Test 3 - toModelPowerSequence() - Default sequence round trips")
+	def := toModelPowerSequence("copy", PowerSequenceFull)
+	ts.Assert().Equal(model.PowerSequenceSourceBuiltIn, def.Source)
+	_, elems, err = validatePowerSequence(def)
+	ts.Require().NoError(err)
+	ts.Assert().Equal(PowerSequenceFull, elems)
+}
+
+func (ts *Transitions_TS) TestBatchComponents() {
+	var t *testing.T
+	t = ts.T()
+
+	transitionID := uuid.New()
+	var compList []*TransitionComponent
+	for i := 0; i < 10; i++ {
+		task := model.NewTransitionTask(transitionID, model.Operation_On)
+		task.Xname = fmt.Sprintf("x0c0s%db0n0", i)
+		compList = append(compList, &TransitionComponent{Task: &task})
+	}
+	batchLens := func(batches [][]*TransitionComponent) []int {
+		var lens []int
+		for _, batch := range batches {
+			lens = append(lens, len(batch))
+		}
+		return lens
+	}
+
+	/////////
+	// Test 1 - batchComponents() - No limit
+	/////////
+	t.Logf("Test 1 - batchComponents() - No limit")
+	ts.Assert().Equal([]int{10}, batchLens(batchComponents(compList, 0, 0)))
+
+	/////////
+	// Test 2 - batchComponents() - Absolute batch size
+	/////////
+	t.Logf("Test 2 - batchComponents() - Absolute batch size")
+	batches := batchComponents(compList, 4, 0)
+	ts.Assert().Equal([]int{4, 4, 2}, batchLens(batches))
+	ts.Assert().Equal(compList[4], batches[1][0])
+
+	/////////
+	// Test 3 - batchComponents() - Percentage batch size rounds up
+	/////////
+	t.Logf("Test 3 - batchComponents() - Percentage batch size rounds up")
+	ts.Assert().Equal([]int{3, 3, 3, 1}, batchLens(batchComponents(compList, 0, 25)))
+	ts.Assert().Equal([]int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, batchLens(batchComponents(compList, 0, 1)))
+
+	/////////
+	// Test 4 - batchComponents() - Failed components are skipped
+	/////////
+	t.Logf("Test 4 - batchComponents() - Failed components are skipped")
+	compList[0].Task.Status = model.TransitionTaskStatusFailed
+	compList[1].Task.Status = model.TransitionTaskStatusFailed
+	batches = batchComponents(compList, 4, 0)
+	ts.Assert().Equal([]int{4, 4}, batchLens(batches))
+	ts.Assert().Equal(compList[2], batches[0][0])
+}
+
+func (ts *Transitions_TS) TestConfirmTransitionRequestsTimeout() {
+	var t *testing.T
+	t = ts.T()
+
+	tr := model.Transition{TransitionID: uuid.New(), Operation: model.Operation_SoftOff}
+	xnames := []string{"x3010c0s0b0n0", "x3010c0s0b0n1"}
+	xnameMap := make(map[string]*TransitionComponent)
+	for _, xname := range xnames {
+		ts.Require().NoError((*GLOB.DSP).StorePowerStatus(model.PowerStatusComponent{
+			XName:       xname,
+			PowerState:  "on",
+			LastUpdated: time.Now(),
+		}))
+		defer (*GLOB.DSP).DeletePowerStatus(xname)
+		task := model.NewTransitionTask(tr.TransitionID, tr.Operation)
+		task.Xname = xname
+		xnameMap[xname] = &TransitionComponent{
+			Task:        &task,
+			Actions:     map[string]string{"gracefulshutdown": "GracefulShutdown", "forceoff": "ForceOff"},
+			ActionCount: 2,
+		}
+	}
+	seqMap := map[string]map[xnametypes.HMSType][]*TransitionComponent{"forceoff": {}}
+	trsTaskMap := map[uuid.UUID]*TransitionComponent{uuid.New(): xnameMap[xnames[0]]}
+
+	/////////
+	// Test 1 - confirmTransitionRequests() - Timed out components are handed to forceoff
+	/////////
+	t.Logf("Test 1 - confirmTransitionRequests() - Timed out components are handed to forceoff")
+	ts.Assert().False(confirmTransitionRequests(tr, "gracefulshutdown", false, xnameMap, seqMap, nil, trsTaskMap))
+	ts.Assert().Empty(trsTaskMap)
+	ts.Assert().Len(seqMap["forceoff"][xnametypes.Node], 1)
+
+	/////////
+	// Test 2 - confirmTransitionRequests() - Later batches don't recheck timed out components
+	/////////
+	t.Logf("Test 2 - confirmTransitionRequests() - Later batches don't recheck timed out components")
+	ts.Require().NoError((*GLOB.DSP).StorePowerStatus(model.PowerStatusComponent{
+		XName:       xnames[0],
+		PowerState:  "off",
+		LastUpdated: time.Now(),
+	}))
+	trsTaskMap[uuid.New()] = xnameMap[xnames[1]]
+	ts.Assert().False(confirmTransitionRequests(tr, "gracefulshutdown", false, xnameMap, seqMap, nil, trsTaskMap))
+	ts.Assert().Empty(trsTaskMap)
+	ts.Assert().Len(seqMap["forceoff"][xnametypes.Node], 2)
+	ts.Assert().Equal(2, xnameMap[xnames[0]].ActionCount)
+	ts.Assert().NotEqual(model.TaskState_Confirmed, xnameMap[xnames[0]].Task.State)
+}
+
+func (ts *Transitions_TS) TestFailureThresholdExceeded() {
+	var t *testing.T
+	t = ts.T()
+
+	transitionID := uuid.New()
+	xnameMap := make(map[string]*TransitionComponent)
+	for i := 0; i < 10; i++ {
+		task := model.NewTransitionTask(transitionID, model.Operation_On)
+		task.Xname = fmt.Sprintf("x0c0s%db0n0", i)
+		if i < 3 {
+			task.Status = model.TransitionTaskStatusFailed
+		}
+		xnameMap[task.Xname] = &TransitionComponent{Task: &task}
+	}
+
+	/////////
+	// Test 1 - failureThresholdExceeded() - No threshold
+	/////////
+	t.Logf("Test 1 - failureThresholdExceeded() - No threshold")
+	ts.Assert().False(failureThresholdExceeded(model.Transition{}, xnameMap))
+
+	/////////
+	// Test 2 - failureThresholdExceeded() - maxFailures
+	/////////
+	t.Logf("Test 2 - failureThresholdExceeded() - maxFailures")
+	ts.Assert().False(failureThresholdExceeded(model.Transition{MaxFailures: 3}, xnameMap))
+	ts.Assert().True(failureThresholdExceeded(model.Transition{MaxFailures: 2}, xnameMap))
+
+	/////////
+	// Test 3 - failureThresholdExceeded() - maxFailurePercent
+	/////////
+	t.Logf("Test 3 - failureThresholdExceeded() - maxFailurePercent")
+	ts.Assert().False(failureThresholdExceeded(model.Transition{MaxFailurePercent: 30}, xnameMap))
+	ts.Assert().True(failureThresholdExceeded(model.Transition{MaxFailurePercent: 25}, xnameMap))
+}
+
+func (ts *Transitions_TS) TestRetryTransition() {
+	var (
+		t              *testing.T
+		testParams     model.TransitionParameter
+		testTransition model.Transition
+		resultsPb      model.Passback
+	)
+	t = ts.T()
+
+	/////////
+	// Test 1 - RetryTransition() Does not exist.
+	/////////
+	t.Logf("Test 1 - RetryTransition() Does not exist.")
+	resultsPb = RetryTransition(uuid.New(), model.TransitionRetryParameter{})
+	ts.Assert().Equal(http.StatusNotFound, resultsPb.StatusCode,
+		"Test 1 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusNotFound)
+
+	/////////
+	// Test 2 - RetryTransition() Not finished.
+	/////////
+	t.Logf("Test 2 - RetryTransition() Not finished.")
+	testParams = model.TransitionParameter{
+		Operation: "On",
+		Location: []model.LocationParameter{
+			{Xname: "x1000c0s1b0n0"},
+			{Xname: "x1000c0s2b0n0", DeputyKey: "deputy"},
+			{Xname: "x1000c0s3b0n0"},
+		},
+	}
+	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusInProgress
+	(*GLOB.DSP).StoreTransition(testTransition)
+	resultsPb = RetryTransition(testTransition.TransitionID, model.TransitionRetryParameter{})
+	ts.Assert().Equal(http.StatusBadRequest, resultsPb.StatusCode,
+		"Test 2 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusBadRequest)
+
+	/////////
+	// Test 3 - RetryTransition() No matching failed tasks.
+	/////////
+	t.Logf("Test 3 - RetryTransition() No matching failed tasks.")
+	testTransition.Status = model.TransitionStatusCompleted
+	testTransition.IsCompressed = true
+	testTransition.Tasks = model.TransitionTaskRespSlice{
+		{Xname: "x1000c0s1b0n0", TaskStatus: model.TransitionTaskStatusSucceeded},
+		{Xname: "x1000c0s2b0n0", TaskStatus: model.TransitionTaskStatusFailed, Error: "Timed out"},
+		{Xname: "x1000c0s3b0n0", TaskStatus: model.TransitionTaskStatusFailed, Error: "Missing xname"},
+	}
+	(*GLOB.DSP).StoreTransition(testTransition)
+	resultsPb = RetryTransition(testTransition.TransitionID, model.TransitionRetryParameter{ErrorFilter: "Unauthorized"})
+	ts.Assert().Equal(http.StatusBadRequest, resultsPb.StatusCode,
+		"Test 3 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusBadRequest)
+
+	/////////
+	// Test 4 - RetryTransition() Success.
+	/////////
+	t.Logf("Test 4 - RetryTransition() Success.")
+	resultsPb = RetryTransition(testTransition.TransitionID, model.TransitionRetryParameter{ErrorFilter: "Timed out"})
+	ts.Require().Equal(http.StatusOK, resultsPb.StatusCode,
+		"Test 4 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusOK)
+	creation := resultsPb.Obj.(model.TransitionCreation)
+	retry, _, err := (*GLOB.DSP).GetTransition(creation.TransitionID)
+	ts.Require().NoError(err)
+	ts.Assert().Equal(testTransition.TransitionID, *retry.ParentID)
+	ts.Assert().Equal(testTransition.Operation, retry.Operation)
+	ts.Assert().Equal(model.LocationParameterSlice{{Xname: "x1000c0s2b0n0", DeputyKey: "deputy"}}, retry.Location)
+	AbortTransitionID(retry.TransitionID)
+}
+
+func (ts *Transitions_TS) TestAbortTransitionTask() {
+	var (
+		t              *testing.T
+		testParams     model.TransitionParameter
+		testTransition model.Transition
+		resultsPb      model.Passback
+	)
+	t = ts.T()
+
+	/////////
+	// Test 1 - AbortTransitionTask() Transition does not exist.
+	/////////
+	t.Logf("Test 1 - AbortTransitionTask() Transition does not exist.")
+	resultsPb = AbortTransitionTask(uuid.New(), "x0c0s1b0n0")
+	ts.Assert().Equal(http.StatusNotFound, resultsPb.StatusCode,
+		"Test 1 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusNotFound)
+
+	/////////
+	// Test 2 - AbortTransitionTask() Component not in the transition.
+	/////////
+	t.Logf("Test 2 - AbortTransitionTask() Component not in the transition.")
+	testParams = model.TransitionParameter{
+		Operation: "Off",
+		Location: []model.LocationParameter{
+			{Xname: "x0c0s1b0n0"},
+			{Xname: "x0c0s2b0n0"},
+		},
+	}
+	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusInProgress
+	(*GLOB.DSP).StoreTransition(testTransition)
+	resultsPb = AbortTransitionTask(testTransition.TransitionID, "x0c0s3b0n0")
+	ts.Assert().Equal(http.StatusNotFound, resultsPb.StatusCode,
+		"Test 2 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusNotFound)
+
+	/////////
+	// Test 3 - AbortTransitionTask() Task already finished.
+	/////////
+	t.Logf("Test 3 - AbortTransitionTask() Task already finished.")
+	task := model.NewTransitionTask(testTransition.TransitionID, testTransition.Operation)
+	task.Xname = "x0c0s2b0n0"
+	task.Status = model.TransitionTaskStatusSucceeded
+	(*GLOB.DSP).StoreTransitionTask(task)
+	resultsPb = AbortTransitionTask(testTransition.TransitionID, "x0c0s2b0n0")
+	ts.Assert().Equal(http.StatusBadRequest, resultsPb.StatusCode,
+		"Test 3 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusBadRequest)
+
+	/////////
+	// Test 4 - AbortTransitionTask() Success.
+	/////////
+	t.Logf("Test 4 - AbortTransitionTask() Success.")
+	resultsPb = AbortTransitionTask(testTransition.TransitionID, "x0c0s1b0n0")
+	ts.Assert().Equal(http.StatusAccepted, resultsPb.StatusCode,
+		"Test 4 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusAccepted)
+	// Repeating the abort is harmless
+	resultsPb = AbortTransitionTask(testTransition.TransitionID, "x0c0s1b0n0")
+	ts.Assert().Equal(http.StatusAccepted, resultsPb.StatusCode,
+		"Test 4 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusAccepted)
+	stored, _, _ := (*GLOB.DSP).GetTransition(testTransition.TransitionID)
+	ts.Assert().Equal(model.XnameSlice{"x0c0s1b0n0"}, stored.AbortedXnames)
+
+	/////////
+	// Test 5 - checkComponentAborts() Aborts the task and stops confirming it.
+	/////////
+	t.Logf("Test 5 - checkComponentAborts() Aborts the task and stops confirming it.")
+	xnameMap := make(map[string]*TransitionComponent)
+	trsTaskMap := make(map[uuid.UUID]*TransitionComponent)
+	for _, xname := range []string{"x0c0s1b0n0", "x0c0s3b0n0"} {
+		task := model.NewTransitionTask(testTransition.TransitionID, testTransition.Operation)
+		task.Xname = xname
+		task.Status = model.TransitionTaskStatusInProgress
+		xnameMap[xname] = &TransitionComponent{Task: &task}
+		trsTaskMap[uuid.New()] = xnameMap[xname]
+	}
+	checkComponentAborts(testTransition, "forceoff", xnameMap, nil, trsTaskMap)
+	ts.Assert().Equal(model.TransitionTaskStatusAborted, xnameMap["x0c0s1b0n0"].Task.Status)
+	ts.Assert().Equal(model.TransitionTaskStatusInProgress, xnameMap["x0c0s3b0n0"].Task.Status)
+	ts.Assert().Len(trsTaskMap, 1)
+	for _, comp := range trsTaskMap {
+		ts.Assert().Equal("x0c0s3b0n0", comp.Task.Xname)
+	}
+}
+
+func (ts *Transitions_TS) TestPauseTransition() {
+	var (
+		t              *testing.T
+		testParams     model.TransitionParameter
+		testTransition model.Transition
+		resultsPb      model.Passback
+	)
+	t = ts.T()
+
+	/////////
+	// Test 1 - PauseTransition() Does not exist.
+	/////////
+	t.Logf("Test 1 - PauseTransition() Does not exist.")
+	resultsPb = PauseTransition(uuid.New())
+	ts.Assert().Equal(http.StatusNotFound, resultsPb.StatusCode,
+		"Test 1 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusNotFound)
+
+	/////////
+	// Test 2 - PauseTransition() Already complete.
+	/////////
+	t.Logf("Test 2 - PauseTransition() Already complete.")
+	testParams = model.TransitionParameter{
+		Operation: "On",
+		Location: []model.LocationParameter{
+			{Xname: "x0c0s1b0n0"},
+		},
+	}
+	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusCompleted
+	(*GLOB.DSP).StoreTransition(testTransition)
+	resultsPb = PauseTransition(testTransition.TransitionID)
+	ts.Assert().Equal(http.StatusBadRequest, resultsPb.StatusCode,
+		"Test 2 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusBadRequest)
+
+	/////////
+	// Test 3 - PauseTransition() Success.
+	/////////
+	t.Logf("Test 3 - PauseTransition() Success.")
+	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusInProgress
+	(*GLOB.DSP).StoreTransition(testTransition)
+	resultsPb = PauseTransition(testTransition.TransitionID)
+	ts.Assert().Equal(http.StatusAccepted, resultsPb.StatusCode,
+		"Test 3 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusAccepted)
+	stored, _, _ := (*GLOB.DSP).GetTransition(testTransition.TransitionID)
+	ts.Assert().Equal(model.TransitionStatusPaused, stored.Status)
+
+	// Storing the transition's progress doesn't unpause it.
+	abort, err := storeTransition(testTransition)
+	ts.Assert().NoError(err)
+	ts.Assert().False(abort)
+	stored, _, _ = (*GLOB.DSP).GetTransition(testTransition.TransitionID)
+	ts.Assert().Equal(model.TransitionStatusPaused, stored.Status)
+
+	/////////
+	// Test 4 - waitWhilePaused() Returns once resumed.
+	/////////
+	t.Logf("Test 4 - waitWhilePaused() Returns once resumed.")
+	defer func(interval time.Duration) { pausePollInterval = interval }(pausePollInterval)
+	pausePollInterval = 100 * time.Millisecond
+	done := make(chan bool)
+	go func() {
+		waitWhilePaused(testTransition)
+		done <- true
+	}()
+	select {
+	case <-done:
+		ts.Fail("Test 4 failed. waitWhilePaused() returned while paused")
+	case <-time.After(300 * time.Millisecond):
+	}
+	resultsPb = ResumeTransition(testTransition.TransitionID)
+	ts.Assert().Equal(http.StatusAccepted, resultsPb.StatusCode,
+		"Test 4 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusAccepted)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		ts.Fail("Test 4 failed. waitWhilePaused() didn't return once resumed")
+	}
+	stored, _, _ = (*GLOB.DSP).GetTransition(testTransition.TransitionID)
+	ts.Assert().Equal(model.TransitionStatusInProgress, stored.Status)
+
+	/////////
+	// Test 5 - ResumeTransition() Not paused.
+	/////////
+	t.Logf("Test 5 - ResumeTransition() Not paused.")
+	resultsPb = ResumeTransition(testTransition.TransitionID)
+	ts.Assert().Equal(http.StatusBadRequest, resultsPb.StatusCode,
+		"Test 5 failed with status code, %d. Expected %d",
+		resultsPb.StatusCode, http.StatusBadRequest)
+}
+
+func (ts *Transitions_TS) TestWatchTransition() {
+	var (
+		t              *testing.T
+		testParams     model.TransitionParameter
+		testTransition model.Transition
+	)
+	t = ts.T()
+	// Changes made by this instance shouldn't need a poll to be seen.
+	defer func(interval time.Duration) { transitionEventFallbackInterval = interval }(transitionEventFallbackInterval)
+	transitionEventFallbackInterval = time.Hour
+
+	testParams = model.TransitionParameter{
+		Operation: "On",
+		Location: []model.LocationParameter{
+			{Xname: "x0c0s1b0n0"},
+			{Xname: "x0c0s2b0n0"},
+		},
+	}
+	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusInProgress
+	var tasks []model.TransitionTask
+	for _, loc := range testParams.Location {
+		task := model.NewTransitionTask(testTransition.TransitionID, testTransition.Operation)
+		task.Xname = loc.Xname
+		task.Status = model.TransitionTaskStatusInProgress
+		task.StatusDesc = "Confirming successful transition, on"
+		testTransition.TaskIDs = append(testTransition.TaskIDs, task.TaskID)
+		(*GLOB.DSP).StoreTransitionTask(task)
+		tasks = append(tasks, task)
+	}
+	(*GLOB.DSP).StoreTransition(testTransition)
+
+	events := make(chan model.TransitionEvent, 10)
+	done := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		done <- WatchTransition(ctx, testTransition.TransitionID, func(event model.TransitionEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	nextEvent := func() model.TransitionEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(2 * time.Second):
+			ts.FailNow("Timed out waiting for an event")
+		}
+		return model.TransitionEvent{}
+	}
+
+	/////////
+	// Test 1 - WatchTransition() Initial state
+	/////////
+	t.Logf("Test 1 - WatchTransition() Initial state")
+	for range tasks {
+		event := nextEvent()
+		ts.Assert().Equal(model.TransitionEventTask, event.Event)
+		ts.Assert().Equal(model.TransitionTaskStatusInProgress, event.Data.(model.TransitionTaskResp).TaskStatus)
+	}
+
+	/////////
+	// Test 2 - WatchTransition() Task change
+	/////////
+	t.Logf("Test 2 - WatchTransition() Task change")
+	tasks[0].Status = model.TransitionTaskStatusSucceeded
+	tasks[0].StatusDesc = "Transition confirmed, on"
+	ts.Require().NoError(storeTransitionTask(&tasks[0]))
+	event := nextEvent()
+	ts.Assert().Equal(model.TransitionEventTask, event.Event)
+	ts.Assert().Equal(model.TransitionTaskResp{
+		Xname:          tasks[0].Xname,
+		TaskStatus:     model.TransitionTaskStatusSucceeded,
+		TaskStatusDesc: "Transition confirmed, on",
+	}, event.Data)
+
+	/////////
+	// Test 3 - WatchTransition() Completion
+	/////////
+	t.Logf("Test 3 - WatchTransition() Completion")
+	tasks[1].Status = model.TransitionTaskStatusFailed
+	tasks[1].StatusDesc = "Failed to achieve transition"
+	ts.Require().NoError(storeTransitionTask(&tasks[1]))
+	compressAndCompleteTransition(testTransition, model.TransitionStatusCompleted)
+	event = nextEvent()
+	ts.Assert().Equal(model.TransitionEventTask, event.Event)
+	ts.Assert().Equal(model.TransitionTaskStatusFailed, event.Data.(model.TransitionTaskResp).TaskStatus)
+	event = nextEvent()
+	ts.Assert().Equal(model.TransitionEventComplete, event.Event)
+	rsp := event.Data.(model.TransitionResp)
+	ts.Assert().Equal(model.TransitionStatusCompleted, rsp.TransitionStatus)
+	ts.Assert().Equal(1, rsp.TaskCounts.Failed)
+	ts.Assert().Equal(1, rsp.TaskCounts.Succeeded)
+	select {
+	case err := <-done:
+		ts.Assert().NoError(err)
+	case <-time.After(2 * time.Second):
+		ts.Fail("Test 3 failed. WatchTransition() didn't return")
+	}
+	ts.Assert().Empty(transitionWatchers)
+
+	/////////
+	// Test 4 - WatchTransition() Changes made by another instance
+	/////////
+	t.Logf("Test 4 - WatchTransition() Changes made by another instance")
+	transitionEventFallbackInterval = 50 * time.Millisecond
+	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusInProgress
+	task := model.NewTransitionTask(testTransition.TransitionID, testTransition.Operation)
+	task.Xname = "x0c0s1b0n0"
+	task.Status = model.TransitionTaskStatusInProgress
+	testTransition.TaskIDs = []uuid.UUID{task.TaskID}
+	(*GLOB.DSP).StoreTransitionTask(task)
+	(*GLOB.DSP).StoreTransition(testTransition)
+	defer (*GLOB.DSP).DeleteTransition(testTransition.TransitionID)
+	defer (*GLOB.DSP).DeleteTransitionTask(testTransition.TransitionID, task.TaskID)
+	go func() {
+		done <- WatchTransition(ctx, testTransition.TransitionID, func(event model.TransitionEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	ts.Assert().Equal(model.TransitionTaskStatusInProgress, nextEvent().Data.(model.TransitionTaskResp).TaskStatus)
+	task.Status = model.TransitionTaskStatusSucceeded
+	(*GLOB.DSP).StoreTransitionTask(task)
+	ts.Assert().Equal(model.TransitionTaskStatusSucceeded, nextEvent().Data.(model.TransitionTaskResp).TaskStatus)
+	cancel()
+	ts.Assert().ErrorIs(<-done, context.Canceled)
+}
+
+func (ts *Transitions_TS) TestWebhookDelivery() {
+	var (
+		t              *testing.T
+		testParams     model.TransitionParameter
+		testTransition model.Transition
+	)
+	t = ts.T()
+	defer func(urls []string, allowlist []string, secret string) {
+		GLOB.WebhookURLs = urls
+		GLOB.CallbackAllowlist = allowlist
+		GLOB.WebhookSecret = secret
+	}(GLOB.WebhookURLs, GLOB.CallbackAllowlist, GLOB.WebhookSecret)
+	defer func(base time.Duration, max int) {
+		webhookRetryBase = base
+		webhookMaxAttempts = max
+	}(webhookRetryBase, webhookMaxAttempts)
+	webhookRetryBase = 10 * time.Millisecond
+
+	type received struct {
+		path         string
+		event        string
+		timestamp    string
+		signature    string
+		notification model.WebhookNotification
+		body         string
+	}
+	requests := make(chan received, 10)
+	var mu sync.Mutex
+	callbackFailures := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		rcv := received{
+			path:      req.URL.Path,
+			event:     req.Header.Get("X-PCS-Event"),
+			timestamp: req.Header.Get("X-PCS-Timestamp"),
+			signature: req.Header.Get("X-PCS-Signature"),
+			body:      string(body),
+		}
+		json.Unmarshal(body, &rcv.notification)
+		// Ignore other tests' transitions that finish meanwhile.
+		if data, ok := rcv.notification.Data.(map[string]interface{}); ok &&
+			rcv.event == model.WebhookEventTransitionComplete && data["transitionID"] != testTransition.TransitionID.String() {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		requests <- rcv
+		mu.Lock()
+		defer mu.Unlock()
+		if req.URL.Path == "/callback/redirect" {
+			http.Redirect(w, req, "/internal", http.StatusFound)
+			return
+		}
+		if req.URL.Path == "/fail" || (req.URL.Path == "/callback" && callbackFailures > 0) {
+			callbackFailures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	nextRequest := func() received {
+		select {
+		case rcv := <-requests:
+			return rcv
+		case <-time.After(2 * time.Second):
+			ts.FailNow("Timed out waiting for a webhook request")
+		}
+		return received{}
+	}
+	// Only deliveries for this test's records, in case other transitions finish meanwhile.
+	deliveriesFor := func(url string, id uuid.UUID) []model.WebhookDelivery {
+		var found []model.WebhookDelivery
+		deliveries, err := (*GLOB.DSP).GetAllWebhookDeliveries()
+		ts.Require().NoError(err)
+		for _, delivery := range deliveries {
+			if delivery.URL == url && strings.Contains(delivery.Payload, id.String()) {
+				found = append(found, delivery)
+			}
+		}
+		return found
+	}
+	waitForStatus := func(url string, id uuid.UUID, status string) model.WebhookDelivery {
+		for i := 0; i < 50; i++ {
+			deliveries := deliveriesFor(url, id)
+			// A delivery being sent is pending until its attempt is recorded.
+			if len(deliveries) == 1 && deliveries[0].Status == status &&
+				(status != model.WebhookDeliveryStatusPending || deliveries[0].LastError != "") {
+				return deliveries[0]
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		ts.FailNow("Timed out waiting for webhook delivery status " + status)
+		return model.WebhookDelivery{}
+	}
+
+	err := ConfigureWebhooks([]string{srv.URL + "/global"}, []string{srv.URL + "/callback", srv.URL + "/fail"}, "testsecret")
+	ts.Require().NoError(err)
+	testParams = model.TransitionParameter{
+		Operation:   "On",
+		Location:    []model.LocationParameter{{Xname: "x0c0s1b0n0"}},
+		CallbackURL: srv.URL + "/callback",
+	}
+	testTransition, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusInProgress
+	task := model.NewTransitionTask(testTransition.TransitionID, testTransition.Operation)
+	task.Xname = "x0c0s1b0n0"
+	task.Status = model.TransitionTaskStatusSucceeded
+	testTransition.TaskIDs = []uuid.UUID{task.TaskID}
+	(*GLOB.DSP).StoreTransitionTask(task)
+	(*GLOB.DSP).StoreTransition(testTransition)
+
+	/////////
+	// Test 1 - compressAndCompleteTransition() Notifies global and callback URLs
+	/////////
+	t.Logf("Test 1 - compressAndCompleteTransition() Notifies global and callback URLs")
+	compressAndCompleteTransition(testTransition, model.TransitionStatusCompleted)
+	paths := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		rcv := nextRequest()
+		paths[rcv.path] = true
+		ts.Assert().Equal(model.WebhookEventTransitionComplete, rcv.event)
+		sent, err := strconv.ParseInt(rcv.timestamp, 10, 64)
+		ts.Require().NoError(err)
+		ts.Assert().WithinDuration(time.Now(), time.Unix(sent, 0), time.Minute)
+		ts.Assert().Equal(signWebhookPayload("testsecret", rcv.timestamp, rcv.body), rcv.signature)
+		ts.Assert().NotEqual(signWebhookPayload("testsecret", strconv.FormatInt(sent+1, 10), rcv.body), rcv.signature)
+		ts.Assert().Equal(model.WebhookEventTransitionComplete, rcv.notification.Event)
+		data := rcv.notification.Data.(map[string]interface{})
+		ts.Assert().Equal(testTransition.TransitionID.String(), data["transitionID"])
+		ts.Assert().Equal(model.TransitionStatusCompleted, data["transitionStatus"])
+	}
+	ts.Assert().True(paths["/global"])
+	ts.Assert().True(paths["/callback"])
+	waitForStatus(srv.URL+"/global", testTransition.TransitionID, model.WebhookDeliveryStatusDelivered)
+
+	/////////
+	// Test 2 - deliverPendingWebhooks() Retries a failed delivery
+	/////////
+	t.Logf("Test 2 - deliverPendingWebhooks() Retries a failed delivery")
+	delivery := waitForStatus(srv.URL+"/callback", testTransition.TransitionID, model.WebhookDeliveryStatusPending)
+	ts.Assert().Equal(1, delivery.Attempts)
+	ts.Assert().Contains(delivery.LastError, "500")
+	time.Sleep(webhookRetryBase)
+	deliverPendingWebhooks()
+	rcv := nextRequest()
+	ts.Assert().Equal("/callback", rcv.path)
+	delivery = waitForStatus(srv.URL+"/callback", testTransition.TransitionID, model.WebhookDeliveryStatusDelivered)
+	ts.Assert().Equal(2, delivery.Attempts)
+
+	/////////
+	// Test 3 - attemptWebhookDelivery() Gives up after the max attempts
+	/////////
+	t.Logf("Test 3 - attemptWebhookDelivery() Gives up after the max attempts")
+	webhookMaxAttempts = 1
+	GLOB.WebhookURLs = nil
+	taskID := uuid.New()
+	enqueueWebhooks(model.WebhookEventPowerCapComplete, srv.URL+"/fail", model.PowerCapTaskResp{TaskID: taskID})
+	rcv = nextRequest()
+	ts.Assert().Equal(model.WebhookEventPowerCapComplete, rcv.event)
+	delivery = waitForStatus(srv.URL+"/fail", taskID, model.WebhookDeliveryStatusFailed)
+	ts.Assert().Equal(1, delivery.Attempts)
+
+	/////////
+	// Test 4 - sendWebhook() Doesn't follow redirects
+	/////////
+	t.Logf("Test 4 - sendWebhook() Doesn't follow redirects")
+	taskID = uuid.New()
+	enqueueWebhooks(model.WebhookEventPowerCapComplete, srv.URL+"/callback/redirect", model.PowerCapTaskResp{TaskID: taskID})
+	rcv = nextRequest()
+	ts.Assert().Equal("/callback/redirect", rcv.path)
+	delivery = waitForStatus(srv.URL+"/callback/redirect", taskID, model.WebhookDeliveryStatusFailed)
+	ts.Assert().Contains(delivery.LastError, "302")
+	select {
+	case rcv = <-requests:
+		ts.Failf("Followed a redirect", "requested %s", rcv.path)
+	default:
+	}
+
+	/////////
+	// Test 5 - checkCallbackURL() and enqueueWebhooks() Refuse callbacks outside the allowlist
+	/////////
+	t.Logf("Test 5 - checkCallbackURL() and enqueueWebhooks() Refuse callbacks outside the allowlist")
+	ts.Assert().False(checkCallbackURL("").IsError)
+	ts.Assert().False(checkCallbackURL(srv.URL + "/callback/mine").IsError)
+	for _, callbackURL := range []string{
+		srv.URL + "/global",
+		srv.URL + "/callbackx",
+		"http://169.254.169.254/latest/meta-data",
+	} {
+		pb := checkCallbackURL(callbackURL)
+		ts.Assert().True(pb.IsError, callbackURL)
+		ts.Assert().Equal(http.StatusBadRequest, pb.StatusCode, callbackURL)
+	}
+	taskID = uuid.New()
+	enqueueWebhooks(model.WebhookEventPowerCapComplete, srv.URL+"/global", model.PowerCapTaskResp{TaskID: taskID})
+	ts.Assert().Empty(deliveriesFor(srv.URL+"/global", taskID))
+
+	ts.Assert().Error(ConfigureWebhooks([]string{srv.URL + "/global"}, nil, ""))
+	ts.Assert().Error(ConfigureWebhooks(nil, []string{srv.URL}, ""))
+	ts.Assert().Error(ConfigureWebhooks(nil, []string{"file:///etc"}, "testsecret"))
+	ts.Assert().NoError(ConfigureWebhooks(nil, nil, ""))
+	pb := checkCallbackURL(srv.URL + "/callback")
+	ts.Assert().True(pb.IsError)
+	ts.Assert().Contains(pb.Error.Detail, "not enabled")
+}
+
+func (ts *Transitions_TS) TestIdempotencyKey() {
+	var (
+		t          *testing.T
+		testParams model.TransitionParameter
+		pb         model.Passback
+	)
+	t = ts.T()
+	defer func(mins int) {
+		GLOB.IdempotencyMins = mins
+	}(GLOB.IdempotencyMins)
+	ts.Require().NoError(ConfigureIdempotencyKeys(60))
+
+	requester := model.Requester{Subject: "automation"}
+	key := uuid.New().String()
+	testParams = model.TransitionParameter{
+		Operation: "On",
+		Location:  []model.LocationParameter{{Xname: "x0c0s1b0n0"}},
+	}
+	starts := 0
+	created := model.TransitionCreation{TransitionID: uuid.New(), Operation: "On"}
+	start := func() model.Passback {
+		starts++
+		return model.BuildSuccessPassback(http.StatusOK, created)
+	}
+
+	/////////
+	// Test 1 - startOnce() Starts the first request
+	/////////
+	t.Logf("Test 1 - startOnce() Starts the first request")
+	pb = startOnce(model.IdempotencyScopeTransition, requester, key, testParams, decodeTransitionCreation, start)
+	ts.Require().False(pb.IsError, "%v", pb.Obj)
+	ts.Assert().Equal(1, starts)
+	ts.Assert().Equal(created, pb.Obj.(model.TransitionCreation))
+
+	/////////
+	// Test 2 - startOnce() Replays the response to a repeated key
+	/////////
+	t.Logf("Test 2 - startOnce() Replays the response to a repeated key")
+	pb = startOnce(model.IdempotencyScopeTransition, requester, key, testParams, decodeTransitionCreation, start)
+	ts.Require().False(pb.IsError, "%v", pb.Obj)
+	ts.Assert().Equal(http.StatusOK, pb.StatusCode)
+	ts.Assert().Equal(1, starts)
+	ts.Assert().Equal(created, pb.Obj.(model.TransitionCreation))
+
+	/////////
+	// Test 3 - startOnce() Rejects a repeated key with a different request
+	/////////
+	t.Logf("Test 3 - startOnce() Rejects a repeated key with a different request")
+	otherParams := testParams
+	otherParams.Operation = "Off"
+	pb = startOnce(model.IdempotencyScopeTransition, requester, key, otherParams, decodeTransitionCreation, start)
+	ts.Assert().True(pb.IsError)
+	ts.Assert().Equal(http.StatusUnprocessableEntity, pb.StatusCode)
+	ts.Assert().Equal(1, starts)
+
+	/////////
+	// Test 4 - startOnce() Scopes keys by request type and requester
+	/////////
+	t.Logf("Test 4 - startOnce() Scopes keys by request type and requester")
+	pb = startOnce(model.IdempotencyScopePowerCapPatch, requester, key, testParams, decodeTransitionCreation, start)
+	ts.Assert().False(pb.IsError)
+	pb = startOnce(model.IdempotencyScopeTransition, model.Requester{Subject: "someone-else"}, key, testParams, decodeTransitionCreation, start)
+	ts.Assert().False(pb.IsError)
+	ts.Assert().Equal(3, starts)
+
+	/////////
+	// Test 5 - startOnce() Forgets the key when the request fails
+	/////////
+	t.Logf("Test 5 - startOnce() Forgets the key when the request fails")
+	failKey := uuid.New().String()
+	fail := func() model.Passback {
+		starts++
+		return model.BuildErrorPassback(http.StatusInternalServerError, errors.New("storage is down"))
+	}
+	pb = startOnce(model.IdempotencyScopeTransition, requester, failKey, testParams, decodeTransitionCreation, fail)
+	ts.Assert().True(pb.IsError)
+	pb = startOnce(model.IdempotencyScopeTransition, requester, failKey, testParams, decodeTransitionCreation, start)
+	ts.Assert().False(pb.IsError)
+	ts.Assert().Equal(5, starts)
+
+	/////////
+	// Test 6 - idempotencyReaper() Removes expired keys so they can be reused
+	/////////
+	t.Logf("Test 6 - idempotencyReaper() Removes expired keys so they can be reused")
+	records, err := (*GLOB.DSP).GetAllIdempotencyRecords()
+	ts.Require().NoError(err)
+	for _, record := range records {
+		if record.Key == key && record.Subject == requester.Subject && record.Scope == model.IdempotencyScopeTransition {
+			record.AutomaticExpirationTime = time.Now().Add(-time.Minute)
+			ts.Require().NoError((*GLOB.DSP).StoreIdempotencyRecord(record))
+		}
+	}
+	idempotencyReaper()
+	pb = startOnce(model.IdempotencyScopeTransition, requester, key, testParams, decodeTransitionCreation, start)
+	ts.Assert().False(pb.IsError)
+	ts.Assert().Equal(6, starts)
+
+	/////////
+	// Test 7 - startOnce() Takes over a claim left without a response
+	/////////
+	t.Logf("Test 7 - startOnce() Takes over a claim left without a response")
+	staleKey := uuid.New().String()
+	stale, err := model.NewIdempotencyRecord(model.IdempotencyScopeTransition, requester, staleKey, testParams, GLOB.IdempotencyMins)
+	ts.Require().NoError(err)
+	_, claimed, err := (*GLOB.DSP).ClaimIdempotencyRecord(stale)
+	ts.Require().NoError(err)
+	ts.Require().True(claimed)
+	pb = startOnce(model.IdempotencyScopeTransition, requester, staleKey, testParams, decodeTransitionCreation, start)
+	ts.Assert().Equal(http.StatusConflict, pb.StatusCode)
+	ts.Assert().Equal(6, starts)
+
+	leftBehind := stale
+	leftBehind.CreateTime = stale.CreateTime.Add(-idempotencyClaimLease - time.Minute)
+	stored, err := (*GLOB.DSP).TASIdempotencyRecord(leftBehind, stale)
+	ts.Require().NoError(err)
+	ts.Require().True(stored)
+	pb = startOnce(model.IdempotencyScopeTransition, requester, staleKey, testParams, decodeTransitionCreation, start)
+	ts.Require().False(pb.IsError, "%v", pb.Obj)
+	ts.Assert().Equal(7, starts)
+	pb = startOnce(model.IdempotencyScopeTransition, requester, staleKey, testParams, decodeTransitionCreation, start)
+	ts.Require().False(pb.IsError, "%v", pb.Obj)
+	ts.Assert().Equal(7, starts)
+	ts.Assert().Equal(created, pb.Obj.(model.TransitionCreation))
+}
+
+func (ts *Transitions_TS) TestTransitionConflicts() {
+	var (
+		t          *testing.T
+		testParams model.TransitionParameter
+		active     model.Transition
+		pb         model.Passback
+		err        error
+	)
+	t = ts.T()
+
+	/////////
+	// Test 1 - locationsOverlap() Matches parents and children, not siblings
+	/////////
+	t.Logf("Test 1 - locationsOverlap() Matches parents and children, not siblings")
+	requested, withAncestors := xnameHierarchy([]model.LocationParameter{{Xname: "x2000c0s1b0n0"}}, nil)
+	for xname, want := range map[string]bool{
+		"x2000c0s1b0n0": true,
+		"x2000c0":       true,
+		"x2000c0s1":     true,
+		"x2000c0s1b0":   true,
+		"x2000c0s1b0n1": false,
+		"x2000c0s10":    false,
+		"x2000c1":       false,
+	} {
+		ts.Assert().Equal(want, locationsOverlap(requested, withAncestors, []model.LocationParameter{{Xname: xname}}, nil), xname)
+	}
+	requested, withAncestors = xnameHierarchy([]model.LocationParameter{{Xname: "x2000c0"}}, nil)
+	ts.Assert().True(locationsOverlap(requested, withAncestors, []model.LocationParameter{{Xname: "x2000c0s1b0n0"}}, nil))
+	ts.Assert().False(locationsOverlap(requested, withAncestors, []model.LocationParameter{{Xname: "x2000c0s1b0n0"}}, []string{"x2000c0s1b0n0"}))
+
+	/////////
+	// Test 2 - TriggerTransition() Rejects a transition under an active one
+	/////////
+	t.Logf("Test 2 - TriggerTransition() Rejects a transition under an active one")
+	_, err = model.ToTransition(model.TransitionParameter{Operation: "On", ConflictPolicy: "wait"}, GLOB.ExpireTimeMins)
+	ts.Assert().Error(err)
+	testParams = model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x2000c0"}},
+	}
+	active, _ = model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	active.Status = model.TransitionStatusInProgress
+	(*GLOB.DSP).StoreTransition(active)
+	testParams = model.TransitionParameter{
+		Operation: "On",
+		Location:  []model.LocationParameter{{Xname: "x2000c0s1b0n0"}},
+	}
+	tr, _ := model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	pb = TriggerTransition(tr)
+	ts.Assert().Equal(http.StatusConflict, pb.StatusCode)
+	ts.Assert().Contains(pb.Error.Detail, active.TransitionID.String())
+	_, _, err = (*GLOB.DSP).GetTransition(tr.TransitionID)
+	ts.Assert().Error(err)
+
+	/////////
+	// Test 3 - TriggerTransition() Queues a transition under an active one
+	/////////
+	t.Logf("Test 3 - TriggerTransition() Queues a transition under an active one")
+	testParams.ConflictPolicy = model.TransitionConflictQueue
+	queued, _ := model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	pb = TriggerTransition(queued)
+	ts.Require().Equal(http.StatusOK, pb.StatusCode)
+	ts.Assert().Equal(model.TransitionStatusQueued, pb.Obj.(model.TransitionCreation).TransitionStatus)
+
+	// Queued transitions hold their place in line.
+	later, _ := model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	conflicts, err := findConflictingTransitions(later)
+	ts.Require().NoError(err)
+	ts.Assert().ElementsMatch([]uuid.UUID{active.TransitionID, queued.TransitionID}, conflicts)
+
+	/////////
+	// Test 4 - startQueuedTransitions() Starts queued transitions once clear
+	/////////
+	t.Logf("Test 4 - startQueuedTransitions() Starts queued transitions once clear")
+	startQueuedTransitions()
+	stored, _, err := (*GLOB.DSP).GetTransition(queued.TransitionID)
+	ts.Require().NoError(err)
+	ts.Assert().Equal(model.TransitionStatusQueued, stored.Status)
+
+	active.Status = model.TransitionStatusCompleted
+	active.IsCompressed = true
+	(*GLOB.DSP).StoreTransition(active)
+	startQueuedTransitions()
+	stored, _, err = (*GLOB.DSP).GetTransition(queued.TransitionID)
+	ts.Require().NoError(err)
+	ts.Assert().NotEqual(model.TransitionStatusQueued, stored.Status)
+
+	/////////
+	// Test 5 - AbortTransitionID() Aborts a queued transition
+	/////////
+	t.Logf("Test 5 - AbortTransitionID() Aborts a queued transition")
+	later.Status = model.TransitionStatusQueued
+	(*GLOB.DSP).StoreTransition(later)
+	pb = AbortTransitionID(later.TransitionID)
+	ts.Assert().Equal(http.StatusAccepted, pb.StatusCode)
+	stored, _, err = (*GLOB.DSP).GetTransition(later.TransitionID)
+	ts.Require().NoError(err)
+	ts.Assert().Equal(model.TransitionStatusAborted, stored.Status)
+}
+
+func (ts *Transitions_TS) TestLocationSelectors() {
+	var (
+		t   *testing.T
+		err error
+	)
+	t = ts.T()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("group") == "compute" {
+			w.Write([]byte(`{"Components":[{"ID":"x3000c0s1b0n0"},{"ID":"x3000c0s2b0n0"}]}`))
+			return
+		}
+		w.Write([]byte(`{"Components":[]}`))
+	}))
+	defer srv.Close()
+	svcClient, _ := hms_certs.CreateRetryableHTTPClientPair("", 10, 10, 1)
+	var selectorHSM hsm.HSMProvider = &hsm.HSMv2{}
+	ts.Require().NoError(selectorHSM.Init(&hsm.HSM_GLOBALS{
+		SvcName:       "PCS-domain-selectors-test",
+		Logger:        logger.Log,
+		SMUrl:         srv.URL,
+		SVCHttpClient: svcClient,
+	}))
+	origHSM := GLOB.HSM
+	GLOB.HSM = &selectorHSM
+	defer func() { GLOB.HSM = origHSM }()
+
+	/////////
+	// Test 1 - resolveLocationSelectors() Replaces selectors with xnames
+	/////////
+	t.Logf("Test 1 - resolveLocationSelectors() Replaces selectors with xnames")
+	tr, err := model.ToTransition(model.TransitionParameter{
+		Operation: "On",
+		Location: []model.LocationParameter{
+			{Xname: "x3000c0s2b0n0", DeputyKey: "key"},
+			{Group: "compute"},
+		},
+	}, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	ts.Require().NoError(resolveLocationSelectors(&tr))
+	ts.Assert().Equal(model.LocationParameterSlice{
+		{Xname: "x3000c0s2b0n0", DeputyKey: "key"},
+		{Xname: "x3000c0s1b0n0"},
+	}, tr.Location)
+
+	/////////
+	// Test 2 - TriggerTransition() Rejects selectors that match nothing
+	/////////
+	t.Logf("Test 2 - TriggerTransition() Rejects selectors that match nothing")
+	tr, err = model.ToTransition(model.TransitionParameter{
+		Operation: "On",
+		Location:  []model.LocationParameter{{Partition: "p9"}},
+	}, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	err = resolveLocationSelectors(&tr)
+	ts.Assert().ErrorIs(err, errEmptySelection)
+	pb := TriggerTransition(tr)
+	ts.Assert().Equal(http.StatusBadRequest, pb.StatusCode)
+	ts.Assert().Contains(pb.Error.Detail, "partition=p9")
+
+	/////////
+	// Test 3 - resolveLocationSelectors() Expands xnames to descendants
+	/////////
+	t.Logf("Test 3 - resolveLocationSelectors() Expands xnames to descendants")
+	for _, xname := range []string{"x301c3", "x301c3s0b0n0", "x301c3s0b0n1", "x301c3r1", "x3010c3s0b0n0"} {
+		ts.Require().NoError((*GLOB.DSP).StorePowerStatus(model.PowerStatusComponent{XName: xname, LastUpdated: time.Now()}))
+	}
+	tr, err = model.ToTransition(model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x301", Expand: []string{"node", "RouterModule"}}},
+	}, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	ts.Require().NoError(resolveLocationSelectors(&tr))
+	ts.Assert().Equal(model.LocationParameterSlice{
+		{Xname: "x301c3r1"},
+		{Xname: "x301c3s0b0n0"},
+		{Xname: "x301c3s0b0n1"},
+	}, tr.Location)
+
+	tr, err = model.ToTransition(model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x301c3", Expand: []string{"CabinetPDUPowerConnector"}}},
+	}, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	err = resolveLocationSelectors(&tr)
+	ts.Assert().ErrorIs(err, errEmptySelection)
+
+	/////////
+	// Test 4 - startScheduledTransition() Aborts an occurrence whose selectors match nothing
+	/////////
+	t.Logf("Test 4 - startScheduledTransition() Aborts an occurrence whose selectors match nothing")
+	tr, err = model.ToTransition(model.TransitionParameter{
+		Operation:    "Off",
+		Location:     []model.LocationParameter{{Group: "empty"}},
+		CronSchedule: "0 3 * * *",
+	}, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	ts.Require().Equal(model.TransitionStatusScheduled, tr.Status)
+	due := time.Now().Add(-time.Minute)
+	tr.StartAt = &due
+	ts.Require().NoError((*GLOB.DSP).StoreTransition(tr))
+	defer deleteTransition(tr.TransitionID)
+
+	startScheduledTransition(tr)
+	got, _, err := (*GLOB.DSP).GetTransition(tr.TransitionID)
+	ts.Require().NoError(err)
+	ts.Assert().Equal(model.TransitionStatusAborted, got.Status)
+	ts.Assert().Contains(got.Error, "group=empty")
+	ts.Assert().Contains(got.Error, errEmptySelection.Error())
+
+	transitions, err := (*GLOB.DSP).GetAllTransitions()
+	ts.Require().NoError(err)
+	var next *model.Transition
+	for i, other := range transitions {
+		if other.TransitionID != tr.TransitionID && other.CronSchedule == tr.CronSchedule &&
+			len(other.Location) == 1 && other.Location[0].Group == "empty" {
+			next = &transitions[i]
+		}
+	}
+	ts.Require().NotNil(next, "next occurrence")
+	defer deleteTransition(next.TransitionID)
+	ts.Assert().Equal(model.TransitionStatusScheduled, next.Status)
+	ts.Assert().True(next.StartAt.After(time.Now()))
+	ts.Assert().Empty(next.Error)
+}
+
+func (ts *Transitions_TS) TestPollPowerStates() {
+	var t *testing.T = ts.T()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/redfish/v1/Systems/Node0":
+			w.Write([]byte(`{"PowerState":"On"}`))
+		case "/redfish/v1/Systems/Node1":
+			w.Write([]byte(`{"PowerState":"PoweringOff"}`))
+		case "/redfish/v1/Managers/BMC":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	srvURL, _ := url.Parse(srv.URL)
+
+	newComp := func(xname string, uri string) *TransitionComponent {
+		return &TransitionComponent{
+			HSMData: &hsm.HsmData{RfFQDN: srvURL.Host, PowerStatusURI: uri},
+			Task:    &model.TransitionTask{Xname: xname},
+		}
+	}
+
+	/////////
+	// Test 1 - pollPowerStates() Reads power states from BMCs
+	/////////
+	t.Logf("Test 1 - pollPowerStates() Reads power states from BMCs")
+	states := pollPowerStates([]*TransitionComponent{
+		newComp("x3002c0s0b0n0", "/redfish/v1/Systems/Node0"),
+		newComp("x3002c0s0b0n1", "/redfish/v1/Systems/Node1"),
+		newComp("x3002c0s0b0", "/redfish/v1/Managers/BMC"),
+		newComp("x3002c0s1b0n0", "/redfish/v1/Systems/Missing"),
+		newComp("x3002c0s2b0n0", ""),
+	})
+	ts.Assert().Equal(map[string]string{
+		"x3002c0s0b0n0": "on",
+		"x3002c0s0b0n1": "poweringoff",
+		"x3002c0s0b0":   "on",
+	}, states)
+}
+
+func (ts *Transitions_TS) TestConfirmPollSeconds() {
+	var t *testing.T = ts.T()
+	defer func() { GLOB.ConfirmPollSeconds = 0 }()
+
+	/////////
+	// Test 1 - ConfigureConfirmPolling() Rejects intervals under the minimum
+	/////////
+	t.Logf("Test 1 - ConfigureConfirmPolling() Rejects intervals under the minimum")
+	for _, seconds := range []int{-1, 1, model.MinConfirmPollSeconds - 1} {
+		ts.Assert().Error(ConfigureConfirmPolling(seconds), "seconds %d", seconds)
+	}
+	ts.Assert().NoError(ConfigureConfirmPolling(0))
+	ts.Assert().Equal(0, confirmPollSeconds(model.Transition{}))
+
+	/////////
+	// Test 2 - confirmPollSeconds() Uses the configured default
+	/////////
+	t.Logf("Test 2 - confirmPollSeconds() Uses the configured default")
+	ts.Require().NoError(ConfigureConfirmPolling(30))
+	ts.Assert().Equal(30, confirmPollSeconds(model.Transition{}))
+	ts.Assert().Equal(10, confirmPollSeconds(model.Transition{ConfirmPollSeconds: 10}))
+
+	/////////
+	// Test 3 - confirmPollSeconds() Holds stored transitions to the minimum
+	/////////
+	t.Logf("Test 3 - confirmPollSeconds() Holds stored transitions to the minimum")
+	ts.Assert().Equal(model.MinConfirmPollSeconds, confirmPollSeconds(model.Transition{ConfirmPollSeconds: 1}))
+}
+
+func (ts *Transitions_TS) TestRedfishEvents() {
+	var t *testing.T = ts.T()
+	var (
+		bmcLock       sync.Mutex
+		bmcRequests   int
+		subscriptions []redfishEventSubscription
+		deletes       int
+		smdUpdates    []hsm.BulkStateData
+		// The context of a subscription to PCS the BMC already has, if any.
+		existingContext string
+	)
+
+	bmc := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		bmcLock.Lock()
+		defer bmcLock.Unlock()
+		bmcRequests++
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == redfishSubscriptionsURI:
+			if existingContext == "" {
+				w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/EventService/Subscriptions/1"}]}`))
+			} else {
+				w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/EventService/Subscriptions/1"},` +
+					`{"@odata.id":"/redfish/v1/EventService/Subscriptions/2"}]}`))
+			}
+		case req.Method == http.MethodGet && req.URL.Path == redfishSubscriptionsURI+"/1":
+			w.Write([]byte(`{"Destination":"https://other.example.com/events"}`))
+		case req.Method == http.MethodGet && req.URL.Path == redfishSubscriptionsURI+"/2" && existingContext != "":
+			body, _ := json.Marshal(rf.EventDestination{Destination: "https://pcs.example.com/redfish-events", Context: existingContext})
+			w.Write(body)
+		case req.Method == http.MethodDelete && req.URL.Path == redfishSubscriptionsURI+"/2" && existingContext != "":
+			deletes++
+			existingContext = ""
+			w.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodPost && req.URL.Path == redfishSubscriptionsURI:
+			var sub redfishEventSubscription
+			json.NewDecoder(req.Body).Decode(&sub)
+			subscriptions = append(subscriptions, sub)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer bmc.Close()
+	bmcURL, _ := url.Parse(bmc.URL)
+	fqdn := bmcURL.Host
+
+	smd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var update hsm.BulkStateData
+		json.NewDecoder(req.Body).Decode(&update)
+		smdUpdates = append(smdUpdates, update)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer smd.Close()
+	svcClient, _ := hms_certs.CreateRetryableHTTPClientPair("", 10, 10, 1)
+	var eventsHSM hsm.HSMProvider = &hsm.HSMv2{}
+	ts.Require().NoError(eventsHSM.Init(&hsm.HSM_GLOBALS{
+		SvcName:       "PCS-domain-events-test",
+		Logger:        logger.Log,
+		SMUrl:         smd.URL,
+		SVCHttpClient: svcClient,
+	}))
+	origHSMHandle := hsmHandle
+	hsmHandle = &eventsHSM
+	origHWStateMap := hwStateMap
+	xname := "x3003c0s0b0n0"
+	hwStateMap = map[string]*componentPowerInfo{
+		xname: {
+			PSComp:  model.PowerStatusComponent{XName: xname, ManagementState: "available"},
+			HSMData: hsm.HsmData{RfFQDN: fqdn, PowerStatusURI: "/redfish/v1/Systems/Node0"},
+		},
+	}
+	ts.Require().NoError(ConfigureRedfishEvents("https://pcs.example.com/redfish-events"))
+	defer func() {
+		hsmHandle = origHSMHandle
+		hwStateMap = origHWStateMap
+		GLOB.RedfishEventsURL = ""
+		delete(redfishEventSubscribed, fqdn)
+	}()
+
+	/////////
+	// Test 1 - redfishEventPowerState() Reads power states from events
+	/////////
+	t.Logf("Test 1 - redfishEventPowerState() Reads power states from events")
+	for _, tc := range []struct {
+		record rf.EventRecord
+		state  model.PowerStateFilter
+	}{
+		{rf.EventRecord{MessageId: "ResourceEvent.1.0.ResourcePoweredOn"}, model.PowerStateFilter_On},
+		{rf.EventRecord{MessageId: "iLOEvents.2.1.ServerPoweredOff"}, model.PowerStateFilter_Off},
+		{rf.EventRecord{MessageId: "ResourceEvent.1.3.ResourcePowerStateChanged", MessageArgs: []string{"Node0", "Off"}}, model.PowerStateFilter_Off},
+		{rf.EventRecord{MessageId: "ResourceEvent.1.3.ResourcePowerStateChanged", MessageArgs: []string{"Node0", "PoweringOn"}}, model.PowerStateFilter_Undefined},
+		{rf.EventRecord{MessageId: "Base.1.0.ResourceCreated"}, model.PowerStateFilter_Undefined},
+	} {
+		ts.Assert().Equal(tc.state, redfishEventPowerState(tc.record), tc.record.MessageId)
+	}
+
+	/////////
+	// Test 2 - subscribeRedfishEvents() Subscribes BMCs without a subscription
+	/////////
+	t.Logf("Test 2 - subscribeRedfishEvents() Subscribes BMCs without a subscription")
+	subscribeRedfishEvents()
+	sub, err := (*GLOB.DSP).GetRedfishEventSubscription(fqdn)
+	ts.Require().NoError(err)
+	ts.Require().Len(subscriptions, 1)
+	ts.Assert().Equal(redfishEventSubscription{
+		Destination: "https://pcs.example.com/redfish-events",
+		Protocol:    "Redfish",
+		Context:     sub.Context(),
+		EventTypes:  redfishEventTypes,
+	}, subscriptions[0])
+	ts.Assert().Equal(3, bmcRequests)
+
+	// Subscribed BMCs aren't checked again until redfishEventRecheck passes.
+	subscribeRedfishEvents()
+	ts.Assert().Equal(3, bmcRequests)
+
+	/////////
+	// Test 3 - subscribeRedfishEvents() Keeps subscriptions with the stored context
+	/////////
+	t.Logf("Test 3 - subscribeRedfishEvents() Keeps subscriptions with the stored context")
+	delete(redfishEventSubscribed, fqdn)
+	existingContext = sub.Context()
+	subscribeRedfishEvents()
+	ts.Assert().Len(subscriptions, 1)
+	ts.Assert().Equal(0, deletes)
+
+	/////////
+	// Test 4 - subscribeRedfishEvents() Replaces subscriptions with another context
+	/////////
+	t.Logf("Test 4 - subscribeRedfishEvents() Replaces subscriptions with another context")
+	delete(redfishEventSubscribed, fqdn)
+	// As subscribed by an earlier version, with only the FQDN.
+	existingContext = fqdn
+	subscribeRedfishEvents()
+	ts.Assert().Equal(1, deletes)
+	ts.Require().Len(subscriptions, 2)
+	ts.Assert().Equal(sub.Context(), subscriptions[1].Context)
+
+	/////////
+	// Test 5 - IngestRedfishEvent() Rejects events without the stored context
+	/////////
+	t.Logf("Test 5 - IngestRedfishEvent() Rejects events without the stored context")
+	err = (*GLOB.DSP).StorePowerStatus(model.PowerStatusComponent{
+		XName:           xname,
+		PowerState:      "off",
+		ManagementState: "available",
+		LastUpdated:     time.Now(),
+	})
+	ts.Require().NoError(err)
+	defer (*GLOB.DSP).DeletePowerStatus(xname)
+	redfishEventXnamesLock.Lock()
+	redfishEventXnames = map[string]map[string]string{fqdn: {"/redfish/v1/Systems/Node0": xname}}
+	redfishEventXnamesTime = time.Now()
+	redfishEventXnamesLock.Unlock()
+
+	powerOn := rf.EventRecord{MessageId: "ResourceEvent.1.0.ResourcePoweredOn", OriginOfCondition: rf.ResourceID{Oid: "/redfish/v1/Systems/Node0"}}
+	for _, event := range []rf.Event{
+		{Events: []rf.EventRecord{powerOn}},
+		{Context: fqdn, Events: []rf.EventRecord{powerOn}},
+		{Context: fqdn + "/" + strings.Repeat("0", 64), Events: []rf.EventRecord{powerOn}},
+		{Context: "x9999c0s0b0/" + sub.Secret, Events: []rf.EventRecord{powerOn}},
+	} {
+		pb := IngestRedfishEvent(event)
+		ts.Assert().Equal(http.StatusUnauthorized, pb.StatusCode, event.Context)
+	}
+	// Every record's context must match when the event has none.
+	forged := powerOn
+	forged.Context = fqdn
+	matched := powerOn
+	matched.Context = sub.Context()
+	pb := IngestRedfishEvent(rf.Event{Events: []rf.EventRecord{matched, forged}})
+	ts.Assert().Equal(http.StatusUnauthorized, pb.StatusCode)
+	psc, err := (*GLOB.DSP).GetPowerStatus(xname)
+	ts.Require().NoError(err)
+	ts.Assert().Equal("off", psc.PowerState)
+	ts.Assert().Empty(smdUpdates)
+
+	/////////
+	// Test 6 - IngestRedfishEvent() Stores power states from events
+	/////////
+	t.Logf("Test 6 - IngestRedfishEvent() Stores power states from events")
+	pb = IngestRedfishEvent(rf.Event{
+		Context: sub.Context(),
+		Events: []rf.EventRecord{
+			{MessageId: "ResourceEvent.1.0.ResourcePoweredOn", OriginOfCondition: rf.ResourceID{Oid: "/redfish/v1/Systems/Node0/"}},
+			{MessageId: "ResourceEvent.1.0.ResourcePoweredOn", OriginOfCondition: rf.ResourceID{Oid: "/redfish/v1/Systems/Unknown"}},
+		},
+	})
+	ts.Require().False(pb.IsError, pb.Error)
+	ts.Assert().Equal(http.StatusNoContent, pb.StatusCode)
+	psc, err = (*GLOB.DSP).GetPowerStatus(xname)
+	ts.Require().NoError(err)
+	ts.Assert().Equal("on", psc.PowerState)
+	ts.Assert().Equal([]hsm.BulkStateData{{ComponentIDs: []string{xname}, State: "on"}}, smdUpdates)
+
+	/////////
+	// Test 7 - syncHWStateMapFromStore() Copies newer stored states
+	/////////
+	t.Logf("Test 7 - syncHWStateMapFromStore() Copies newer stored states")
+	syncHWStateMapFromStore()
+	ts.Assert().Equal("on", hwStateMap[xname].PSComp.PowerState)
+
+	/////////
+	// Test 8 - IngestRedfishEvent() Rejects events when disabled
+	/////////
+	t.Logf("Test 8 - IngestRedfishEvent() Rejects events when disabled")
+	GLOB.RedfishEventsURL = ""
+	pb = IngestRedfishEvent(rf.Event{})
+	ts.Assert().Equal(http.StatusNotFound, pb.StatusCode)
+}
+
+func (ts *Transitions_TS) TestTaskDeadlines() {
+	var t *testing.T = ts.T()
+
+	defer func(deadlines model.TaskDeadlineSlice) { GLOB.TaskDeadlines = deadlines }(GLOB.TaskDeadlines)
+	ts.Require().NoError(ConfigureTaskDeadlines([]string{"gracefulshutdown:Node=10", ":Chassis=1", "waitForBMC=8"}))
+	ts.Require().Error(ConfigureTaskDeadlines([]string{"sometime=10"}))
+
+	/////////
+	// Test 1 - taskDeadline() Picks the most specific deadline
+	/////////
+	t.Logf("Test 1 - taskDeadline() Picks the most specific deadline")
+	tr := model.Transition{
+		TaskDeadline:  3,
+		TaskDeadlines: model.TaskDeadlineSlice{{Action: "gracefulshutdown", Minutes: 20}},
+	}
+	for _, tc := range []struct {
+		action   string
+		compType xnametypes.HMSType
+		deadline time.Duration
+	}{
+		// The transition's own deadlines come before the global ones.
+		{"gracefulshutdown", xnametypes.Node, 20 * time.Minute},
+		{"forceoff", xnametypes.Chassis, time.Minute},
+		{"forceoff", xnametypes.Node, 3 * time.Minute},
+		{model.TaskDeadlineWaitForBMC, xnametypes.NodeBMC, 8 * time.Minute},
+	} {
+		deadline, ok := taskDeadline(tr, tc.action, tc.compType)
+		ts.Assert().True(ok)
+		ts.Assert().Equal(tc.deadline, deadline, "%s %s", tc.action, tc.compType)
+	}
+
+	GLOB.TaskDeadlines = nil
+	deadline, ok := taskDeadline(tr, model.TaskDeadlineWaitForBMC, xnametypes.NodeBMC)
+	ts.Assert().True(ok)
+	ts.Assert().Equal(time.Duration(model.DefaultWaitForBMCDeadline)*time.Minute, deadline)
+	tr.TaskDeadline = -1
+	_, ok = taskDeadline(tr, "forceoff", xnametypes.Node)
+	ts.Assert().False(ok)
+
+	/////////
+	// Test 2 - waitForBMC() Returns once BMCs are available
+	/////////
+	t.Logf("Test 2 - waitForBMC() Returns once BMCs are available")
+	defer func(interval time.Duration) { waitForBMCInterval = interval }(waitForBMCInterval)
+	waitForBMCInterval = 100 * time.Millisecond
+	psc := model.PowerStatusComponent{
+		XName:           "x3004c0s0b0",
+		PowerState:      "on",
+		ManagementState: "unavailable",
+		LastUpdated:     time.Now(),
+	}
+	ts.Require().NoError((*GLOB.DSP).StorePowerStatus(psc))
+	defer (*GLOB.DSP).DeletePowerStatus(psc.XName)
+	task := model.NewTransitionTask(uuid.New(), model.Operation_On)
+	task.Xname = psc.XName
+	comp := &TransitionComponent{Task: &task}
+	done := make(chan bool)
+	go func() {
+		waitForBMC(model.Transition{TaskDeadline: 5}, []*TransitionComponent{comp})
+		close(done)
+	}()
+	select {
+	case <-done:
+		ts.Fail("waitForBMC() returned while the BMC was unavailable")
+	case <-time.After(300 * time.Millisecond):
+	}
+	psc.ManagementState = "available"
+	ts.Require().NoError((*GLOB.DSP).StorePowerStatus(psc))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		ts.Fail("waitForBMC() kept waiting after the BMC was available")
+	}
+	ts.Assert().Equal("Waiting for controller to be ready", comp.Task.StatusDesc)
+}
+
+func (ts *Transitions_TS) TestTaskTimeline() {
+	var t *testing.T = ts.T()
+
+	testParams := model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x3005c0s0b0n0"}},
+	}
+	testTransition, _ := model.ToTransition(testParams, GLOB.ExpireTimeMins)
+	testTransition.Status = model.TransitionStatusInProgress
+	task := model.NewTransitionTask(testTransition.TransitionID, testTransition.Operation)
+	task.Xname = "x3005c0s0b0n0"
+	testTransition.TaskIDs = []uuid.UUID{task.TaskID}
+	ts.Require().NoError((*GLOB.DSP).StoreTransition(testTransition))
+	defer (*GLOB.DSP).DeleteTransition(testTransition.TransitionID)
+
+	/////////
+	// Test 1 - storeTransitionTask() Records each change
+	/////////
+	t.Logf("Test 1 - storeTransitionTask() Records each change")
+	task.StatusDesc = "Gathering data"
+	ts.Require().NoError(storeTransitionTask(&task))
+	task.State = model.TaskState_Sending
+	task.Operation = model.Operation_Off
+	task.Status = model.TransitionTaskStatusInProgress
+	task.StatusDesc = "Applying transition, gracefulshutdown"
+	ts.Require().NoError(storeTransitionTask(&task))
+	// Unchanged
+	ts.Require().NoError(storeTransitionTask(&task))
+	task.StatusDesc = "Timeout waiting for transition, gracefulshutdown. Falling back to forceoff"
+	ts.Require().NoError(storeTransitionTask(&task))
+	task.Operation = model.Operation_ForceOff
+	task.StatusDesc = "Applying transition, forceoff"
+	ts.Require().NoError(storeTransitionTask(&task))
+	task.State = model.TaskState_Confirmed
+	task.Status = model.TransitionTaskStatusSucceeded
+	task.StatusDesc = "Transition confirmed, forceoff"
+	ts.Require().NoError(storeTransitionTask(&task))
+
+	stored, err := (*GLOB.DSP).GetTransitionTask(testTransition.TransitionID, task.TaskID)
+	ts.Require().NoError(err)
+	ts.Require().Len(stored.Timeline, 5)
+	ts.Assert().Equal("", stored.Timeline[0].Action)
+	ts.Assert().Equal("gracefulshutdown", stored.Timeline[1].Action)
+	ts.Assert().Equal("forceoff", stored.Timeline[3].Action)
+	ts.Assert().Equal(model.TransitionTaskStatusSucceeded, stored.Timeline[4].TaskStatus)
+
+	/////////
+	// Test 2 - GetTransition() Only includes timelines when asked
+	/////////
+	t.Logf("Test 2 - GetTransition() Only includes timelines when asked")
+	pb := GetTransition(testTransition.TransitionID, false)
+	ts.Require().False(pb.IsError)
+	ts.Require().Len(pb.Obj.(model.TransitionResp).Tasks, 1)
+	ts.Assert().Empty(pb.Obj.(model.TransitionResp).Tasks[0].Timeline)
+	ts.Assert().Empty(pb.Obj.(model.TransitionResp).Tasks[0].TierDurations)
+
+	pb = GetTransition(testTransition.TransitionID, true)
+	ts.Require().False(pb.IsError)
+	taskRsp := pb.Obj.(model.TransitionResp).Tasks[0]
+	ts.Assert().Len(taskRsp.Timeline, 5)
+	ts.Require().Len(taskRsp.TierDurations, 2)
+	// Tiers 0 and 1 of PowerSequenceFull are gracefulshutdown and forceoff on Nodes.
+	ts.Assert().Equal(0, taskRsp.TierDurations[0].Tier)
+	ts.Assert().Equal("gracefulshutdown", taskRsp.TierDurations[0].Action)
+	ts.Assert().Equal(1, taskRsp.TierDurations[1].Tier)
+	ts.Assert().Equal("forceoff", taskRsp.TierDurations[1].Action)
+
+	/////////
+	// Test 3 - GetTransition() Timelines survive compression
+	/////////
+	t.Logf("Test 3 - GetTransition() Timelines survive compression")
+	compressAndCompleteTransition(testTransition, model.TransitionStatusCompleted)
+	pb = GetTransition(testTransition.TransitionID, true)
+	ts.Require().False(pb.IsError)
+	ts.Require().Len(pb.Obj.(model.TransitionResp).Tasks, 1)
+	taskRsp = pb.Obj.(model.TransitionResp).Tasks[0]
+	ts.Assert().Len(taskRsp.Timeline, 5)
+	ts.Assert().Len(taskRsp.TierDurations, 2)
+	pb = GetTransition(testTransition.TransitionID, false)
+	ts.Require().False(pb.IsError)
+	ts.Assert().Empty(pb.Obj.(model.TransitionResp).Tasks[0].Timeline)
+}
+
+func (ts *Transitions_TS) TestDesiredPowerStates() {
+	var t *testing.T = ts.T()
+
+	/////////
+	// Test 1 - StoreDesiredPowerState() Stores, lists, and deletes
+	/////////
+	t.Logf("Test 1 - StoreDesiredPowerState() Stores, lists, and deletes")
+	pb := StoreDesiredPowerState("x3006c0s0b0n9", "soft-off")
+	ts.Assert().Equal(http.StatusBadRequest, pb.StatusCode)
+	pb = StoreDesiredPowerState("X3006C0S0B0N9", "On")
+	ts.Require().False(pb.IsError)
+	ts.Assert().Equal("x3006c0s0b0n9", pb.Obj.(model.DesiredPowerState).Target)
+	pb = GetDesiredPowerState("X3006C0S0B0N9")
+	ts.Require().False(pb.IsError)
+	ts.Assert().Equal(model.DesiredPowerStateOn, pb.Obj.(model.DesiredPowerState).PowerState)
+	pb = GetDesiredPowerStates()
+	ts.Require().False(pb.IsError)
+	ts.Assert().Len(pb.Obj.(model.DesiredPowerStateArray).DesiredPowerStates, 1)
+	pb = DeleteDesiredPowerState("x3006c0s0b0n9")
+	ts.Assert().Equal(http.StatusNoContent, pb.StatusCode)
+	pb = DeleteDesiredPowerState("x3006c0s0b0n9")
+	ts.Assert().Equal(http.StatusNotFound, pb.StatusCode)
+
+	/////////
+	// Test 2 - reconcileDesiredPowerStates() Leaves alone what it should
+	/////////
+	t.Logf("Test 2 - reconcileDesiredPowerStates() Leaves alone what it should")
+	defer func(rate int, backoff time.Duration) {
+		GLOB.DesiredStateRate = rate
+		GLOB.DesiredStateBackoff = backoff
+		desiredStateAttempts = make(map[string]desiredStateAttempt)
+		desiredStateStarts = nil
+	}(GLOB.DesiredStateRate, GLOB.DesiredStateBackoff)
+	ts.Require().Error(ConfigureDesiredPowerStates(0, 60))
+	ts.Require().NoError(ConfigureDesiredPowerStates(2, 60))
+
+	comps := map[string]model.PowerStatusComponent{
+		// Already on
+		"x3006c0s0b0n0": {PowerState: "on", ManagementState: "available"},
+		// Held by another transition
+		"x3006c0s0b0n1": {PowerState: "off", ManagementState: "available"},
+		// Backing off
+		"x3006c0s0b0n2": {PowerState: "off", ManagementState: "available"},
+		// BMC unavailable
+		"x3006c0s0b0n3": {PowerState: "off", ManagementState: "unavailable"},
+		// Power state unknown
+		"x3006c0s0b0n4": {PowerState: "undefined", ManagementState: "available"},
+		// Drifted, but over the rate limit
+		"x3006c0s0b0n5": {PowerState: "off", ManagementState: "available"},
+	}
+	for xname, psc := range comps {
+		psc.XName = xname
+		psc.LastUpdated = time.Now()
+		ts.Require().NoError((*GLOB.DSP).StorePowerStatus(psc))
+		defer (*GLOB.DSP).DeletePowerStatus(xname)
+		if xname != "x3006c0s0b0n5" {
+			ts.Require().False(StoreDesiredPowerState(xname, "on").IsError)
+			defer (*GLOB.DSP).DeleteDesiredPowerState(xname)
+		}
+	}
+	active, _ := model.ToTransition(model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x3006c0s0b0n1"}},
+	}, GLOB.ExpireTimeMins)
+	active.Status = model.TransitionStatusInProgress
+	ts.Require().NoError((*GLOB.DSP).StoreTransition(active))
+	defer (*GLOB.DSP).DeleteTransition(active.TransitionID)
+
+	desiredStateAttempts = map[string]desiredStateAttempt{
+		"x3006c0s0b0n0": {count: 1, next: time.Now().Add(-time.Minute)},
+		"x3006c0s0b0n2": {count: 1, next: time.Now().Add(time.Minute)},
+		// No longer has a desired power state
+		"x3006c0s0b0n8": {count: 3, next: time.Now()},
+	}
+	desiredStateStarts = []time.Time{time.Now().Add(-2 * time.Minute), time.Now()}
+	reconcileDesiredPowerStates()
+
+	// Only the attempt backing off is kept, and old starts are forgotten.
+	ts.Assert().Len(desiredStateAttempts, 1)
+	ts.Assert().Contains(desiredStateAttempts, "x3006c0s0b0n2")
+	ts.Assert().Len(desiredStateStarts, 1)
+
+	ts.Require().False(StoreDesiredPowerState("x3006c0s0b0n5", "on").IsError)
+	defer (*GLOB.DSP).DeleteDesiredPowerState("x3006c0s0b0n5")
+	desiredStateStarts = append(desiredStateStarts, time.Now())
+	reconcileDesiredPowerStates()
+	ts.Assert().NotContains(desiredStateAttempts, "x3006c0s0b0n5")
+
+	selector, _ := model.ParseLabelSelector(model.DesiredPowerStateLabel)
+	started, _, err := (*GLOB.DSP).GetTransitions(model.TransitionFilter{Labels: []model.LabelSelector{selector}})
+	ts.Require().NoError(err)
+	ts.Assert().Empty(started)
+}
+
+func (ts *Transitions_TS) TestPowerOnStagger() {
+	var t *testing.T = ts.T()
+
+	defer func(staggers model.PowerOnStaggerSlice) { GLOB.PowerOnStaggers = staggers }(GLOB.PowerOnStaggers)
+	ts.Require().NoError(ConfigurePowerOnStaggers([]string{"Node=2/10"}))
+	ts.Require().Error(ConfigurePowerOnStaggers([]string{"Node=2"}))
+	ts.Require().Error(ConfigurePowerOnStaggers([]string{"Node=2/10", "node:pdu=1/5"}))
+
+	transitionID := uuid.New()
+	newComp := func(xname string, poweredBy ...string) *TransitionComponent {
+		task := model.NewTransitionTask(transitionID, model.Operation_On)
+		task.Xname = xname
+		return &TransitionComponent{Task: &task, HSMData: &hsm.HsmData{PoweredBy: poweredBy}}
+	}
+	waveXnames := func(waves []staggerWave) map[int][]string {
+		xnames := make(map[int][]string)
+		for _, wave := range waves {
+			offset := int(wave.offset / time.Second)
+			for _, comp := range wave.comps {
+				xnames[offset] = append(xnames[offset], comp.Task.Xname)
+			}
+		}
+		return xnames
+	}
+
+	var compList []*TransitionComponent
+	for i := 0; i < 5; i++ {
+		compList = append(compList, newComp(fmt.Sprintf("x1000c0s%db0n0", i), "x1000m0p0v"+fmt.Sprint(i)))
+	}
+	compList = append(compList, newComp("x1001c0s0b0n0", "x1000m0p1v0"))
+	compList = append(compList, newComp("x1000c0"))
+
+	/////////
+	// Test 1 - staggerComponents() Global stagger by cabinet
+	/////////
+	t.Logf("Test 1 - staggerComponents() Global stagger by cabinet")
+	waves := staggerComponents(model.Transition{}, "on", compList)
+	ts.Assert().Equal(map[int][]string{
+		0:  {"x1000c0s0b0n0", "x1000c0s1b0n0", "x1001c0s0b0n0", "x1000c0"},
+		10: {"x1000c0s2b0n0", "x1000c0s3b0n0"},
+		20: {"x1000c0s4b0n0"},
+	}, waveXnames(waves))
+
+	/////////
+	// Test 2 - staggerComponents() Transition stagger by PDU takes precedence
+	/////////
+	t.Logf("Test 2 - staggerComponents() Transition stagger by PDU takes precedence")
+	compList[6] = newComp("x1000c0", "x1000m0p1v1")
+	tr := model.Transition{PowerOnStagger: model.PowerOnStaggerSlice{
+		{GroupBy: model.PowerOnStaggerByPDU, Count: 3, DelaySeconds: 5},
+	}}
+	waves = staggerComponents(tr, "on", compList)
+	ts.Assert().Equal(map[int][]string{
+		0: {"x1000c0s0b0n0", "x1000c0s1b0n0", "x1000c0s2b0n0", "x1001c0s0b0n0", "x1000c0"},
+		5: {"x1000c0s3b0n0", "x1000c0s4b0n0"},
+	}, waveXnames(waves))
+
+	/////////
+	// Test 3 - staggerComponents() No delay turns staggering off
+	/////////
+	t.Logf("Test 3 - staggerComponents() No delay turns staggering off")
+	tr = model.Transition{PowerOnStagger: model.PowerOnStaggerSlice{{ComponentType: "Node", Count: 1}}}
+	waves = staggerComponents(tr, "on", compList)
+	ts.Assert().Len(waves, 1)
+	ts.Assert().Len(waves[0].comps, len(compList))
+
+	/////////
+	// Test 4 - staggerComponents() Only power on is staggered
+	/////////
+	t.Logf("Test 4 - staggerComponents() Only power on is staggered")
+	waves = staggerComponents(model.Transition{}, "forceoff", compList)
+	ts.Assert().Len(waves, 1)
+	ts.Assert().Len(waves[0].comps, len(compList))
+}
+
+func (ts *Transitions_TS) TestPowerBudget() {
+	var t *testing.T = ts.T()
+
+	defer func(budgets map[string]int) { GLOB.PowerBudgets = budgets }(GLOB.PowerBudgets)
+	ts.Require().Error(ConfigurePowerBudgets([]string{"x3007=2000", "X3007=3000"}))
+	ts.Require().Error(ConfigurePowerBudgets([]string{"x3007c0=2000"}))
+	ts.Require().NoError(ConfigurePowerBudgets([]string{"x3007=2000", "system=100000"}))
+
+	powerup := 900
+	for i := 0; i < 4; i++ {
+		recordPowerCapabilities(fmt.Sprintf("x3007c0s0b0n%d", i), model.PowerCapabilities{PowerupPower: &powerup})
+	}
+	// Nothing to estimate from, so not stored.
+	recordPowerCapabilities("x3007c0s0b0n4", model.PowerCapabilities{})
+	capsList, err := (*GLOB.DSP).GetAllPowerCapabilities()
+	ts.Require().NoError(err)
+	for _, caps := range capsList {
+		ts.Assert().NotEqual("x3007c0s0b0n4", caps.Xname)
+	}
+
+	for i := 0; i < 5; i++ {
+		psc := model.PowerStatusComponent{
+			XName:           fmt.Sprintf("x3007c0s0b0n%d", i),
+			PowerState:      "off",
+			ManagementState: "available",
+			LastUpdated:     time.Now(),
+		}
+		if i == 0 {
+			psc.PowerState = "on"
+		}
+		ts.Require().NoError((*GLOB.DSP).StorePowerStatus(psc))
+		defer (*GLOB.DSP).DeletePowerStatus(psc.XName)
+	}
+	active, _ := model.ToTransition(model.TransitionParameter{
+		Operation: "On",
+		Location:  []model.LocationParameter{{Xname: "x3007c0s0b0n1"}},
+	}, GLOB.ExpireTimeMins)
+	active.Status = model.TransitionStatusInProgress
+	ts.Require().NoError((*GLOB.DSP).StoreTransition(active))
+	defer (*GLOB.DSP).DeleteTransition(active.TransitionID)
+
+	newTransition := func(operation string, policy string, xnames ...string) model.Transition {
+		params := model.TransitionParameter{Operation: operation, BudgetPolicy: policy}
+		for _, xname := range xnames {
+			params.Location = append(params.Location, model.LocationParameter{Xname: xname})
+		}
+		tr, err := model.ToTransition(params, GLOB.ExpireTimeMins)
+		ts.Require().NoError(err)
+		return tr
+	}
+
+	/////////
+	// Test 1 - checkPowerBudget() Counts components that are on or being powered on
+	/////////
+	t.Logf("Test 1 - checkPowerBudget() Counts components that are on or being powered on")
+	tr := newTransition("On", "", "x3007c0s0b0n3", "x3007c0s0b0n2", "x3007c0s0b0n4")
+	check, err := checkPowerBudget(tr)
+	ts.Require().NoError(err)
+	ts.Assert().Equal([]string{"x3007c0s0b0n2", "x3007c0s0b0n3"}, check.over)
+	ts.Assert().Contains(check.exceeded, "x3007 budget of 2000 W with 1800 W in use")
+	// Its draw isn't known.
+	ts.Assert().Equal([]string{"x3007c0s0b0n4"}, check.unchecked)
+
+	check, err = checkPowerBudget(newTransition("Off", "", "x3007c0s0b0n2"))
+	ts.Require().NoError(err)
+	ts.Assert().Empty(check.over)
+
+	/////////
+	// Test 2 - admitPowerBudget() Defers components under the partial policy
+	/////////
+	t.Logf("Test 2 - admitPowerBudget() Defers components under the partial policy")
+	ts.Require().NoError(ConfigurePowerBudgets([]string{"x3007=2700"}))
+	tr = newTransition("On", model.PowerBudgetPolicyPartial, "x3007c0s0b0n2", "x3007c0s0b0n3", "x3007c0s0b0n4")
+	exceeded, err := admitPowerBudget(&tr)
+	ts.Require().NoError(err)
+	ts.Assert().Empty(exceeded)
+	ts.Assert().Equal(model.XnameSlice{"x3007c0s0b0n3"}, tr.BudgetDeferred)
+	ts.Assert().Equal(model.XnameSlice{"x3007c0s0b0n4"}, tr.BudgetUnchecked)
+
+	// Nothing would be left to start.
+	ts.Require().NoError(ConfigurePowerBudgets([]string{"x3007=2000"}))
+	tr = newTransition("On", model.PowerBudgetPolicyPartial, "x3007c0s0b0n2")
+	exceeded, err = admitPowerBudget(&tr)
+	ts.Require().NoError(err)
+	ts.Assert().NotEmpty(exceeded)
+	ts.Assert().Empty(tr.BudgetDeferred)
+
+	/////////
+	// Test 3 - TriggerTransition() Rejects or queues transitions over budget
+	/////////
+	t.Logf("Test 3 - TriggerTransition() Rejects or queues transitions over budget")
+	pb := TriggerTransition(newTransition("On", "", "x3007c0s0b0n2"))
+	ts.Require().True(pb.IsError)
+	ts.Assert().Equal(http.StatusConflict, pb.StatusCode)
+	ts.Assert().Contains(pb.Error.Detail, "power budget")
+
+	pb = TriggerTransition(newTransition("On", model.PowerBudgetPolicyQueue, "x3007c0s0b0n2"))
+	ts.Require().False(pb.IsError)
+	queued := pb.Obj.(model.TransitionCreation)
+	defer (*GLOB.DSP).DeleteTransition(queued.TransitionID)
+	ts.Assert().Equal(model.TransitionStatusQueued, queued.TransitionStatus)
+
+	/////////
+	// Test 4 - failBudgetDeferredComps() Fails deferred components
+	/////////
+	t.Logf("Test 4 - failBudgetDeferredComps() Fails deferred components")
+	xnameMap := make(map[string]*TransitionComponent)
+	for _, xname := range []string{"x3007c0s0b0n2", "x3007c0s0b0n3"} {
+		task := model.NewTransitionTask(tr.TransitionID, model.Operation_On)
+		task.Xname = xname
+		xnameMap[xname] = &TransitionComponent{Task: &task}
+	}
+	tr.BudgetDeferred = model.XnameSlice{"x3007c0s0b0n3"}
+	failBudgetDeferredComps(tr, xnameMap)
+	defer (*GLOB.DSP).DeleteTransitionTask(tr.TransitionID, xnameMap["x3007c0s0b0n3"].Task.TaskID)
+	ts.Assert().Equal(model.TransitionTaskStatusNew, xnameMap["x3007c0s0b0n2"].Task.Status)
+	ts.Assert().Equal(model.TransitionTaskStatusFailed, xnameMap["x3007c0s0b0n3"].Task.Status)
+	ts.Assert().Contains(xnameMap["x3007c0s0b0n3"].Task.Error, "power budget")
+
+	/////////
+	// Test 5 - admitPowerBudget() Lists nodes without a known draw
+	/////////
+	t.Logf("Test 5 - admitPowerBudget() Lists nodes without a known draw")
+	tr = newTransition("On", "", "x3007c0s0b0n4", "x3007c0")
+	exceeded, err = admitPowerBudget(&tr)
+	ts.Require().NoError(err)
+	ts.Assert().Empty(exceeded)
+	// Only nodes have their power capabilities read.
+	ts.Assert().Equal(model.XnameSlice{"x3007c0s0b0n4"}, tr.BudgetUnchecked)
+
+	// Once it is being powered on, it is uncounted rather than unchecked.
+	// Other tests may leave nodes without a known draw on.
+	before, err := checkPowerBudget(newTransition("On", "", "x3007c0s0b0n4"))
+	ts.Require().NoError(err)
+	tr.Status = model.TransitionStatusInProgress
+	ts.Require().NoError((*GLOB.DSP).StoreTransition(tr))
+	defer (*GLOB.DSP).DeleteTransition(tr.TransitionID)
+	check, err = checkPowerBudget(newTransition("On", "", "x3007c0s0b0n4"))
+	ts.Require().NoError(err)
+	ts.Assert().Empty(check.unchecked)
+	ts.Assert().Equal(before.uncounted+1, check.uncounted)
+}
+
+func (ts *Transitions_TS) TestFreeze() {
+	var t *testing.T = ts.T()
+
+	freeze, err := model.ToFreeze(model.FreezeParameter{
+		Xnames:   []string{"x3008c0s0b0n0"},
+		Prefixes: []string{"x3008c0s1"},
+		EndTime:  time.Now().Add(time.Hour),
+		Reason:   "Firmware update",
+	})
+	ts.Require().NoError(err)
+	ts.Require().NoError((*GLOB.DSP).StoreFreeze(freeze))
+	defer (*GLOB.DSP).DeleteFreeze(freeze.FreezeID)
+
+	start := time.Now().Add(time.Hour)
+	later, err := model.ToFreeze(model.FreezeParameter{
+		Xnames:    []string{"x3008c0s2b0n0"},
+		StartTime: &start,
+		EndTime:   start.Add(time.Hour),
+		Reason:    "Not yet",
+	})
+	ts.Require().NoError(err)
+	ts.Require().NoError((*GLOB.DSP).StoreFreeze(later))
+	defer (*GLOB.DSP).DeleteFreeze(later.FreezeID)
+
+	newTransition := func(xnames ...string) model.Transition {
+		params := model.TransitionParameter{Operation: "Off"}
+		for _, xname := range xnames {
+			params.Location = append(params.Location, model.LocationParameter{Xname: xname})
+		}
+		tr, err := model.ToTransition(params, GLOB.ExpireTimeMins)
+		ts.Require().NoError(err)
+		return tr
+	}
+
+	/////////
+	// Test 1 - checkTransitionFreezes() Finds frozen components and their ancestors
+	/////////
+	t.Logf("Test 1 - checkTransitionFreezes() Finds frozen components and their ancestors")
+	for _, xname := range []string{"x3008c0s0b0n0", "x3008c0s1b0n1", "x3008c0"} {
+		frozen, err := checkTransitionFreezes(newTransition(xname))
+		ts.Require().NoError(err)
+		ts.Assert().Contains(frozen, "Firmware update", xname)
+	}
+	for _, xname := range []string{"x3008c0s0b0n1", "x3008c0s2b0n0"} {
+		frozen, err := checkTransitionFreezes(newTransition(xname))
+		ts.Require().NoError(err)
+		ts.Assert().Empty(frozen, xname)
+	}
+	tr := newTransition("x3008c0s0b0n0")
+	tr.FreezeOverride = true
+	frozen, err := checkTransitionFreezes(tr)
+	ts.Require().NoError(err)
+	ts.Assert().Empty(frozen)
+
+	/////////
+	// Test 2 - TriggerTransition() and startPowerCapPatch() Reject frozen components
+	/////////
+	t.Logf("Test 2 - TriggerTransition() and startPowerCapPatch() Reject frozen components")
+	pb := TriggerTransition(newTransition("x3008c0s0b0n0"))
+	ts.Require().True(pb.IsError)
+	ts.Assert().Equal(http.StatusConflict, pb.StatusCode)
+	ts.Assert().Contains(pb.Error.Detail, "frozen")
+
+	patch := model.PowerCapPatchParameter{
+		Components: []model.PowerCapComponentParameter{{Xname: "x3008c0s1b0n0"}},
+	}
+	pb = startPowerCapPatch(patch)
+	ts.Require().True(pb.IsError)
+	ts.Assert().Equal(http.StatusConflict, pb.StatusCode)
+
+	// Capping a chassis doesn't affect the frozen nodes in it.
+	frozen, err = checkPowerCapFreezes(model.PowerCapPatchParameter{
+		Components: []model.PowerCapComponentParameter{{Xname: "x3008c0"}},
+	})
+	ts.Require().NoError(err)
+	ts.Assert().Empty(frozen)
+	patch.Requester.FreezeOverride = true
+	frozen, err = checkPowerCapFreezes(patch)
+	ts.Require().NoError(err)
+	ts.Assert().Empty(frozen)
+
+	/////////
+	// Test 3 - failFrozenComps() Blocks the tasks of frozen components
+	/////////
+	t.Logf("Test 3 - failFrozenComps() Blocks the tasks of frozen components")
+	tr = newTransition("x3008c0s0b0n0", "x3008c0s0b0n1")
+	newXnameMap := func() map[string]*TransitionComponent {
+		xnameMap := make(map[string]*TransitionComponent)
+		for _, loc := range tr.Location {
+			task := model.NewTransitionTask(tr.TransitionID, model.Operation_Off)
+			task.Xname = loc.Xname
+			xnameMap[loc.Xname] = &TransitionComponent{Task: &task}
+		}
+		return xnameMap
+	}
+	xnameMap := newXnameMap()
+	failFrozenComps(tr, xnameMap)
+	defer (*GLOB.DSP).DeleteTransitionTask(tr.TransitionID, xnameMap["x3008c0s0b0n0"].Task.TaskID)
+	ts.Assert().Equal(model.TransitionTaskStatusFailed, xnameMap["x3008c0s0b0n0"].Task.Status)
+	ts.Assert().Equal("Blocked by freeze", xnameMap["x3008c0s0b0n0"].Task.StatusDesc)
+	ts.Assert().Contains(xnameMap["x3008c0s0b0n0"].Task.Error, freeze.FreezeID.String())
+	ts.Assert().Equal(model.TransitionTaskStatusNew, xnameMap["x3008c0s0b0n1"].Task.Status)
+
+	tr.FreezeOverride = true
+	xnameMap = newXnameMap()
+	failFrozenComps(tr, xnameMap)
+	ts.Assert().Equal(model.TransitionTaskStatusNew, xnameMap["x3008c0s0b0n0"].Task.Status)
+
+	/////////
+	// Test 4 - freezeReaper() Removes freezes that ended long ago
+	/////////
+	t.Logf("Test 4 - freezeReaper() Removes freezes that ended long ago")
+	ended := model.Freeze{
+		FreezeID:  uuid.New(),
+		Xnames:    model.XnameSlice{"x3008c0s3b0n0"},
+		StartTime: time.Now().Add(-time.Duration(GLOB.ExpireTimeMins+120) * time.Minute),
+		EndTime:   time.Now().Add(-time.Duration(GLOB.ExpireTimeMins+60) * time.Minute),
+		Reason:    "Over",
+	}
+	ts.Require().NoError((*GLOB.DSP).StoreFreeze(ended))
+	freezeReaper()
+	_, err = (*GLOB.DSP).GetFreeze(ended.FreezeID)
+	ts.Assert().ErrorContains(err, "does not exist")
+	_, err = (*GLOB.DSP).GetFreeze(freeze.FreezeID)
+	ts.Assert().NoError(err)
+
+	/////////
+	// Test 5 - DeleteFreeze() Needs a requester who may override freezes
+	/////////
+	t.Logf("Test 5 - DeleteFreeze() Needs a requester who may override freezes")
+	pb = DeleteFreeze(later.FreezeID, model.Requester{Subject: "operator"})
+	ts.Require().True(pb.IsError)
+	ts.Assert().Equal(http.StatusForbidden, pb.StatusCode)
+	_, err = (*GLOB.DSP).GetFreeze(later.FreezeID)
+	ts.Assert().NoError(err)
+
+	pb = DeleteFreeze(later.FreezeID, model.Requester{Subject: "admin", FreezeOverride: true})
+	ts.Require().False(pb.IsError, pb.Error)
+	_, err = (*GLOB.DSP).GetFreeze(later.FreezeID)
+	ts.Assert().ErrorContains(err, "does not exist")
+}
+
+func (ts *Transitions_TS) TestProtectedComponents() {
+	var t *testing.T = ts.T()
+
+	selectors, forceScope := GLOB.ProtectedSelectors, GLOB.ForceScope
+	defer func() {
+		GLOB.ProtectedSelectors, GLOB.ForceScope = selectors, forceScope
+	}()
+
+	newTransition := func(operation string, xnames ...string) model.Transition {
+		params := model.TransitionParameter{Operation: operation}
+		for _, xname := range xnames {
+			params.Location = append(params.Location, model.LocationParameter{Xname: xname})
+		}
+		tr, err := model.ToTransition(params, GLOB.ExpireTimeMins)
+		ts.Require().NoError(err)
+		return tr
+	}
+
+	/////////
+	// Test 1 - ConfigureProtectedComponents() Parses selectors
+	/////////
+	t.Logf("Test 1 - ConfigureProtectedComponents() Parses selectors")
+	ts.Assert().Error(ConfigureProtectedComponents([]string{"label=mgmt"}, model.DefaultForceScope))
+	ts.Assert().Error(ConfigureProtectedComponents([]string{"xname=x3009c0s0b0n0"}, ""))
+	ts.Require().NoError(ConfigureProtectedComponents(
+		[]string{"xname=x3009c0s0b0n0", "type=CabinetPDUPowerConnector"}, model.DefaultForceScope))
+	ts.Assert().Len(GLOB.ProtectedSelectors, 2)
+	ts.Assert().Equal(model.DefaultForceScope, GLOB.ForceScope)
+
+	/////////
+	// Test 2 - checkProtectedComponents() Finds protected components and their ancestors
+	/////////
+	t.Logf("Test 2 - checkProtectedComponents() Finds protected components and their ancestors")
+	protected, err := checkProtectedComponents(newTransition("Off", "x3009c0s0b0n1", "x3009c0s0b0n0", "x3009m0p0v1"))
+	ts.Require().NoError(err)
+	ts.Assert().Equal([]string{
+		"x3009c0s0b0n0 (xname=x3009c0s0b0n0)",
+		"x3009m0p0v1 (type=CabinetPDUPowerConnector)",
+	}, protected)
+	for _, operation := range []string{"Soft-Restart", "Init", "Force-Off"} {
+		protected, err = checkProtectedComponents(newTransition(operation, "x3009c0"))
+		ts.Require().NoError(err)
+		ts.Assert().Equal([]string{"x3009c0 (xname=x3009c0s0b0n0)"}, protected, operation)
+	}
+	protected, err = checkProtectedComponents(newTransition("On", "x3009c0s0b0n0"))
+	ts.Require().NoError(err)
+	ts.Assert().Empty(protected)
+	tr := newTransition("Off", "x3009c0s0b0n0")
+	tr.Force = true
+	protected, err = checkProtectedComponents(tr)
+	ts.Require().NoError(err)
+	ts.Assert().Empty(protected)
+
+	/////////
+	// Test 3 - TriggerTransition() and authorizeForce() Reject unforced and unauthorized transitions
+	/////////
+	t.Logf("Test 3 - TriggerTransition() and authorizeForce() Reject unforced and unauthorized transitions")
+	pb := TriggerTransition(newTransition("Off", "x3009c0s0b0n0"))
+	ts.Require().True(pb.IsError)
+	ts.Assert().Equal(http.StatusForbidden, pb.StatusCode)
+	ts.Assert().Contains(pb.Error.Detail, "x3009c0s0b0n0")
+	ts.Assert().Contains(pb.Error.Detail, model.DefaultForceScope)
+
+	startAt := time.Now().Add(time.Hour)
+	tr, err = model.ToTransition(model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x3009c0s0b0n0"}},
+		StartAt:   &startAt,
+	}, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	pb = TriggerTransition(tr)
+	ts.Require().True(pb.IsError)
+	ts.Assert().Equal(http.StatusForbidden, pb.StatusCode)
+	_, _, err = (*GLOB.DSP).GetTransition(tr.TransitionID)
+	ts.Assert().ErrorContains(err, "does not exist")
+
+	// Selectors aren't resolved until the transition is due.
+	tr, err = model.ToTransition(model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Group: "protected"}},
+		StartAt:   &startAt,
+	}, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	pb = TriggerTransition(tr)
+	ts.Require().False(pb.IsError, pb.Error)
+	deleteTransition(tr.TransitionID)
+
+	params := model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x3009c0s0b0n0"}},
+		Force:     true,
+	}
+	tr, err = model.ToTransition(params, GLOB.ExpireTimeMins)
+	ts.Require().NoError(err)
+	pb = CreateTransition(params, tr)
+	ts.Require().True(pb.IsError)
+	ts.Assert().Equal(http.StatusForbidden, pb.StatusCode)
+
+	ts.Assert().False(authorizeForce(false, model.Requester{}).IsError)
+	ts.Assert().False(authorizeForce(true, model.Requester{Scopes: []string{model.DefaultForceScope}}).IsError)
+	ts.Assert().True(authorizeForce(true, model.Requester{Scopes: []string{"openid"}}).IsError)
+
+	/////////
+	// Test 4 - failProtectedComps() Fails the tasks of protected components
+	/////////
+	t.Logf("Test 4 - failProtectedComps() Fails the tasks of protected components")
+	tr = newTransition("Off", "x3009c0s0b0n0", "x3009c0s0b0n1")
+	newXnameMap := func() map[string]*TransitionComponent {
+		xnameMap := make(map[string]*TransitionComponent)
+		for _, loc := range tr.Location {
+			task := model.NewTransitionTask(tr.TransitionID, model.Operation_Off)
+			task.Xname = loc.Xname
+			xnameMap[loc.Xname] = &TransitionComponent{Task: &task}
+		}
+		return xnameMap
+	}
+	xnameMap := newXnameMap()
+	failProtectedComps(tr, xnameMap)
+	defer (*GLOB.DSP).DeleteTransitionTask(tr.TransitionID, xnameMap["x3009c0s0b0n0"].Task.TaskID)
+	ts.Assert().Equal(model.TransitionTaskStatusFailed, xnameMap["x3009c0s0b0n0"].Task.Status)
+	ts.Assert().Equal("Protected component", xnameMap["x3009c0s0b0n0"].Task.StatusDesc)
+	ts.Assert().Equal(model.TransitionTaskStatusNew, xnameMap["x3009c0s0b0n1"].Task.Status)
+
+	tr.Force = true
+	xnameMap = newXnameMap()
+	failProtectedComps(tr, xnameMap)
+	ts.Assert().Equal(model.TransitionTaskStatusNew, xnameMap["x3009c0s0b0n0"].Task.Status)
+}
@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/logger"
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// Webhook delivery tuning. A failed delivery is retried after
+// webhookRetryBase, doubling with each attempt up to webhookRetryMax, until
+// it has been tried webhookMaxAttempts times.
+var (
+	webhookDeliveryInterval = 5 * time.Second
+	webhookRetryBase        = 10 * time.Second
+	webhookRetryMax         = time.Hour
+	webhookMaxAttempts      = 8
+	// How long an instance holds a delivery it is sending before another
+	// instance may pick it up. Must be longer than the client timeout.
+	webhookLease = time.Minute
+	// Redirects aren't followed, since they could lead anywhere the
+	// callback URL allowlist doesn't.
+	webhookClient = &http.Client{
+		Timeout: 20 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+)
+
+// ConfigureWebhooks sets the URLs that are notified of every completed
+// transition and power cap task, the URL prefixes a request's callbackURL
+// must fall under, and the secret notifications are signed with. The secret
+// is required if there are any URLs or prefixes. Without prefixes, requests
+// can't ask for callbacks.
+func ConfigureWebhooks(urls []string, callbackAllowlist []string, secret string) error {
+	for _, u := range urls {
+		if u == "" {
+			return fmt.Errorf("webhook URL cannot be empty")
+		}
+		if err := model.ValidateCallbackURL(u); err != nil {
+			return fmt.Errorf("invalid webhook URL '%s': %w", u, err)
+		}
+	}
+	for _, u := range callbackAllowlist {
+		if u == "" {
+			return fmt.Errorf("callback URL prefix cannot be empty")
+		}
+		if err := model.ValidateCallbackURL(u); err != nil {
+			return fmt.Errorf("invalid callback URL prefix '%s': %w", u, err)
+		}
+	}
+	if secret == "" && (len(urls) > 0 || len(callbackAllowlist) > 0) {
+		return fmt.Errorf("a webhook secret is required to send webhooks or callbacks")
+	}
+	GLOB.WebhookURLs = urls
+	GLOB.CallbackAllowlist = callbackAllowlist
+	GLOB.WebhookSecret = secret
+	return nil
+}
+
+// Checks that a request's callbackURL, if any, falls under one of the
+// allowed prefixes, so requests can't have PCS POST to arbitrary addresses.
+func checkCallbackURL(callbackURL string) (pb model.Passback) {
+	if callbackURL == "" || model.CallbackURLAllowed(callbackURL, GLOB.CallbackAllowlist) {
+		return
+	}
+	err := fmt.Errorf("callbackURL %s is not allowed", callbackURL)
+	if len(GLOB.CallbackAllowlist) == 0 {
+		err = errors.New("callbacks are not enabled")
+	}
+	pb = model.BuildErrorPassback(http.StatusBadRequest, err)
+	logger.Log.WithFields(logrus.Fields{"ERROR": err, "HttpStatusCode": pb.StatusCode}).Error("Invalid callback URL")
+	return
+}
+
+// Periodically sends webhook notifications that are due, including ones
+// left behind by other PCS instances, and prunes finished ones.
+func StartWebhookDelivery() {
+	go func() {
+		logger.Log.Debug("Starting webhook delivery.")
+		ticker := time.NewTicker(webhookDeliveryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				deliverPendingWebhooks()
+			}
+		}
+	}()
+}
+
+// Records a notification of event for each global webhook URL and
+// callbackURL, then tries to send them.
+func enqueueWebhooks(event string, callbackURL string, data interface{}) {
+	urls := append([]string{}, GLOB.WebhookURLs...)
+	if callbackURL != "" {
+		// Checked when the request was made, but the allowed prefixes may
+		// have changed since.
+		if model.CallbackURLAllowed(callbackURL, GLOB.CallbackAllowlist) {
+			urls = append(urls, callbackURL)
+		} else {
+			logger.Log.WithFields(logrus.Fields{"url": callbackURL}).Warn("Skipping callback to a URL that is not allowed")
+		}
+	}
+	seen := make(map[string]bool)
+	for _, url := range urls {
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+		delivery, err := model.NewWebhookDelivery(url, event, data, GLOB.ExpireTimeMins)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "url": url}).Error("Error building webhook notification")
+			continue
+		}
+		err = (*GLOB.DSP).StoreWebhookDelivery(delivery)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "url": url}).Error("Error storing webhook delivery")
+			continue
+		}
+		go attemptWebhookDelivery(delivery)
+	}
+}
+
+func deliverPendingWebhooks() {
+	deliveries, err := (*GLOB.DSP).GetAllWebhookDeliveries()
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Error("Error retreiving webhook deliveries")
+		return
+	}
+	now := time.Now()
+	for _, delivery := range deliveries {
+		if delivery.Status == model.WebhookDeliveryStatusPending {
+			if !delivery.NextAttempt.After(now) {
+				attemptWebhookDelivery(delivery)
+			}
+		} else if delivery.AutomaticExpirationTime.Before(now) {
+			err = (*GLOB.DSP).DeleteWebhookDelivery(delivery.DeliveryID)
+			if err != nil {
+				logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error deleting webhook delivery, %s.", delivery.DeliveryID.String())
+			}
+		}
+	}
+}
+
+// Claims a pending delivery and sends it once. The outcome is stored so the
+// next attempt, if any, can be made by any instance.
+func attemptWebhookDelivery(delivery model.WebhookDelivery) {
+	claimed := delivery
+	claimed.Attempts++
+	claimed.NextAttempt = model.WebhookTime(time.Now().Add(webhookLease))
+	ok, err := (*GLOB.DSP).TASWebhookDelivery(claimed, delivery)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error claiming webhook delivery, %s.", delivery.DeliveryID.String())
+		return
+	}
+	if !ok {
+		// Another instance got to it first.
+		return
+	}
+
+	result := claimed
+	err = sendWebhook(claimed)
+	if err == nil {
+		result.Status = model.WebhookDeliveryStatusDelivered
+		result.LastError = ""
+	} else {
+		result.LastError = err.Error()
+		if result.Attempts >= webhookMaxAttempts {
+			result.Status = model.WebhookDeliveryStatusFailed
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "url": result.URL}).Errorf("Giving up on webhook delivery, %s.", result.DeliveryID.String())
+		} else {
+			result.NextAttempt = model.WebhookTime(time.Now().Add(webhookBackoff(result.Attempts)))
+			logger.Log.WithFields(logrus.Fields{"ERROR": err, "url": result.URL}).Warnf("Webhook delivery, %s, failed. Will retry.", result.DeliveryID.String())
+		}
+	}
+	ok, err = (*GLOB.DSP).TASWebhookDelivery(result, claimed)
+	if err != nil || !ok {
+		logger.Log.WithFields(logrus.Fields{"ERROR": err}).Errorf("Error storing webhook delivery, %s.", result.DeliveryID.String())
+	}
+}
+
+func sendWebhook(delivery model.WebhookDelivery) error {
+	if GLOB.WebhookSecret == "" {
+		return errors.New("no webhook secret is configured to sign the notification")
+	}
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-PCS-Event", delivery.Event)
+	req.Header.Set("X-PCS-Delivery", delivery.DeliveryID.String())
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-PCS-Timestamp", timestamp)
+	req.Header.Set("X-PCS-Signature", signWebhookPayload(GLOB.WebhookSecret, timestamp, delivery.Payload))
+	rsp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	io.Copy(io.Discard, rsp.Body)
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", rsp.StatusCode)
+	}
+	return nil
+}
+
+// Computes the X-PCS-Signature header value, the hex encoded HMAC-SHA256 of
+// the X-PCS-Timestamp header value and the payload, joined by a period.
+// Signing the timestamp lets receivers reject replayed notifications.
+func signWebhookPayload(secret string, timestamp string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// How long to wait after the given number of failed attempts.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookRetryBase
+	for i := 1; i < attempts && backoff < webhookRetryMax; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookRetryMax {
+		backoff = webhookRetryMax
+	}
+	return backoff
+}
@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -304,6 +305,41 @@ func (suite *Models_TS) TestFillHSMData() {
 	}
 }
 
+func (suite *Models_TS) TestGetSelectedComponents() {
+	var query string
+	smServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != hsmStateComponentsPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = req.URL.RawQuery
+		if req.URL.Query().Get("group") == "missing" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"detail":"no such group"}`))
+			return
+		}
+		w.Write([]byte(`{"Components":[{"ID":"x0c0s0b0n0"},{"ID":"x0c0s1b0n0"}]}`))
+	}))
+	defer smServer.Close()
+
+	svcClient, err := hms_certs.CreateRetryableHTTPClientPair("", 10, 10, 1)
+	suite.Require().NoError(err)
+	glb := HSM_GLOBALS{SvcName: "HSMLayerTest", Logger: glogger,
+		SMUrl: smServer.URL, SVCHttpClient: svcClient}
+	HSM := &HSMv2{}
+	suite.Require().NoError(HSM.Init(&glb))
+
+	xnames, err := HSM.GetSelectedComponents(ComponentSelector{Role: "Compute", SubRole: "Worker"})
+	suite.Require().NoError(err)
+	suite.Equal([]string{"x0c0s0b0n0", "x0c0s1b0n0"}, xnames)
+	suite.Equal("role=Compute&subrole=Worker", query)
+
+	_, err = HSM.GetSelectedComponents(ComponentSelector{Group: "missing"})
+	suite.Error(err)
+	_, err = HSM.GetSelectedComponents(ComponentSelector{})
+	suite.Error(err)
+}
+
 func Test_Stuff(t *testing.T) {
 	suite.Run(t, new(Models_TS))
 }
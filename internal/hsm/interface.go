@@ -36,6 +36,7 @@ type HSMProvider interface {
 	CheckDeputyKeys(comp []ReservationData) error
 	FillComponentEndpointData(hd map[string]*HsmData) error
 	GetStateComponents(xnames []string) (base.ComponentArray, error)
+	GetSelectedComponents(selector ComponentSelector) ([]string, error)
 	FillPowerMapData(hd map[string]*HsmData) error
 	FillHSMData(xnames []string) (map[string]*HsmData, error)
 	BulkComponentStateUpdate(xnames []string, states string) error
@@ -88,3 +88,12 @@ type PowerCap struct {
 	Max         int
 	PwrCtlIndex int
 }
+
+// ComponentSelector picks components by HSM group, partition, or role. Every
+// field that is set must match.
+type ComponentSelector struct {
+	Group     string
+	Partition string
+	Role      string
+	SubRole   string
+}
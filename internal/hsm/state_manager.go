@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -622,6 +623,74 @@ func (b *HSMv2) GetStateComponents(xnames []string) (base.ComponentArray, error)
 	return retData, nil
 }
 
+// Fetch the IDs of the components matching selector from HSM.
+func (b *HSMv2) GetSelectedComponents(selector ComponentSelector) ([]string, error) {
+	var retData base.ComponentArray
+	var xnames []string
+
+	query := url.Values{}
+	if selector.Group != "" {
+		query.Set("group", selector.Group)
+	}
+	if selector.Partition != "" {
+		query.Set("partition", selector.Partition)
+	}
+	if selector.Role != "" {
+		query.Set("role", selector.Role)
+	}
+	if selector.SubRole != "" {
+		query.Set("subrole", selector.SubRole)
+	}
+	if len(query) == 0 {
+		return xnames, errors.New("Component selector is empty")
+	}
+	smurl := b.HSMGlobals.SMUrl + hsmStateComponentsPath + "?" + query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, smurl, nil)
+	if err != nil {
+		return xnames, fmt.Errorf("ERROR creating HTTP request for '%s': %v", smurl, err)
+	}
+
+	reqContext, reqCtxCancel := context.WithTimeout(context.Background(), 40*time.Second)
+
+	req = req.WithContext(reqContext)
+
+	rsp, rsperr := b.HSMGlobals.SVCHttpClient.Do(req)
+	if rsperr != nil {
+		// Always drain and close response bodies
+		base.DrainAndCloseResponseBody(rsp)
+
+		reqCtxCancel() // Release resources and signal context timeout to stop
+
+		return xnames, fmt.Errorf("Error in http request '%s': %v", smurl, rsperr)
+	}
+
+	body, bderr := io.ReadAll(rsp.Body)
+
+	// Always close response bodies
+	base.DrainAndCloseResponseBody(rsp)
+
+	reqCtxCancel() // Release resources and signal context timeout to stop
+
+	if bderr != nil {
+		return xnames, fmt.Errorf("Error reading response body for '%s': %v", smurl, bderr)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return xnames, fmt.Errorf("Error response from '%s': %d %s", smurl,
+			rsp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	bderr = json.Unmarshal(body, &retData)
+	if bderr != nil {
+		return xnames, fmt.Errorf("Error unmarshalling response body for '%s': %v", smurl, bderr)
+	}
+
+	for _, comp := range retData.Components {
+		xnames = append(xnames, comp.ID)
+	}
+	return xnames, nil
+}
+
 // Fetch power map from HSM.
 func (b *HSMv2) FillPowerMapData(hd map[string]*HsmData) error {
 	var retData []sm.PowerMap
@@ -0,0 +1,105 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CronTS struct {
+	suite.Suite
+}
+
+func (suite *CronTS) TestParseCronScheduleErrors() {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := ParseCronSchedule(expr)
+		suite.Error(err, "expected an error for '%s'", expr)
+	}
+}
+
+func (suite *CronTS) TestNext() {
+	// Wednesday
+	base := time.Date(2025, time.January, 1, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.January, 1, 10, 31, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2025, time.January, 2, 10, 30, 0, 0, time.UTC)},
+		{"0 22 * * 1-5", time.Date(2025, time.January, 1, 22, 0, 0, 0, time.UTC)},
+		{"0 2 * * 0", time.Date(2025, time.January, 5, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * 7", time.Date(2025, time.January, 5, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, time.January, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 6 15,20 * *", time.Date(2025, time.January, 15, 6, 0, 0, 0, time.UTC)},
+		// Both day fields restricted matches either.
+		{"0 0 20 * 5", time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		sched, err := ParseCronSchedule(test.expr)
+		suite.Require().NoError(err, test.expr)
+		suite.Equal(test.want, sched.Next(base), test.expr)
+	}
+}
+
+func (suite *CronTS) TestScheduledTransition() {
+	param := TransitionParameter{Operation: "on"}
+	tr, err := ToTransition(param, 10)
+	suite.NoError(err)
+	suite.Equal(TransitionStatusNew, tr.Status)
+	suite.Nil(tr.StartAt)
+
+	// A start time in the past starts right away
+	past := time.Now().Add(-time.Hour)
+	param.StartAt = &past
+	tr, err = ToTransition(param, 10)
+	suite.NoError(err)
+	suite.Equal(TransitionStatusNew, tr.Status)
+
+	future := time.Now().Add(time.Hour)
+	param.StartAt = &future
+	tr, err = ToTransition(param, 10)
+	suite.NoError(err)
+	suite.Equal(TransitionStatusScheduled, tr.Status)
+	suite.True(tr.StartAt.Equal(future))
+	suite.True(tr.AutomaticExpirationTime.Equal(future.Add(10 * time.Minute)))
+	_, ok := NextScheduledTransition(tr, 10)
+	suite.False(ok)
+
+	param.StartAt = nil
+	param.CronSchedule = "0 * * * *"
+	tr, err = ToTransition(param, 10)
+	suite.NoError(err)
+	suite.Equal(TransitionStatusScheduled, tr.Status)
+	suite.Equal(0, tr.StartAt.Minute())
+	next, ok := NextScheduledTransition(tr, 10)
+	suite.True(ok)
+	suite.NotEqual(tr.TransitionID, next.TransitionID)
+	suite.Equal(TransitionStatusScheduled, next.Status)
+	suite.Equal(tr.CronSchedule, next.CronSchedule)
+
+	param.CronSchedule = "0 0 30 2 *"
+	_, err = ToTransition(param, 10)
+	suite.Error(err)
+}
+
+func TestCronSuite(t *testing.T) {
+	suite.Run(t, new(CronTS))
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+const (
+	DesiredPowerStateOn  = "on"
+	DesiredPowerStateOff = "off"
+
+	// DesiredPowerStateGroupPrefix marks a desired power state target as an
+	// HSM group rather than an xname.
+	DesiredPowerStateGroupPrefix = "group:"
+
+	// DesiredPowerStateLabel is set, to the desired power state, on the
+	// transitions the reconciler starts.
+	DesiredPowerStateLabel = "desired-power-state"
+)
+
+// DesiredPowerState is the power state the reconciler keeps a component, or
+// every member of an HSM group, in. Target is the xname, or the group name
+// prefixed with DesiredPowerStateGroupPrefix, and identifies the entry.
+type DesiredPowerState struct {
+	Target     string    `json:"target" db:"target"`
+	Xname      string    `json:"xname,omitempty" db:"xname"`
+	Group      string    `json:"group,omitempty" db:"group_name"`
+	PowerState string    `json:"powerState" db:"power_state"`
+	UpdateTime time.Time `json:"updateTime" db:"updated"`
+}
+
+type DesiredPowerStateArray struct {
+	DesiredPowerStates []DesiredPowerState `json:"desiredPowerStates"`
+}
+
+// NormalizeDesiredPowerStateTarget normalizes the xname in target. Group
+// targets are returned as they are.
+func NormalizeDesiredPowerStateTarget(target string) string {
+	if strings.HasPrefix(target, DesiredPowerStateGroupPrefix) {
+		return target
+	}
+	return xnametypes.NormalizeHMSCompID(target)
+}
+
+// ToDesiredPowerState builds the desired power state for target, an xname or
+// group:<name>, after checking it and powerState.
+func ToDesiredPowerState(target string, powerState string) (DesiredPowerState, error) {
+	var d DesiredPowerState
+	if group, ok := strings.CutPrefix(target, DesiredPowerStateGroupPrefix); ok {
+		if group == "" {
+			return d, fmt.Errorf("invalid target %s, group name is empty", target)
+		}
+		d.Group = group
+		d.Target = DesiredPowerStateGroupPrefix + group
+	} else {
+		xname := NormalizeDesiredPowerStateTarget(target)
+		if !xnametypes.IsHMSCompIDValid(xname) {
+			return d, fmt.Errorf("invalid target %s, must be an xname or %s<name>", target, DesiredPowerStateGroupPrefix)
+		}
+		d.Xname = xname
+		d.Target = xname
+	}
+	d.PowerState = strings.ToLower(powerState)
+	if d.PowerState != DesiredPowerStateOn && d.PowerState != DesiredPowerStateOff {
+		return d, fmt.Errorf("invalid powerState %s, must be %s or %s", powerState,
+			DesiredPowerStateOn, DesiredPowerStateOff)
+	}
+	d.UpdateTime = time.Now()
+	return d, nil
+}
@@ -0,0 +1,44 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DesiredPowerStateTS struct {
+	suite.Suite
+}
+
+func (suite *DesiredPowerStateTS) TestToDesiredPowerState() {
+	d, err := ToDesiredPowerState("X1000C0S0B0N0", "On")
+	suite.Require().NoError(err)
+	suite.Equal("x1000c0s0b0n0", d.Target)
+	suite.Equal("x1000c0s0b0n0", d.Xname)
+	suite.Empty(d.Group)
+	suite.Equal(DesiredPowerStateOn, d.PowerState)
+	suite.False(d.UpdateTime.IsZero())
+
+	d, err = ToDesiredPowerState("group:compute", "off")
+	suite.Require().NoError(err)
+	suite.Equal("group:compute", d.Target)
+	suite.Equal("compute", d.Group)
+	suite.Empty(d.Xname)
+	suite.Equal(DesiredPowerStateOff, d.PowerState)
+
+	for _, tc := range []struct{ target, powerState string }{
+		{"group:", "on"},
+		{"notanxname", "on"},
+		{"x1000c0s0b0n0", "soft-off"},
+		{"x1000c0s0b0n0", ""},
+	} {
+		_, err = ToDesiredPowerState(tc.target, tc.powerState)
+		suite.Error(err, "%s %s", tc.target, tc.powerState)
+	}
+}
+
+func TestDesiredPowerStateSuite(t *testing.T) {
+	suite.Run(t, new(DesiredPowerStateTS))
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/google/uuid"
+)
+
+// FreezeOverrideClaim is the token claim that, when true, lets the caller
+// start transitions and power cap tasks on frozen components.
+const FreezeOverrideClaim = "pcs_freeze_override"
+
+// FreezeParameter asks for components to be left alone between StartTime
+// and EndTime. Components are frozen by xname, by HSM group, or by prefix,
+// an xname whose descendants are all frozen along with it.
+type FreezeParameter struct {
+	Xnames   []string `json:"xnames,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Prefixes []string `json:"prefixes,omitempty"`
+	// StartTime defaults to now.
+	StartTime *time.Time `json:"startTime,omitempty"`
+	EndTime   time.Time  `json:"endTime"`
+	Reason    string     `json:"reason"`
+	// Requester is set by the API from the caller's token.
+	Requester Requester `json:"-"`
+}
+
+type Freeze struct {
+	FreezeID         uuid.UUID  `json:"freezeID" db:"id"`
+	Xnames           XnameSlice `json:"xnames,omitempty" db:"xnames"`
+	Groups           GroupSlice `json:"groups,omitempty" db:"group_names"`
+	Prefixes         XnameSlice `json:"prefixes,omitempty" db:"prefixes"`
+	StartTime        time.Time  `json:"startTime" db:"start_time"`
+	EndTime          time.Time  `json:"endTime" db:"end_time"`
+	Reason           string     `json:"reason" db:"reason"`
+	RequesterSubject string     `json:"requesterSubject,omitempty" db:"requester_sub"`
+	RequesterIssuer  string     `json:"requesterIssuer,omitempty" db:"requester_iss"`
+	CreateTime       time.Time  `json:"createTime" db:"created"`
+}
+
+type FreezeResp struct {
+	FreezeID   uuid.UUID  `json:"freezeID"`
+	Xnames     []string   `json:"xnames,omitempty"`
+	Groups     []string   `json:"groups,omitempty"`
+	Prefixes   []string   `json:"prefixes,omitempty"`
+	StartTime  time.Time  `json:"startTime"`
+	EndTime    time.Time  `json:"endTime"`
+	Reason     string     `json:"reason"`
+	Requester  *Requester `json:"requester,omitempty"`
+	CreateTime time.Time  `json:"createTime"`
+	Active     bool       `json:"active"`
+}
+
+type FreezeRespArray struct {
+	Freezes []FreezeResp `json:"freezes"`
+}
+
+// GroupSlice is a list of HSM group names.
+type GroupSlice []string
+
+func (g GroupSlice) Value() (driver.Value, error) {
+	return json.Marshal(g)
+}
+
+func (g *GroupSlice) Scan(value interface{}) error {
+	if value == nil {
+		*g = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &g)
+}
+
+// ToFreeze builds a freeze from parameter after checking it. Xnames and
+// prefixes are normalized.
+func ToFreeze(parameter FreezeParameter) (f Freeze, err error) {
+	now := time.Now()
+	f.FreezeID = uuid.New()
+	f.CreateTime = now
+	f.RequesterSubject = parameter.Requester.Subject
+	f.RequesterIssuer = parameter.Requester.Issuer
+	f.Reason = strings.TrimSpace(parameter.Reason)
+	if f.Reason == "" {
+		return f, errors.New("freeze reason is required")
+	}
+
+	if len(parameter.Xnames) == 0 && len(parameter.Groups) == 0 && len(parameter.Prefixes) == 0 {
+		return f, errors.New("freeze must have at least one xname, group, or prefix")
+	}
+	f.Xnames, err = normalizeFreezeXnames(parameter.Xnames)
+	if err != nil {
+		return f, err
+	}
+	f.Prefixes, err = normalizeFreezeXnames(parameter.Prefixes)
+	if err != nil {
+		return f, err
+	}
+	for _, group := range parameter.Groups {
+		if strings.TrimSpace(group) == "" {
+			return f, errors.New("freeze group name is empty")
+		}
+		f.Groups = append(f.Groups, group)
+	}
+
+	f.StartTime = now
+	if parameter.StartTime != nil {
+		f.StartTime = *parameter.StartTime
+	}
+	f.EndTime = parameter.EndTime
+	if f.EndTime.IsZero() {
+		return f, errors.New("freeze endTime is required")
+	}
+	if !f.EndTime.After(f.StartTime) {
+		return f, errors.New("freeze endTime must be after its startTime")
+	}
+	if !f.EndTime.After(now) {
+		return f, errors.New("freeze endTime has already passed")
+	}
+	return f, nil
+}
+
+func normalizeFreezeXnames(xnames []string) (XnameSlice, error) {
+	var normalized XnameSlice
+	for _, xname := range xnames {
+		x := xnametypes.NormalizeHMSCompID(xname)
+		if !xnametypes.IsHMSCompIDValid(x) {
+			return nil, fmt.Errorf("invalid freeze xname %s", xname)
+		}
+		normalized = append(normalized, x)
+	}
+	return normalized, nil
+}
+
+// Active reports whether the freeze is in effect at t.
+func (f Freeze) Active(t time.Time) bool {
+	return !t.Before(f.StartTime) && t.Before(f.EndTime)
+}
+
+func ToFreezeResp(f Freeze) FreezeResp {
+	return FreezeResp{
+		FreezeID:   f.FreezeID,
+		Xnames:     f.Xnames,
+		Groups:     f.Groups,
+		Prefixes:   f.Prefixes,
+		StartTime:  f.StartTime,
+		EndTime:    f.EndTime,
+		Reason:     f.Reason,
+		Requester:  toRequesterResp(f.RequesterSubject, f.RequesterIssuer),
+		CreateTime: f.CreateTime,
+		Active:     f.Active(time.Now()),
+	}
+}
@@ -0,0 +1,76 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FreezeTS struct {
+	suite.Suite
+}
+
+func (suite *FreezeTS) TestToFreeze() {
+	end := time.Now().Add(time.Hour)
+	f, err := ToFreeze(FreezeParameter{
+		Xnames:    []string{"X1000C0S0B0N0"},
+		Groups:    []string{"compute"},
+		Prefixes:  []string{"x1001C0"},
+		EndTime:   end,
+		Reason:    " Firmware update ",
+		Requester: Requester{Subject: "admin"},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(XnameSlice{"x1000c0s0b0n0"}, f.Xnames)
+	suite.Equal(GroupSlice{"compute"}, f.Groups)
+	suite.Equal(XnameSlice{"x1001c0"}, f.Prefixes)
+	suite.Equal("Firmware update", f.Reason)
+	suite.Equal("admin", f.RequesterSubject)
+	suite.True(f.Active(time.Now()))
+	suite.False(f.Active(end))
+
+	past := time.Now().Add(-2 * time.Hour)
+	later := time.Now().Add(2 * time.Hour)
+	for name, p := range map[string]FreezeParameter{
+		"no reason":     {Xnames: []string{"x1000c0"}, EndTime: end},
+		"no targets":    {EndTime: end, Reason: "r"},
+		"bad xname":     {Xnames: []string{"rack1"}, EndTime: end, Reason: "r"},
+		"bad prefix":    {Prefixes: []string{"x1000q"}, EndTime: end, Reason: "r"},
+		"empty group":   {Groups: []string{" "}, EndTime: end, Reason: "r"},
+		"no end":        {Xnames: []string{"x1000c0"}, Reason: "r"},
+		"end passed":    {Xnames: []string{"x1000c0"}, StartTime: &past, EndTime: past.Add(time.Hour), Reason: "r"},
+		"end too early": {Xnames: []string{"x1000c0"}, StartTime: &later, EndTime: end, Reason: "r"},
+	} {
+		_, err = ToFreeze(p)
+		suite.Error(err, name)
+	}
+}
+
+func (suite *FreezeTS) TestToTransition() {
+	tr, err := ToTransition(TransitionParameter{Operation: "On", Requester: Requester{FreezeOverride: true}}, 5)
+	suite.Require().NoError(err)
+	suite.True(tr.FreezeOverride)
+	suite.True(ToTransitionResp(tr, nil, true).FreezeOverride)
+
+	// Later occurrences are checked against freezes.
+	next, ok := NextScheduledTransition(Transition{CronSchedule: "0 * * * *", FreezeOverride: true}, 5)
+	suite.Require().True(ok)
+	suite.False(next.FreezeOverride)
+}
+
+func (suite *FreezeTS) TestGroupSliceScan() {
+	groups := GroupSlice{"compute"}
+	suite.Require().NoError(groups.Scan(nil))
+	suite.Nil(groups)
+
+	suite.Require().NoError(groups.Scan([]byte(`["compute","io"]`)))
+	suite.Equal(GroupSlice{"compute", "io"}, groups)
+	suite.Error(groups.Scan("compute"))
+}
+
+func TestFreezeSuite(t *testing.T) {
+	suite.Run(t, new(FreezeTS))
+}
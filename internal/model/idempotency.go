@@ -0,0 +1,76 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+///////////////////////////
+// Idempotency Definitions
+///////////////////////////
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// The requests an Idempotency-Key is remembered for. The same key may be
+// used once in each.
+const (
+	IdempotencyScopeTransition       = "transition"
+	IdempotencyScopePowerCapSnapshot = "power-cap-snapshot"
+	IdempotencyScopePowerCapPatch    = "power-cap-patch"
+)
+
+const maxIdempotencyKeyLen = 255
+
+// IdempotencyRecord remembers the response to a request made with an
+// Idempotency-Key so a repeat of the request gets the same response instead
+// of starting another job. Keys are scoped to the request type and the
+// requester's subject.
+type IdempotencyRecord struct {
+	Scope   string `json:"scope" db:"scope"`
+	Subject string `json:"subject" db:"subject"`
+	Key     string `json:"key" db:"key"`
+	// RequestHash identifies the request body the key was first used with.
+	RequestHash string `json:"requestHash" db:"request_hash"`
+	// Response is the JSON encoded creation response. It is empty until the
+	// job has been started.
+	Response                string    `json:"response" db:"response"`
+	CreateTime              time.Time `json:"createTime" db:"created"`
+	AutomaticExpirationTime time.Time `json:"automaticExpirationTime" db:"expires"`
+}
+
+// NewIdempotencyRecord creates an unfinished record of request, made by
+// requester with key, that is kept for retentionMins.
+func NewIdempotencyRecord(scope string, requester Requester, key string, request interface{}, retentionMins int) (IdempotencyRecord, error) {
+	now := time.Now().Truncate(time.Microsecond)
+	record := IdempotencyRecord{
+		Scope:                   scope,
+		Subject:                 requester.Subject,
+		Key:                     key,
+		CreateTime:              now,
+		AutomaticExpirationTime: now.Add(time.Minute * time.Duration(retentionMins)),
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return record, err
+	}
+	sum := sha256.Sum256(body)
+	record.RequestHash = hex.EncodeToString(sum[:])
+	return record, nil
+}
+
+// ValidateIdempotencyKey checks an Idempotency-Key header value. An empty key
+// is valid and means the request isn't deduplicated.
+func ValidateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLen {
+		return errors.New("Idempotency-Key must be at most 255 characters")
+	}
+	for _, c := range key {
+		if c < 0x21 || c > 0x7e {
+			return errors.New("Idempotency-Key must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
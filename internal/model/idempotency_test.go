@@ -0,0 +1,50 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyTS struct {
+	suite.Suite
+}
+
+func (suite *IdempotencyTS) TestNewIdempotencyRecord() {
+	requester := Requester{Subject: "automation"}
+	params := TransitionParameter{Operation: "On", Location: []LocationParameter{{Xname: "x0c0s0b0n0"}}}
+	record, err := NewIdempotencyRecord(IdempotencyScopeTransition, requester, "key-1", params, 60)
+	suite.Require().NoError(err)
+	suite.Equal("automation", record.Subject)
+	suite.Equal("key-1", record.Key)
+	suite.Empty(record.Response)
+	suite.Equal(time.Hour, record.AutomaticExpirationTime.Sub(record.CreateTime))
+
+	// The request hash ignores the fields set from the request headers.
+	params.Requester = Requester{Subject: "someone-else"}
+	params.IdempotencyKey = "key-1"
+	same, err := NewIdempotencyRecord(IdempotencyScopeTransition, requester, "key-1", params, 60)
+	suite.Require().NoError(err)
+	suite.Equal(record.RequestHash, same.RequestHash)
+
+	params.Operation = "Off"
+	different, err := NewIdempotencyRecord(IdempotencyScopeTransition, requester, "key-1", params, 60)
+	suite.Require().NoError(err)
+	suite.NotEqual(record.RequestHash, different.RequestHash)
+}
+
+func (suite *IdempotencyTS) TestValidateIdempotencyKey() {
+	suite.NoError(ValidateIdempotencyKey(""))
+	suite.NoError(ValidateIdempotencyKey("3f6e1a7c-5a59-4bd3-9a8e-0d0e4b8f4f3b"))
+	suite.Error(ValidateIdempotencyKey("has space"))
+	suite.Error(ValidateIdempotencyKey("tab\tkey"))
+	suite.Error(ValidateIdempotencyKey(strings.Repeat("k", 256)))
+}
+
+func TestIdempotencySuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyTS))
+}
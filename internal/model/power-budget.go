@@ -0,0 +1,97 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+const (
+	// PowerBudgetSystem is the PowerBudget target for the whole system.
+	PowerBudgetSystem = "system"
+
+	PowerBudgetPolicyReject  = "reject"
+	PowerBudgetPolicyQueue   = "queue"
+	PowerBudgetPolicyPartial = "partial"
+)
+
+// PowerBudget is the most power, in watts, the components in a cabinet, or
+// the whole system, may be expected to draw once powered on.
+type PowerBudget struct {
+	Target string `json:"target"`
+	Watts  int    `json:"watts"`
+}
+
+// ParsePowerBudget parses a power budget written as target=watts, where the
+// target is a cabinet xname or system, e.g. x1000=80000 or system=1500000.
+func ParsePowerBudget(spec string) (PowerBudget, error) {
+	var b PowerBudget
+	target, watts, ok := strings.Cut(spec, "=")
+	if !ok {
+		return b, fmt.Errorf("invalid power budget %s, must be target=watts", spec)
+	}
+	b.Target = strings.ToLower(strings.TrimSpace(target))
+	if b.Target != PowerBudgetSystem {
+		b.Target = xnametypes.NormalizeHMSCompID(b.Target)
+		if xnametypes.GetHMSType(b.Target) != xnametypes.Cabinet {
+			return b, fmt.Errorf("invalid power budget target %s, must be a cabinet xname or %s", target, PowerBudgetSystem)
+		}
+	}
+	var err error
+	b.Watts, err = strconv.Atoi(watts)
+	if err != nil {
+		return b, fmt.Errorf("invalid power budget %s: watts must be a number", spec)
+	}
+	if b.Watts < 1 {
+		return b, fmt.Errorf("invalid power budget %s: watts must be at least 1", spec)
+	}
+	return b, nil
+}
+
+// ValidatePowerBudgetPolicy checks a transition's budgetPolicy.
+func ValidatePowerBudgetPolicy(policy string) error {
+	switch policy {
+	case "", PowerBudgetPolicyReject, PowerBudgetPolicyQueue, PowerBudgetPolicyPartial:
+		return nil
+	}
+	return fmt.Errorf("invalid budgetPolicy %s, must be %s, %s, or %s", policy,
+		PowerBudgetPolicyReject, PowerBudgetPolicyQueue, PowerBudgetPolicyPartial)
+}
+
+// ComponentPowerCapabilities are the power capabilities last read from a
+// component's BMC by a power capping task.
+type ComponentPowerCapabilities struct {
+	Xname        string    `json:"xname" db:"xname"`
+	HostLimitMax *int      `json:"hostLimitMax,omitempty" db:"host_limit_max"`
+	HostLimitMin *int      `json:"hostLimitMin,omitempty" db:"host_limit_min"`
+	PowerupPower *int      `json:"powerupPower,omitempty" db:"powerup_power"`
+	UpdateTime   time.Time `json:"updateTime" db:"updated"`
+}
+
+// ToComponentPowerCapabilities builds the power capabilities of xname from
+// the limits a power capping task read.
+func ToComponentPowerCapabilities(xname string, limits PowerCapabilities) ComponentPowerCapabilities {
+	return ComponentPowerCapabilities{
+		Xname:        xnametypes.NormalizeHMSCompID(xname),
+		HostLimitMax: limits.HostLimitMax,
+		HostLimitMin: limits.HostLimitMin,
+		PowerupPower: limits.PowerupPower,
+		UpdateTime:   time.Now(),
+	}
+}
+
+// EstimatedDraw is the power, in watts, the component is expected to draw
+// once on: its powerup power, or failing that its maximum host limit.
+// Returns false if neither is known.
+func (c ComponentPowerCapabilities) EstimatedDraw() (int, bool) {
+	if c.PowerupPower != nil && *c.PowerupPower > 0 {
+		return *c.PowerupPower, true
+	}
+	if c.HostLimitMax != nil && *c.HostLimitMax > 0 {
+		return *c.HostLimitMax, true
+	}
+	return 0, false
+}
@@ -0,0 +1,68 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PowerBudgetTS struct {
+	suite.Suite
+}
+
+func (suite *PowerBudgetTS) TestParsePowerBudget() {
+	for spec, want := range map[string]PowerBudget{
+		"x1000=80000":    {Target: "x1000", Watts: 80000},
+		"X01001=60000":   {Target: "x1001", Watts: 60000},
+		"System=1500000": {Target: PowerBudgetSystem, Watts: 1500000},
+	} {
+		b, err := ParsePowerBudget(spec)
+		suite.Require().NoError(err, spec)
+		suite.Equal(want, b, spec)
+	}
+	for _, spec := range []string{
+		"x1000",
+		"x1000=lots",
+		"x1000=0",
+		"x1000c0=80000",
+		"rack1=80000",
+		"=80000",
+	} {
+		_, err := ParsePowerBudget(spec)
+		suite.Error(err, spec)
+	}
+}
+
+func (suite *PowerBudgetTS) TestEstimatedDraw() {
+	powerup := 900
+	max := 1200
+	zero := 0
+
+	draw, ok := ToComponentPowerCapabilities("X1000C0S0B0N0", PowerCapabilities{HostLimitMax: &max, PowerupPower: &powerup}).EstimatedDraw()
+	suite.True(ok)
+	suite.Equal(powerup, draw)
+
+	caps := ToComponentPowerCapabilities("x1000c0s0b0n0", PowerCapabilities{HostLimitMax: &max, PowerupPower: &zero})
+	suite.Equal("x1000c0s0b0n0", caps.Xname)
+	draw, ok = caps.EstimatedDraw()
+	suite.True(ok)
+	suite.Equal(max, draw)
+
+	_, ok = ToComponentPowerCapabilities("x1000c0s0b0n0", PowerCapabilities{}).EstimatedDraw()
+	suite.False(ok)
+}
+
+func (suite *PowerBudgetTS) TestToTransition() {
+	tr, err := ToTransition(TransitionParameter{Operation: "On", BudgetPolicy: "Partial"}, 5)
+	suite.Require().NoError(err)
+	suite.Equal(PowerBudgetPolicyPartial, tr.BudgetPolicy)
+
+	_, err = ToTransition(TransitionParameter{Operation: "On", BudgetPolicy: "ignore"}, 5)
+	suite.Error(err)
+}
+
+func TestPowerBudgetSuite(t *testing.T) {
+	suite.Run(t, new(PowerBudgetTS))
+}
@@ -62,6 +62,13 @@ const (
 
 type PowerCapSnapshotParameter struct {
 	Xnames []string `json:"xnames"`
+	// Reason and Labels are free-form notes kept with the task.
+	Reason string `json:"reason,omitempty"`
+	Labels Labels `json:"labels,omitempty"`
+	// Requester is set by the API from the caller's token.
+	Requester Requester `json:"-"`
+	// IdempotencyKey is set by the API from the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
 }
 
 func (p PowerCapSnapshotParameter) Value() (driver.Value, error) {
@@ -84,6 +91,16 @@ func (p *PowerCapSnapshotParameter) Scan(value interface{}) error {
 
 type PowerCapPatchParameter struct {
 	Components []PowerCapComponentParameter `json:"components"`
+	// CallbackURL is sent a webhook notification when the task completes,
+	// in addition to any globally registered webhook URLs.
+	CallbackURL string `json:"callbackURL,omitempty"`
+	// Reason and Labels are free-form notes kept with the task.
+	Reason string `json:"reason,omitempty"`
+	Labels Labels `json:"labels,omitempty"`
+	// Requester is set by the API from the caller's token.
+	Requester Requester `json:"-"`
+	// IdempotencyKey is set by the API from the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
 }
 
 func (p PowerCapPatchParameter) Value() (driver.Value, error) {
@@ -123,6 +140,13 @@ type PowerCapTask struct {
 	TaskStatus              string                     `json:"taskStatus" db:"status"`
 	OperationIDs            []uuid.UUID
 
+	// RequesterSubject and RequesterIssuer are the sub and iss claims of the caller's token. Reason and Labels are
+	// free-form notes from the request.
+	RequesterSubject string `json:"requesterSubject,omitempty" db:"requester_sub"`
+	RequesterIssuer  string `json:"requesterIssuer,omitempty" db:"requester_iss"`
+	Reason           string `json:"reason,omitempty" db:"reason"`
+	Labels           Labels `json:"labels,omitempty" db:"labels"`
+
 	// Only populated when the task is completed, but stored in the DB, not just calculated. these save an operation
 	// list summary, since we delete operation rows after completing a task.
 	IsCompressed bool                   `json:"isCompressed" db:"compressed"`
@@ -175,6 +199,9 @@ type PowerCapTaskResp struct {
 	TaskCreateTime          time.Time              `json:"taskCreateTime"`
 	AutomaticExpirationTime time.Time              `json:"automaticExpirationTime"`
 	TaskStatus              string                 `json:"taskStatus"`
+	Requester               *Requester             `json:"requester,omitempty"`
+	Reason                  string                 `json:"reason,omitempty"`
+	Labels                  Labels                 `json:"labels,omitempty"`
 	TaskCounts              PowerCapTaskCounts     `json:"taskCounts"`
 	Components              PowerCapComponentSlice `json:"components,omitempty"`
 }
@@ -257,6 +284,7 @@ func NewPowerCapSnapshotTask(parameters PowerCapSnapshotParameter, expirationTim
 	task := newPowerCapTask(expirationTimeMins)
 	task.Type = PowerCapTaskTypeSnapshot
 	task.SnapshotParameters = &parameters
+	task.setRequest(parameters.Requester, parameters.Reason, parameters.Labels)
 	return task
 }
 
@@ -264,9 +292,22 @@ func NewPowerCapPatchTask(parameters PowerCapPatchParameter, expirationTimeMins
 	task := newPowerCapTask(expirationTimeMins)
 	task.Type = PowerCapTaskTypePatch
 	task.PatchParameters = &parameters
+	task.setRequest(parameters.Requester, parameters.Reason, parameters.Labels)
 	return task
 }
 
+func (task *PowerCapTask) setRequest(requester Requester, reason string, labels Labels) {
+	task.RequesterSubject = requester.Subject
+	task.RequesterIssuer = requester.Issuer
+	task.Reason = reason
+	task.Labels = labels
+}
+
+// Requester returns who asked for the task, or nil if that isn't known.
+func (task PowerCapTask) Requester() *Requester {
+	return toRequesterResp(task.RequesterSubject, task.RequesterIssuer)
+}
+
 func newPowerCapTask(expirationTimeMins int) PowerCapTask {
 	return PowerCapTask{
 		TaskID:                  uuid.New(),
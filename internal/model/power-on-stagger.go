@@ -0,0 +1,135 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+const (
+	// PowerOnStaggerByCabinet spaces out the components in each cabinet.
+	PowerOnStaggerByCabinet = "cabinet"
+	// PowerOnStaggerByPDU spaces out the components fed by each PDU, found
+	// from the PDU connectors HSM says power them. Components HSM has no
+	// PDU connectors for are grouped by cabinet.
+	PowerOnStaggerByPDU = "pdu"
+)
+
+// PowerOnStagger spaces out powering on the components of a type, to limit
+// inrush current. Within each cabinet or PDU, Count components are powered
+// on at a time, DelaySeconds apart. ComponentType may be left empty to cover
+// every component type, and GroupBy defaults to PowerOnStaggerByCabinet. A
+// DelaySeconds of zero turns staggering off.
+type PowerOnStagger struct {
+	ComponentType string `json:"componentType,omitempty"`
+	GroupBy       string `json:"groupBy,omitempty"`
+	Count         int    `json:"count"`
+	DelaySeconds  int    `json:"delaySeconds"`
+}
+
+// Normalizes the component type and grouping and checks the spacing.
+func (s *PowerOnStagger) normalize() error {
+	if s.ComponentType != "" {
+		compType := xnametypes.VerifyNormalizeType(s.ComponentType)
+		if compType == "" {
+			return fmt.Errorf("invalid power on stagger componentType %s", s.ComponentType)
+		}
+		s.ComponentType = compType
+	}
+	s.GroupBy = strings.ToLower(s.GroupBy)
+	if s.GroupBy == "" {
+		s.GroupBy = PowerOnStaggerByCabinet
+	}
+	if s.GroupBy != PowerOnStaggerByCabinet && s.GroupBy != PowerOnStaggerByPDU {
+		return fmt.Errorf("invalid power on stagger groupBy %s, must be %s or %s", s.GroupBy,
+			PowerOnStaggerByCabinet, PowerOnStaggerByPDU)
+	}
+	if s.Count < 1 {
+		return errors.New("power on stagger count must be at least 1")
+	}
+	if s.DelaySeconds < 0 {
+		return errors.New("power on stagger delaySeconds cannot be negative")
+	}
+	return nil
+}
+
+type PowerOnStaggerSlice []PowerOnStagger
+
+// Lookup returns the stagger for compType components, or failing that the
+// one for every component type. Returns false if neither is set.
+func (s PowerOnStaggerSlice) Lookup(compType string) (PowerOnStagger, bool) {
+	for _, match := range []string{compType, ""} {
+		for _, stagger := range s {
+			if strings.EqualFold(stagger.ComponentType, match) {
+				return stagger, true
+			}
+		}
+	}
+	return PowerOnStagger{}, false
+}
+
+func (s PowerOnStaggerSlice) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *PowerOnStaggerSlice) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &s)
+}
+
+// ValidatePowerOnStaggers normalizes and checks each of staggers. Only one
+// may be given per component type.
+func ValidatePowerOnStaggers(staggers []PowerOnStagger) error {
+	seen := make(map[string]bool)
+	for i := range staggers {
+		if err := staggers[i].normalize(); err != nil {
+			return err
+		}
+		if seen[staggers[i].ComponentType] {
+			return fmt.Errorf("more than one power on stagger for componentType '%s'", staggers[i].ComponentType)
+		}
+		seen[staggers[i].ComponentType] = true
+	}
+	return nil
+}
+
+// ParsePowerOnStagger parses a power on stagger written as
+// componentType:groupBy=count/delaySeconds, where either the component type
+// or the grouping may be left out, e.g. Node:pdu=4/2, Chassis=1/30, or
+// :cabinet=16/5.
+func ParsePowerOnStagger(spec string) (PowerOnStagger, error) {
+	var s PowerOnStagger
+	target, spacing, ok := strings.Cut(spec, "=")
+	if !ok {
+		return s, fmt.Errorf("invalid power on stagger %s, must be componentType:groupBy=count/delaySeconds", spec)
+	}
+	count, delay, ok := strings.Cut(spacing, "/")
+	if !ok {
+		return s, fmt.Errorf("invalid power on stagger %s, must be componentType:groupBy=count/delaySeconds", spec)
+	}
+	s.ComponentType, s.GroupBy, _ = strings.Cut(target, ":")
+	var err error
+	s.Count, err = strconv.Atoi(count)
+	if err != nil {
+		return s, fmt.Errorf("invalid power on stagger %s: count must be a number", spec)
+	}
+	s.DelaySeconds, err = strconv.Atoi(delay)
+	if err != nil {
+		return s, fmt.Errorf("invalid power on stagger %s: delaySeconds must be a number", spec)
+	}
+	err = s.normalize()
+	return s, err
+}
@@ -0,0 +1,77 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PowerOnStaggerTS struct {
+	suite.Suite
+}
+
+func (suite *PowerOnStaggerTS) TestParsePowerOnStagger() {
+	for spec, want := range map[string]PowerOnStagger{
+		"Node:pdu=4/2":     {ComponentType: "Node", GroupBy: PowerOnStaggerByPDU, Count: 4, DelaySeconds: 2},
+		"chassis=1/30":     {ComponentType: "Chassis", GroupBy: PowerOnStaggerByCabinet, Count: 1, DelaySeconds: 30},
+		":Cabinet=16/5":    {GroupBy: PowerOnStaggerByCabinet, Count: 16, DelaySeconds: 5},
+		"RouterModule=2/0": {ComponentType: "RouterModule", GroupBy: PowerOnStaggerByCabinet, Count: 2},
+	} {
+		s, err := ParsePowerOnStagger(spec)
+		suite.Require().NoError(err, spec)
+		suite.Equal(want, s, spec)
+	}
+	for _, spec := range []string{
+		"Node",
+		"Node=4",
+		"Node=four/2",
+		"Node=4/soon",
+		"Node=0/2",
+		"Node=4/-1",
+		"Toaster=4/2",
+		"Node:rack=4/2",
+	} {
+		_, err := ParsePowerOnStagger(spec)
+		suite.Error(err, spec)
+	}
+}
+
+func (suite *PowerOnStaggerTS) TestLookup() {
+	staggers := PowerOnStaggerSlice{
+		{Count: 16, DelaySeconds: 5},
+		{ComponentType: "Node", Count: 4, DelaySeconds: 2},
+	}
+	s, ok := staggers.Lookup("Node")
+	suite.True(ok)
+	suite.Equal(4, s.Count)
+	s, ok = staggers.Lookup("Chassis")
+	suite.True(ok)
+	suite.Equal(16, s.Count)
+
+	_, ok = PowerOnStaggerSlice{{ComponentType: "Node", Count: 4, DelaySeconds: 2}}.Lookup("Chassis")
+	suite.False(ok)
+}
+
+func (suite *PowerOnStaggerTS) TestToTransition() {
+	tr, err := ToTransition(TransitionParameter{
+		Operation:      "On",
+		PowerOnStagger: []PowerOnStagger{{ComponentType: "node", GroupBy: "PDU", Count: 4, DelaySeconds: 2}},
+	}, 5)
+	suite.Require().NoError(err)
+	suite.Equal(PowerOnStaggerSlice{{ComponentType: "Node", GroupBy: PowerOnStaggerByPDU, Count: 4, DelaySeconds: 2}}, tr.PowerOnStagger)
+
+	_, err = ToTransition(TransitionParameter{
+		Operation: "On",
+		PowerOnStagger: []PowerOnStagger{
+			{ComponentType: "Node", Count: 4, DelaySeconds: 2},
+			{ComponentType: "node", Count: 8, DelaySeconds: 1},
+		},
+	}, 5)
+	suite.Error(err)
+}
+
+func TestPowerOnStaggerSuite(t *testing.T) {
+	suite.Run(t, new(PowerOnStaggerTS))
+}
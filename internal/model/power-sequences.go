@@ -0,0 +1,58 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+///////////////////////////
+// Power Sequence Definitions
+///////////////////////////
+
+const (
+	// PowerSequenceSourceBuiltIn is the sequence compiled into PCS.
+	PowerSequenceSourceBuiltIn = "built-in"
+	// PowerSequenceSourceConfig is a sequence loaded from the power sequences file at startup.
+	PowerSequenceSourceConfig = "config"
+	// PowerSequenceSourceAPI is a sequence created through the API and kept in storage.
+	PowerSequenceSourceAPI = "api"
+)
+
+// PowerSequence is a named, ordered list of power actions to apply to sets of
+// component types during a transition.
+type PowerSequence struct {
+	Name        string                 `json:"name" db:"name"`
+	Description string                 `json:"description,omitempty" db:"description"`
+	Steps       PowerSequenceStepSlice `json:"steps" db:"steps"`
+	// Source is derived from where the sequence was found and is never stored.
+	Source string `json:"source,omitempty" db:"-"`
+}
+
+// PowerSequenceStep applies Action to every component of the listed types
+// before moving on to the next step.
+type PowerSequenceStep struct {
+	Action         string   `json:"action"`
+	ComponentTypes []string `json:"componentTypes"`
+}
+
+type PowerSequenceStepSlice []PowerSequenceStep
+
+func (p PowerSequenceStepSlice) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *PowerSequenceStepSlice) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &p)
+}
+
+// PowerSequenceArray is both the response for listing power sequences and
+// the format of the power sequences file.
+type PowerSequenceArray struct {
+	PowerSequences []PowerSequence `json:"powerSequences"`
+}
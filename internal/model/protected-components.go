@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+const (
+	ProtectedSelectorXname = "xname"
+	ProtectedSelectorType  = "type"
+	ProtectedSelectorGroup = "group"
+	ProtectedSelectorRole  = "role"
+
+	// DefaultForceScope is the token scope needed to force a transition on
+	// protected components, unless configured otherwise.
+	DefaultForceScope = "pcs:force"
+)
+
+// ProtectedSelector selects components that transitions may only power off
+// or restart when forced: an xname, every component of a type, the members
+// of an HSM group, or the components with an HSM role and optionally
+// subrole.
+type ProtectedSelector struct {
+	Xname         string
+	ComponentType xnametypes.HMSType
+	Group         string
+	Role          string
+	SubRole       string
+}
+
+// ParseProtectedSelector parses a protected selector written as kind=value,
+// e.g. xname=x3000c0s1b0n0, type=CabinetPDUPowerConnector, group=mgmt,
+// role=Management, or role=Management/Master.
+func ParseProtectedSelector(spec string) (ProtectedSelector, error) {
+	var s ProtectedSelector
+	kind, value, ok := strings.Cut(spec, "=")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return s, fmt.Errorf("invalid protected selector %s, must be kind=value", spec)
+	}
+	switch kind {
+	case ProtectedSelectorXname:
+		s.Xname = xnametypes.NormalizeHMSCompID(value)
+		if !xnametypes.IsHMSCompIDValid(s.Xname) {
+			return s, fmt.Errorf("invalid protected selector %s: invalid xname", spec)
+		}
+	case ProtectedSelectorType:
+		compType := xnametypes.VerifyNormalizeType(value)
+		if compType == "" {
+			return s, fmt.Errorf("invalid protected selector %s: invalid component type", spec)
+		}
+		s.ComponentType = xnametypes.HMSType(compType)
+	case ProtectedSelectorGroup:
+		s.Group = value
+	case ProtectedSelectorRole:
+		s.Role, s.SubRole, _ = strings.Cut(value, "/")
+		if s.Role == "" {
+			return s, fmt.Errorf("invalid protected selector %s: role is empty", spec)
+		}
+	default:
+		return s, fmt.Errorf("invalid protected selector %s, kind must be %s, %s, %s, or %s", spec,
+			ProtectedSelectorXname, ProtectedSelectorType, ProtectedSelectorGroup, ProtectedSelectorRole)
+	}
+	return s, nil
+}
+
+// String writes the selector as ParseProtectedSelector reads it.
+func (s ProtectedSelector) String() string {
+	switch {
+	case s.Xname != "":
+		return ProtectedSelectorXname + "=" + s.Xname
+	case s.ComponentType != "":
+		return ProtectedSelectorType + "=" + s.ComponentType.String()
+	case s.Group != "":
+		return ProtectedSelectorGroup + "=" + s.Group
+	case s.SubRole != "":
+		return ProtectedSelectorRole + "=" + s.Role + "/" + s.SubRole
+	}
+	return ProtectedSelectorRole + "=" + s.Role
+}
@@ -0,0 +1,76 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProtectedComponentsTS struct {
+	suite.Suite
+}
+
+func (suite *ProtectedComponentsTS) TestParseProtectedSelector() {
+	tests := map[string]ProtectedSelector{
+		"xname=X3000C0S1B0N0":             {Xname: "x3000c0s1b0n0"},
+		"type=cabinetpdupowerconnector":   {ComponentType: xnametypes.CabinetPDUPowerConnector},
+		"group=mgmt":                      {Group: "mgmt"},
+		"role=Management":                 {Role: "Management"},
+		" Role = Management/Master ":      {Role: "Management", SubRole: "Master"},
+		"type=CabinetPDUPowerConnector  ": {ComponentType: xnametypes.CabinetPDUPowerConnector},
+	}
+	for spec, want := range tests {
+		s, err := ParseProtectedSelector(spec)
+		suite.Require().NoError(err, spec)
+		suite.Equal(want, s, spec)
+		again, err := ParseProtectedSelector(s.String())
+		suite.Require().NoError(err, s.String())
+		suite.Equal(s, again, s.String())
+	}
+
+	for _, spec := range []string{"", "xname", "xname=", "xname=rack1", "type=Rack", "role=/Master", "label=mgmt"} {
+		_, err := ParseProtectedSelector(spec)
+		suite.Error(err, spec)
+	}
+}
+
+func (suite *ProtectedComponentsTS) TestHasScope() {
+	r := Requester{Scopes: []string{"openid", DefaultForceScope}}
+	suite.True(r.HasScope(DefaultForceScope))
+	suite.False(r.HasScope("pcs:admin"))
+	suite.False(Requester{}.HasScope(DefaultForceScope))
+}
+
+func (suite *ProtectedComponentsTS) TestForce() {
+	params := TransitionParameter{
+		Operation: "Off",
+		Location:  []LocationParameter{{Xname: "x3000c0s1b0n0"}},
+		Force:     true,
+	}
+	tr, err := ToTransition(params, 5)
+	suite.Require().NoError(err)
+	suite.True(tr.Force)
+	suite.True(ToTransitionResp(tr, nil, false).Force)
+
+	// Later occurrences are checked against protected components.
+	params.CronSchedule = "0 * * * *"
+	tr, err = ToTransition(params, 5)
+	suite.Require().NoError(err)
+	suite.True(tr.Force)
+	next, ok := NextScheduledTransition(tr, 5)
+	suite.Require().True(ok)
+	suite.False(next.Force)
+
+	params.CronSchedule = ""
+	params.Force = false
+	tr, err = ToTransition(params, 5)
+	suite.Require().NoError(err)
+	suite.False(tr.Force)
+}
+
+func TestProtectedComponentsSuite(t *testing.T) {
+	suite.Run(t, new(ProtectedComponentsTS))
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// RedfishEventSubscription holds the secret PCS gave a BMC when subscribing
+// it to events. The BMC sends Context back with every event, which tells
+// events from the BMC apart from ones anybody could make up, since the BMC
+// FQDN is easily guessed.
+type RedfishEventSubscription struct {
+	BMC        string    `json:"bmc" db:"bmc"`
+	Secret     string    `json:"secret" db:"secret"`
+	CreateTime time.Time `json:"createTime" db:"created"`
+}
+
+// NewRedfishEventSubscription creates a subscription for the BMC at fqdn
+// with a new random secret.
+func NewRedfishEventSubscription(fqdn string) (RedfishEventSubscription, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return RedfishEventSubscription{}, err
+	}
+	return RedfishEventSubscription{
+		BMC:        fqdn,
+		Secret:     hex.EncodeToString(secret),
+		CreateTime: time.Now().Truncate(time.Microsecond),
+	}, nil
+}
+
+// Context is the subscription context the BMC is asked to send with its
+// events, the BMC FQDN and the secret separated by a slash.
+func (s RedfishEventSubscription) Context() string {
+	return s.BMC + "/" + s.Secret
+}
+
+// Matches reports whether context is this subscription's context.
+func (s RedfishEventSubscription) Matches(context string) bool {
+	return s.Secret != "" && subtle.ConstantTimeCompare([]byte(context), []byte(s.Context())) == 1
+}
+
+// RedfishEventContextBMC returns the BMC FQDN in an event's context, or an
+// empty string if it isn't a context PCS hands out.
+func RedfishEventContextBMC(context string) string {
+	fqdn, secret, ok := strings.Cut(context, "/")
+	if !ok || secret == "" {
+		return ""
+	}
+	return fqdn
+}
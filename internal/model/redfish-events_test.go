@@ -0,0 +1,37 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RedfishEventsTS struct {
+	suite.Suite
+}
+
+func (suite *RedfishEventsTS) TestRedfishEventSubscription() {
+	sub, err := NewRedfishEventSubscription("x3000c0s0b0.example.com:8443")
+	suite.Require().NoError(err)
+	suite.Len(sub.Secret, 64)
+	suite.Equal("x3000c0s0b0.example.com:8443/"+sub.Secret, sub.Context())
+	suite.True(sub.Matches(sub.Context()))
+	suite.Equal("x3000c0s0b0.example.com:8443", RedfishEventContextBMC(sub.Context()))
+
+	other, err := NewRedfishEventSubscription(sub.BMC)
+	suite.Require().NoError(err)
+	suite.NotEqual(sub.Secret, other.Secret)
+	for _, context := range []string{"", sub.BMC, sub.BMC + "/", other.Context(), sub.Context() + "0"} {
+		suite.False(sub.Matches(context), context)
+	}
+	suite.False(RedfishEventSubscription{BMC: sub.BMC}.Matches(sub.BMC+"/"), "empty secret")
+
+	suite.Equal("", RedfishEventContextBMC(sub.BMC))
+	suite.Equal("", RedfishEventContextBMC(sub.BMC+"/"))
+}
+
+func TestRedfishEventsSuite(t *testing.T) {
+	suite.Run(t, new(RedfishEventsTS))
+}
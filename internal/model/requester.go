@@ -0,0 +1,114 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Requester identifies who asked for a transition or power cap task, from
+// the sub and iss claims of their token. It is empty if PCS isn't
+// configured to require tokens. FreezeOverride is set if the token has a
+// true FreezeOverrideClaim, and Scopes are the token's scopes.
+type Requester struct {
+	Subject        string   `json:"sub,omitempty"`
+	Issuer         string   `json:"iss,omitempty"`
+	FreezeOverride bool     `json:"-"`
+	Scopes         []string `json:"-"`
+}
+
+// HasScope reports whether the requester's token has scope.
+func (r Requester) HasScope(scope string) bool {
+	for _, s := range r.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// toRequesterResp returns nil for an anonymous requester, so it is left out
+// of responses.
+func toRequesterResp(subject string, issuer string) *Requester {
+	if subject == "" && issuer == "" {
+		return nil
+	}
+	return &Requester{Subject: subject, Issuer: issuer}
+}
+
+// Labels are free-form key/value pairs clients attach to a request so they
+// can find it again with a label selector.
+type Labels map[string]string
+
+func (l Labels) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *Labels) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &l)
+}
+
+// ValidateLabels checks that labels can be selected on. Keys can't be empty
+// or contain '='.
+func ValidateLabels(labels Labels) error {
+	for key := range labels {
+		if key == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("invalid label key '%s'", key)
+		}
+	}
+	return nil
+}
+
+// LabelSelector matches records with a label. It is given as "key=value",
+// or "key" to match any value.
+type LabelSelector struct {
+	Key      string
+	Value    string
+	AnyValue bool
+}
+
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	key, value, found := strings.Cut(selector, "=")
+	if key == "" {
+		return LabelSelector{}, fmt.Errorf("invalid label selector '%s'", selector)
+	}
+	return LabelSelector{Key: key, Value: value, AnyValue: !found}, nil
+}
+
+func ParseLabelSelectors(selectors []string) ([]LabelSelector, error) {
+	var parsed []LabelSelector
+	for _, selector := range selectors {
+		s, err := ParseLabelSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, s)
+	}
+	return parsed, nil
+}
+
+func (s LabelSelector) Matches(labels Labels) bool {
+	value, ok := labels[s.Key]
+	return ok && (s.AnyValue || value == s.Value)
+}
+
+// MatchesLabels reports whether labels satisfy every selector.
+func MatchesLabels(selectors []LabelSelector, labels Labels) bool {
+	for _, s := range selectors {
+		if !s.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
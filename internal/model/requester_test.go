@@ -0,0 +1,75 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RequesterTS struct {
+	suite.Suite
+}
+
+func (suite *RequesterTS) TestLabelSelectors() {
+	labels := Labels{"rack": "x1003", "ticket": ""}
+	for selector, want := range map[string]bool{
+		"rack":        true,
+		"rack=x1003":  true,
+		"rack=x1004":  false,
+		"ticket":      true,
+		"ticket=":     true,
+		"cabinet":     false,
+		"cabinet=foo": false,
+	} {
+		s, err := ParseLabelSelector(selector)
+		suite.Require().NoError(err)
+		suite.Equal(want, s.Matches(labels), "selector %s", selector)
+	}
+	_, err := ParseLabelSelector("=x1003")
+	suite.Error(err)
+
+	selectors, err := ParseLabelSelectors([]string{"rack=x1003", "ticket"})
+	suite.Require().NoError(err)
+	suite.True(MatchesLabels(selectors, labels))
+	suite.False(MatchesLabels(selectors, Labels{"rack": "x1003"}))
+	suite.True(MatchesLabels(nil, nil))
+}
+
+func (suite *RequesterTS) TestValidateLabels() {
+	suite.NoError(ValidateLabels(nil))
+	suite.NoError(ValidateLabels(Labels{"rack": "x1003"}))
+	suite.Error(ValidateLabels(Labels{"": "x1003"}))
+	suite.Error(ValidateLabels(Labels{"rack=x1003": ""}))
+}
+
+func (suite *RequesterTS) TestTransitionRequester() {
+	param := TransitionParameter{
+		Operation: "Off",
+		Location:  []LocationParameter{{Xname: "x1003c0s0b0n0"}},
+		Reason:    "cabinet maintenance",
+		Labels:    Labels{"rack": "x1003"},
+		Requester: Requester{Subject: "admin", Issuer: "https://auth.example.com"},
+	}
+	tr, err := ToTransition(param, 10)
+	suite.Require().NoError(err)
+	rsp := ToTransitionResp(tr, nil, false)
+	suite.Equal(&param.Requester, rsp.Requester)
+	suite.Equal("cabinet maintenance", rsp.Reason)
+	suite.Equal(Labels{"rack": "x1003"}, rsp.Labels)
+
+	// Without auth there is no requester to report.
+	param.Requester = Requester{}
+	tr, err = ToTransition(param, 10)
+	suite.Require().NoError(err)
+	suite.Nil(ToTransitionResp(tr, nil, false).Requester)
+
+	param.Labels = Labels{"": "x1003"}
+	_, err = ToTransition(param, 10)
+	suite.Error(err)
+}
+
+func TestRequesterSuite(t *testing.T) {
+	suite.Run(t, new(RequesterTS))
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// TaskDeadlineWaitForBMC is the TaskDeadline action for waiting on BMCs to
+// become ready after the components above them are powered on or after they
+// are restarted.
+const TaskDeadlineWaitForBMC = "waitforbmc"
+
+// DefaultWaitForBMCDeadline is how long, in minutes, to wait for BMCs to
+// become ready when no TaskDeadline covers them.
+const DefaultWaitForBMCDeadline = 5
+
+// The actions a TaskDeadline may be set for.
+var taskDeadlineActions = []string{
+	"gracefulshutdown",
+	"forceoff",
+	"gracefulrestart",
+	"on",
+	TaskDeadlineWaitForBMC,
+}
+
+// TaskDeadline is the time limit, in minutes, for the components of a type
+// to finish a power sequence action. Either Action or ComponentType may be
+// left empty to cover every action or component type.
+type TaskDeadline struct {
+	Action        string `json:"action,omitempty"`
+	ComponentType string `json:"componentType,omitempty"`
+	Minutes       int    `json:"minutes"`
+}
+
+// Normalizes the action and component type and checks that the deadline
+// covers something and is positive.
+func (d *TaskDeadline) normalize() error {
+	if d.Action == "" && d.ComponentType == "" {
+		return errors.New("task deadline needs an action, a componentType, or both")
+	}
+	if d.Action != "" {
+		action := strings.ToLower(d.Action)
+		valid := false
+		for _, a := range taskDeadlineActions {
+			if action == a {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid task deadline action %s, must be one of %s",
+				d.Action, strings.Join(taskDeadlineActions, ", "))
+		}
+		d.Action = action
+	}
+	if d.ComponentType != "" {
+		compType := xnametypes.VerifyNormalizeType(d.ComponentType)
+		if compType == "" {
+			return fmt.Errorf("invalid task deadline componentType %s", d.ComponentType)
+		}
+		d.ComponentType = compType
+	}
+	if d.Minutes < 1 {
+		return errors.New("task deadline minutes must be at least 1")
+	}
+	return nil
+}
+
+type TaskDeadlineSlice []TaskDeadline
+
+// Lookup returns the minutes of the deadline that most specifically covers
+// compType components doing action: one for both, then one for the action,
+// then one for the component type. Returns false if none do.
+func (s TaskDeadlineSlice) Lookup(action string, compType string) (int, bool) {
+	for _, match := range []struct{ action, compType string }{
+		{action, compType},
+		{action, ""},
+		{"", compType},
+	} {
+		for _, d := range s {
+			if d.Action == match.action && strings.EqualFold(d.ComponentType, match.compType) {
+				return d.Minutes, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (s TaskDeadlineSlice) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *TaskDeadlineSlice) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &s)
+}
+
+// ValidateTaskDeadlines normalizes and checks each of deadlines.
+func ValidateTaskDeadlines(deadlines []TaskDeadline) error {
+	for i := range deadlines {
+		if err := deadlines[i].normalize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseTaskDeadline parses a task deadline written as
+// action:componentType=minutes, where either the action or the component
+// type may be left out, e.g. gracefulshutdown:Node=10, forceoff=2, or
+// :Chassis=1.
+func ParseTaskDeadline(spec string) (TaskDeadline, error) {
+	var d TaskDeadline
+	target, minutes, ok := strings.Cut(spec, "=")
+	if !ok {
+		return d, fmt.Errorf("invalid task deadline %s, must be action:componentType=minutes", spec)
+	}
+	d.Action, d.ComponentType, _ = strings.Cut(target, ":")
+	var err error
+	d.Minutes, err = strconv.Atoi(minutes)
+	if err != nil {
+		return d, fmt.Errorf("invalid task deadline %s: minutes must be a number", spec)
+	}
+	err = d.normalize()
+	return d, err
+}
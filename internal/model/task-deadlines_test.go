@@ -0,0 +1,79 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskDeadlinesTS struct {
+	suite.Suite
+}
+
+func (suite *TaskDeadlinesTS) TestParseTaskDeadline() {
+	for spec, want := range map[string]TaskDeadline{
+		"gracefulshutdown:Node=10": {Action: "gracefulshutdown", ComponentType: "Node", Minutes: 10},
+		"ForceOff=2":               {Action: "forceoff", Minutes: 2},
+		":chassis=1":               {ComponentType: "Chassis", Minutes: 1},
+		"waitForBMC=8":             {Action: TaskDeadlineWaitForBMC, Minutes: 8},
+	} {
+		d, err := ParseTaskDeadline(spec)
+		suite.Require().NoError(err, spec)
+		suite.Equal(want, d, spec)
+	}
+	for _, spec := range []string{
+		"forceoff",
+		"forceoff=soon",
+		"forceoff=0",
+		"reboot=5",
+		"on:Toaster=5",
+		"=5",
+	} {
+		_, err := ParseTaskDeadline(spec)
+		suite.Error(err, spec)
+	}
+}
+
+func (suite *TaskDeadlinesTS) TestLookup() {
+	deadlines := TaskDeadlineSlice{
+		{ComponentType: "Chassis", Minutes: 1},
+		{Action: "gracefulshutdown", Minutes: 7},
+		{Action: "gracefulshutdown", ComponentType: "Node", Minutes: 10},
+	}
+	for _, tc := range []struct {
+		action   string
+		compType string
+		minutes  int
+		ok       bool
+	}{
+		{"gracefulshutdown", "Node", 10, true},
+		{"gracefulshutdown", "Chassis", 7, true},
+		{"forceoff", "Chassis", 1, true},
+		{"forceoff", "Node", 0, false},
+	} {
+		minutes, ok := deadlines.Lookup(tc.action, tc.compType)
+		suite.Equal(tc.ok, ok, "%s %s", tc.action, tc.compType)
+		suite.Equal(tc.minutes, minutes, "%s %s", tc.action, tc.compType)
+	}
+}
+
+func (suite *TaskDeadlinesTS) TestToTransition() {
+	tr, err := ToTransition(TransitionParameter{
+		Operation:     "Off",
+		TaskDeadlines: []TaskDeadline{{Action: "GracefulShutdown", ComponentType: "node", Minutes: 10}},
+	}, 5)
+	suite.Require().NoError(err)
+	suite.Equal(TaskDeadlineSlice{{Action: "gracefulshutdown", ComponentType: "Node", Minutes: 10}}, tr.TaskDeadlines)
+
+	_, err = ToTransition(TransitionParameter{
+		Operation:     "Off",
+		TaskDeadlines: []TaskDeadline{{Minutes: 10}},
+	}, 5)
+	suite.Error(err)
+}
+
+func TestTaskDeadlinesSuite(t *testing.T) {
+	suite.Run(t, new(TaskDeadlinesTS))
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// TaskTimelineEntry is the state of a transition task after one of its
+// changes.
+type TaskTimelineEntry struct {
+	Time           time.Time `json:"time"`
+	TaskState      string    `json:"taskState"`
+	Action         string    `json:"action,omitempty"`
+	TaskStatus     string    `json:"taskStatus"`
+	TaskStatusDesc string    `json:"taskStatusDescription"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// TaskTimeline is every change made to a transition task, oldest first.
+type TaskTimeline []TaskTimelineEntry
+
+func (t TaskTimeline) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *TaskTimeline) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &t)
+}
+
+// TaskTierDuration is how long a task spent in one tier of its transition's
+// power sequence. Tier is the index of the tier, as in TransitionPlanStep.
+type TaskTierDuration struct {
+	Tier    int     `json:"tier"`
+	Action  string  `json:"action"`
+	Seconds float64 `json:"seconds"`
+}
+
+// RecordTimeline appends the task's current state, at now, to its timeline
+// unless nothing has changed since the last entry. action is the power action
+// the task is performing, if any.
+func (t *TransitionTask) RecordTimeline(now time.Time, action string) {
+	entry := TaskTimelineEntry{
+		Time:           now,
+		TaskState:      t.State.String(),
+		Action:         action,
+		TaskStatus:     t.Status,
+		TaskStatusDesc: t.StatusDesc,
+		Error:          t.Error,
+	}
+	if len(t.Timeline) > 0 {
+		last := t.Timeline[len(t.Timeline)-1]
+		last.Time = now
+		if last == entry {
+			return
+		}
+	}
+	t.Timeline = append(t.Timeline, entry)
+}
+
+// TierDurations returns how long the task spent in each tier it went
+// through. tierOf returns the tier an action was performed in, or false if
+// it wasn't part of one. A tier lasts from its first entry until the next
+// tier starts or, for the last tier, until its last entry.
+func (t TaskTimeline) TierDurations(tierOf func(action string) (int, bool)) []TaskTierDuration {
+	var (
+		durations []TaskTierDuration
+		start     time.Time
+	)
+	for _, entry := range t {
+		if entry.Action == "" {
+			continue
+		}
+		tier, ok := tierOf(entry.Action)
+		if !ok {
+			continue
+		}
+		if len(durations) > 0 {
+			last := &durations[len(durations)-1]
+			last.Seconds = entry.Time.Sub(start).Seconds()
+			if last.Tier == tier {
+				continue
+			}
+		}
+		durations = append(durations, TaskTierDuration{Tier: tier, Action: entry.Action})
+		start = entry.Time
+	}
+	return durations
+}
@@ -0,0 +1,84 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskTimelineTS struct {
+	suite.Suite
+}
+
+func (suite *TaskTimelineTS) TestRecordTimeline() {
+	start := time.Now()
+	task := NewTransitionTask(uuid.New(), Operation_Off)
+	task.StatusDesc = "Gathering data"
+	task.RecordTimeline(start, "")
+	// Nothing changed
+	task.RecordTimeline(start.Add(time.Second), "")
+	suite.Require().Len(task.Timeline, 1)
+	suite.Equal(start, task.Timeline[0].Time)
+	suite.Equal(TaskState_GatherData.String(), task.Timeline[0].TaskState)
+
+	task.State = TaskState_Sending
+	task.StatusDesc = "Applying transition, gracefulshutdown"
+	task.RecordTimeline(start.Add(2*time.Second), "gracefulshutdown")
+	suite.Require().Len(task.Timeline, 2)
+	suite.Equal(TaskTimelineEntry{
+		Time:           start.Add(2 * time.Second),
+		TaskState:      TaskState_Sending.String(),
+		Action:         "gracefulshutdown",
+		TaskStatus:     TransitionTaskStatusNew,
+		TaskStatusDesc: "Applying transition, gracefulshutdown",
+	}, task.Timeline[1])
+}
+
+func (suite *TaskTimelineTS) TestTierDurations() {
+	start := time.Now()
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+	timeline := TaskTimeline{
+		{Time: at(0)},
+		{Time: at(1), Action: "gracefulshutdown"},
+		{Time: at(5), Action: "gracefulshutdown"},
+		{Time: at(301), Action: "gracefulshutdown"},
+		{Time: at(302), Action: "forceoff"},
+		{Time: at(320), Action: "forceoff"},
+		{Time: at(400), Action: "unknown"},
+	}
+	tiers := map[string]int{"gracefulshutdown": 0, "forceoff": 1}
+	durations := timeline.TierDurations(func(action string) (int, bool) {
+		tier, ok := tiers[action]
+		return tier, ok
+	})
+	suite.Equal([]TaskTierDuration{
+		{Tier: 0, Action: "gracefulshutdown", Seconds: 301},
+		{Tier: 1, Action: "forceoff", Seconds: 18},
+	}, durations)
+
+	suite.Empty(TaskTimeline{}.TierDurations(func(string) (int, bool) { return 0, true }))
+}
+
+func (suite *TaskTimelineTS) TestScan() {
+	var timeline TaskTimeline
+	suite.Require().NoError(timeline.Scan(nil))
+	suite.Nil(timeline)
+
+	want := TaskTimeline{{Time: time.Now().UTC(), TaskState: "Sending Command", Action: "on"}}
+	value, err := want.Value()
+	suite.Require().NoError(err)
+	suite.Require().NoError(timeline.Scan(value))
+	suite.Require().Len(timeline, 1)
+	suite.True(want[0].Time.Equal(timeline[0].Time))
+	suite.Equal(want[0].Action, timeline[0].Action)
+}
+
+func TestTaskTimelineSuite(t *testing.T) {
+	suite.Run(t, new(TaskTimelineTS))
+}
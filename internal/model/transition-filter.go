@@ -0,0 +1,207 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TransitionOrderAsc  = "asc"
+	TransitionOrderDesc = "desc"
+)
+
+// TransitionFilter selects and orders the transitions returned by
+// GET /transitions. Empty fields match everything.
+type TransitionFilter struct {
+	Statuses   []string
+	Operations []Operation
+	// Xname matches transitions with the component in their location.
+	Xname string
+	// Labels match transitions with every one of the labels.
+	Labels []LabelSelector
+	// CreatedAfter and CreatedBefore are exclusive bounds on CreateTime.
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Order sorts by CreateTime, then TransitionID, ascending or descending.
+	Order string
+	// Limit is the page size. Zero means no limit.
+	Limit int
+	// Cursor continues a previous page, after the transition it names.
+	Cursor *TransitionCursor
+}
+
+// TransitionCursor is the position of a transition in the sort order of
+// GET /transitions.
+type TransitionCursor struct {
+	CreateTime   time.Time
+	TransitionID uuid.UUID
+}
+
+func NewTransitionCursor(tr Transition) TransitionCursor {
+	return TransitionCursor{CreateTime: tr.CreateTime, TransitionID: tr.TransitionID}
+}
+
+// String encodes the cursor as the opaque nextCursor value.
+func (c TransitionCursor) String() string {
+	raw := c.CreateTime.UTC().Format(time.RFC3339Nano) + "/" + c.TransitionID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseTransitionCursor(cursor string) (TransitionCursor, error) {
+	var c TransitionCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	created, id, found := strings.Cut(string(raw), "/")
+	if !found {
+		return c, errors.New("invalid cursor")
+	}
+	c.CreateTime, err = time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	c.TransitionID, err = uuid.Parse(id)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
+// ToTransitionFilter builds a filter from the GET /transitions query
+// parameters.
+func ToTransitionFilter(params url.Values) (TransitionFilter, error) {
+	filter := TransitionFilter{Order: TransitionOrderAsc}
+	for _, status := range params["status"] {
+		status = strings.ToLower(status)
+		switch status {
+		case TransitionStatusNew, TransitionStatusInProgress, TransitionStatusCompleted,
+			TransitionStatusAborted, TransitionStatusAbortSignaled, TransitionStatusHalted,
+			TransitionStatusScheduled, TransitionStatusPaused, TransitionStatusQueued:
+			filter.Statuses = append(filter.Statuses, status)
+		default:
+			return filter, fmt.Errorf("invalid status %s", status)
+		}
+	}
+	for _, op := range params["operation"] {
+		operation, err := ToOperationFilter(op)
+		if err != nil {
+			return filter, err
+		}
+		filter.Operations = append(filter.Operations, operation)
+	}
+	filter.Xname = params.Get("xname")
+	labels, err := ParseLabelSelectors(params["label"])
+	if err != nil {
+		return filter, err
+	}
+	filter.Labels = labels
+	for name, bound := range map[string]**time.Time{
+		"createdAfter":  &filter.CreatedAfter,
+		"createdBefore": &filter.CreatedBefore,
+	} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, must be an RFC 3339 time: %w", name, err)
+			}
+			*bound = &t
+		}
+	}
+	if order := strings.ToLower(params.Get("order")); order != "" {
+		if order != TransitionOrderAsc && order != TransitionOrderDesc {
+			return filter, fmt.Errorf("invalid order %s, must be asc or desc", order)
+		}
+		filter.Order = order
+	}
+	if limit := params.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return filter, fmt.Errorf("invalid limit %s, must be a positive integer", limit)
+		}
+		filter.Limit = l
+	}
+	if cursor := params.Get("cursor"); cursor != "" {
+		c, err := ParseTransitionCursor(cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = &c
+	}
+	return filter, nil
+}
+
+// Matches reports whether tr passes the filter's criteria. It doesn't
+// consider the cursor.
+func (f TransitionFilter) Matches(tr Transition) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, tr.Status) {
+		return false
+	}
+	if len(f.Operations) > 0 && !slices.Contains(f.Operations, tr.Operation) {
+		return false
+	}
+	if !MatchesLabels(f.Labels, tr.Labels) {
+		return false
+	}
+	if f.CreatedAfter != nil && !tr.CreateTime.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !tr.CreateTime.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.Xname != "" {
+		found := false
+		for _, loc := range tr.Location {
+			if loc.Xname == f.Xname {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// less reports whether a sorts before b in the filter's order.
+func (f TransitionFilter) less(a, b TransitionCursor) bool {
+	if f.Order == TransitionOrderDesc {
+		a, b = b, a
+	}
+	if a.CreateTime.Equal(b.CreateTime) {
+		return a.TransitionID.String() < b.TransitionID.String()
+	}
+	return a.CreateTime.Before(b.CreateTime)
+}
+
+// PageTransitions sorts transitions that already match the filter and
+// returns the page after the filter's cursor, plus the cursor for the next
+// page if there is one. It is for storage backends that can't do this in
+// their queries.
+func (f TransitionFilter) PageTransitions(transitions []Transition) ([]Transition, string) {
+	sort.Slice(transitions, func(i, j int) bool {
+		return f.less(NewTransitionCursor(transitions[i]), NewTransitionCursor(transitions[j]))
+	})
+	start := 0
+	if f.Cursor != nil {
+		start = sort.Search(len(transitions), func(i int) bool {
+			return f.less(*f.Cursor, NewTransitionCursor(transitions[i]))
+		})
+	}
+	page := transitions[start:]
+	if f.Limit > 0 && len(page) > f.Limit {
+		page = page[:f.Limit]
+		return page, NewTransitionCursor(page[len(page)-1]).String()
+	}
+	return page, ""
+}
@@ -0,0 +1,127 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransitionFilterTS struct {
+	suite.Suite
+}
+
+func (suite *TransitionFilterTS) TestToTransitionFilter() {
+	params := url.Values{
+		"status":       {"completed", "Halted"},
+		"operation":    {"on", "Soft-Restart"},
+		"xname":        {"x0c0s1b0n0"},
+		"createdAfter": {"2025-01-01T00:00:00Z"},
+		"order":        {"desc"},
+		"limit":        {"10"},
+	}
+	filter, err := ToTransitionFilter(params)
+	suite.Require().NoError(err)
+	suite.Equal([]string{TransitionStatusCompleted, TransitionStatusHalted}, filter.Statuses)
+	suite.Equal([]Operation{Operation_On, Operation_SoftRestart}, filter.Operations)
+	suite.Equal("x0c0s1b0n0", filter.Xname)
+	suite.Equal(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedAfter)
+	suite.Nil(filter.CreatedBefore)
+	suite.Equal(TransitionOrderDesc, filter.Order)
+	suite.Equal(10, filter.Limit)
+	suite.Nil(filter.Cursor)
+
+	filter, err = ToTransitionFilter(url.Values{})
+	suite.Require().NoError(err)
+	suite.Equal(TransitionFilter{Order: TransitionOrderAsc}, filter)
+
+	for _, bad := range []url.Values{
+		{"status": {"done"}},
+		{"operation": {"sideways"}},
+		{"createdBefore": {"yesterday"}},
+		{"order": {"random"}},
+		{"limit": {"0"}},
+		{"limit": {"ten"}},
+		{"cursor": {"not-a-cursor"}},
+	} {
+		_, err = ToTransitionFilter(bad)
+		suite.Error(err, "expected an error for %v", bad)
+	}
+}
+
+func (suite *TransitionFilterTS) TestTransitionCursor() {
+	cursor := TransitionCursor{
+		CreateTime:   time.Date(2025, time.March, 4, 5, 6, 7, 891, time.UTC),
+		TransitionID: uuid.New(),
+	}
+	parsed, err := ParseTransitionCursor(cursor.String())
+	suite.Require().NoError(err)
+	suite.True(cursor.CreateTime.Equal(parsed.CreateTime))
+	suite.Equal(cursor.TransitionID, parsed.TransitionID)
+}
+
+func (suite *TransitionFilterTS) TestMatches() {
+	created := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+	tr := Transition{
+		Operation:  Operation_Off,
+		Status:     TransitionStatusCompleted,
+		CreateTime: created,
+		Location:   []LocationParameter{{Xname: "x0c0s1b0n0"}, {Xname: "x0c0s2b0n0"}},
+	}
+	before := created.Add(time.Hour)
+	after := created.Add(-time.Hour)
+	suite.True(TransitionFilter{}.Matches(tr))
+	suite.True(TransitionFilter{
+		Statuses:      []string{TransitionStatusAborted, TransitionStatusCompleted},
+		Operations:    []Operation{Operation_Off},
+		Xname:         "x0c0s2b0n0",
+		CreatedAfter:  &after,
+		CreatedBefore: &before,
+	}.Matches(tr))
+	suite.False(TransitionFilter{Statuses: []string{TransitionStatusNew}}.Matches(tr))
+	suite.False(TransitionFilter{Operations: []Operation{Operation_On}}.Matches(tr))
+	suite.False(TransitionFilter{Xname: "x0c0s3b0n0"}.Matches(tr))
+	suite.False(TransitionFilter{CreatedAfter: &created}.Matches(tr))
+	suite.False(TransitionFilter{CreatedBefore: &created}.Matches(tr))
+}
+
+func (suite *TransitionFilterTS) TestPageTransitions() {
+	base := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var transitions []Transition
+	for i := 4; i >= 0; i-- {
+		transitions = append(transitions, Transition{TransitionID: uuid.New(), CreateTime: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	for _, order := range []string{TransitionOrderAsc, TransitionOrderDesc} {
+		filter := TransitionFilter{Order: order, Limit: 2}
+		var seen []time.Time
+		for pages := 0; pages < 5; pages++ {
+			page, cursor := filter.PageTransitions(append([]Transition{}, transitions...))
+			for _, tr := range page {
+				seen = append(seen, tr.CreateTime)
+			}
+			if cursor == "" {
+				break
+			}
+			c, err := ParseTransitionCursor(cursor)
+			suite.Require().NoError(err)
+			filter.Cursor = &c
+		}
+		suite.Require().Len(seen, 5, "order %s", order)
+		for i := range seen {
+			want := base.Add(time.Duration(i) * time.Minute)
+			if order == TransitionOrderDesc {
+				want = base.Add(time.Duration(4-i) * time.Minute)
+			}
+			suite.True(want.Equal(seen[i]), "order %s, item %d", order, i)
+		}
+	}
+}
+
+func TestTransitionFilterSuite(t *testing.T) {
+	suite.Run(t, new(TransitionFilterTS))
+}
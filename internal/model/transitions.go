@@ -26,9 +26,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/Cray-HPE/hms-xname/xnametypes"
 	"github.com/google/uuid"
 )
 
@@ -42,14 +44,30 @@ const (
 	TransitionStatusCompleted     = "completed"
 	TransitionStatusAborted       = "aborted"
 	TransitionStatusAbortSignaled = "abort-signaled"
+	TransitionStatusHalted        = "halted"
+	TransitionStatusScheduled     = "scheduled"
+	TransitionStatusPaused        = "paused"
+	TransitionStatusQueued        = "queued"
 )
 
+// What to do with a transition requested for components that an active
+// transition already covers.
+const (
+	TransitionConflictReject = "reject"
+	TransitionConflictQueue  = "queue"
+)
+
+// The shortest interval at which a transition may ask BMCs for power states
+// while confirming its actions.
+const MinConfirmPollSeconds = 5
+
 const (
 	TransitionTaskStatusNew         = "new"
 	TransitionTaskStatusInProgress  = "in-progress"
 	TransitionTaskStatusFailed      = "failed"
 	TransitionTaskStatusSucceeded   = "succeeded"
 	TransitionTaskStatusUnsupported = "unsupported"
+	TransitionTaskStatusAborted     = "aborted"
 )
 
 const DefaultTaskDeadline = 5
@@ -63,11 +81,130 @@ type TransitionParameter struct {
 	Operation    string              `json:"operation"`
 	TaskDeadline *int                `json:"taskDeadlineMinutes"`
 	Location     []LocationParameter `json:"location"`
+	// PowerSequence is the name of the power sequence to follow. The
+	// built-in default sequence is used if it is empty.
+	PowerSequence string `json:"powerSequence,omitempty"`
+	// BatchSize limits how many components in a power sequence tier are sent
+	// the tier's action at once. BatchSizePercent does the same as a
+	// percentage of the components in the tier. Zero means no limit.
+	BatchSize        int `json:"batchSize,omitempty"`
+	BatchSizePercent int `json:"batchSizePercent,omitempty"`
+	// BatchDelaySeconds is how long to wait before sending the next batch.
+	BatchDelaySeconds int `json:"batchDelaySeconds,omitempty"`
+	// BatchWaitForConfirmation holds the next batch until every component in
+	// the current batch has confirmed its transition or failed.
+	BatchWaitForConfirmation bool `json:"batchWaitForConfirmation,omitempty"`
+	// MaxFailures halts the transition once more than this many of its tasks
+	// have failed. MaxFailurePercent does the same as a percentage of its
+	// tasks. Zero means no limit.
+	MaxFailures       int `json:"maxFailures,omitempty"`
+	MaxFailurePercent int `json:"maxFailurePercent,omitempty"`
+	// StartAt holds the transition in the scheduled status until the given
+	// time. CronSchedule repeats the transition at each time matching the
+	// five field cron expression, starting no earlier than StartAt.
+	StartAt      *time.Time `json:"startAt,omitempty"`
+	CronSchedule string     `json:"cronSchedule,omitempty"`
+	// CallbackURL is sent a webhook notification when the transition
+	// completes, in addition to any globally registered webhook URLs.
+	CallbackURL string `json:"callbackURL,omitempty"`
+	// Reason and Labels are free-form notes kept with the transition.
+	Reason string `json:"reason,omitempty"`
+	Labels Labels `json:"labels,omitempty"`
+	// ConflictPolicy is what to do if an active transition covers any of the
+	// same components, or their parents or children: reject the request, the
+	// default, or queue the transition until the conflicts finish.
+	ConflictPolicy string `json:"conflictPolicy,omitempty"`
+	// ConfirmPollSeconds, if set, confirms each action by asking the
+	// components' BMCs for their power state this often, rather than
+	// waiting for the power status monitor to update stored power states.
+	// It must be at least MinConfirmPollSeconds.
+	ConfirmPollSeconds int `json:"confirmPollSeconds,omitempty"`
+	// TaskDeadlines override TaskDeadline for particular actions, component
+	// types, or both, and the wait for BMCs to become ready.
+	TaskDeadlines []TaskDeadline `json:"taskDeadlines,omitempty"`
+	// PowerOnStagger overrides the global power on stagger for particular
+	// component types, spacing out the components powered on in each
+	// cabinet or PDU.
+	PowerOnStagger []PowerOnStagger `json:"powerOnStagger,omitempty"`
+	// BudgetPolicy is what to do if powering on the transition's components
+	// would exceed a power budget: reject the request, the default, queue
+	// the transition until there is room, or start only the components that
+	// fit.
+	BudgetPolicy string `json:"budgetPolicy,omitempty"`
+	// Force allows powering off or restarting protected components. The
+	// caller's token must have the force scope.
+	Force bool `json:"force,omitempty"`
+	// Requester is set by the API from the caller's token.
+	Requester Requester `json:"-"`
+	// IdempotencyKey is set by the API from the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
+	// DryRun computes the plan for the transition without reserving
+	// components, storing anything, or sending any Redfish requests.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// TransitionRetryParameter selects which failed tasks of a finished
+// transition to retry. An empty ErrorFilter retries every failed task.
+type TransitionRetryParameter struct {
+	ErrorFilter string `json:"errorFilter,omitempty"`
+	// Force allows powering off or restarting protected components, as for
+	// TransitionParameter.
+	Force bool `json:"force,omitempty"`
+	// Requester is set by the API from the caller's token.
+	Requester Requester `json:"-"`
 }
 
+// LocationParameter is either an xname or a selector for the components in
+// an HSM group or partition, or with a role and optionally subrole. Selectors
+// are replaced with the xnames they match when the transition starts, as are
+// xnames with Expand set, by their descendants of the listed types.
 type LocationParameter struct {
-	Xname     string `json:"xname" db:"xname"`
-	DeputyKey string `json:"deputyKey,omitempty" db:"deputy_key"`
+	Xname     string   `json:"xname" db:"xname"`
+	DeputyKey string   `json:"deputyKey,omitempty" db:"deputy_key"`
+	Group     string   `json:"group,omitempty" db:"group"`
+	Partition string   `json:"partition,omitempty" db:"partition"`
+	Role      string   `json:"role,omitempty" db:"role"`
+	SubRole   string   `json:"subRole,omitempty" db:"sub_role"`
+	Expand    []string `json:"expand,omitempty" db:"expand"`
+}
+
+// IsSelector reports whether the location selects components from HSM
+// rather than naming one.
+func (l LocationParameter) IsSelector() bool {
+	return l.Group != "" || l.Partition != "" || l.Role != "" || l.SubRole != ""
+}
+
+// Checks that each location is either an xname or a selector, and that
+// expanded xnames list valid component types.
+func validateLocations(locations []LocationParameter) error {
+	for _, loc := range locations {
+		if len(loc.Expand) > 0 {
+			if loc.Xname == "" {
+				return errors.New("location expand requires an xname")
+			}
+			if loc.DeputyKey != "" {
+				return fmt.Errorf("location %s cannot have both expand and a deputyKey", loc.Xname)
+			}
+			for _, hmsType := range loc.Expand {
+				if xnametypes.VerifyNormalizeType(hmsType) == "" {
+					return fmt.Errorf("location %s expand has invalid component type %s", loc.Xname, hmsType)
+				}
+			}
+		}
+		if !loc.IsSelector() {
+			continue
+		}
+		if loc.Xname != "" {
+			return fmt.Errorf("location %s cannot also have a group, partition, or role", loc.Xname)
+		}
+		if loc.DeputyKey != "" {
+			return errors.New("deputyKey is only allowed on xname locations")
+		}
+		if loc.SubRole != "" && loc.Role == "" {
+			return errors.New("location subRole requires a role")
+		}
+	}
+	return nil
 }
 
 type LocationParameterSlice []LocationParameter
@@ -94,14 +231,175 @@ func ToTransition(parameter TransitionParameter, expirationTimeMins int) (TR Tra
 		TR.TaskDeadline = DefaultTaskDeadline
 	}
 	TR.Location = parameter.Location
+	TR.PowerSequence = parameter.PowerSequence
+	TR.BatchSize = parameter.BatchSize
+	TR.BatchSizePercent = parameter.BatchSizePercent
+	TR.BatchDelaySeconds = parameter.BatchDelaySeconds
+	TR.BatchWaitForConfirmation = parameter.BatchWaitForConfirmation
+	TR.MaxFailures = parameter.MaxFailures
+	TR.MaxFailurePercent = parameter.MaxFailurePercent
+	TR.CallbackURL = parameter.CallbackURL
+	TR.RequesterSubject = parameter.Requester.Subject
+	TR.RequesterIssuer = parameter.Requester.Issuer
+	TR.FreezeOverride = parameter.Requester.FreezeOverride
+	TR.Force = parameter.Force
+	TR.Reason = parameter.Reason
+	TR.Labels = parameter.Labels
+	TR.ConflictPolicy = strings.ToLower(parameter.ConflictPolicy)
+	TR.ConfirmPollSeconds = parameter.ConfirmPollSeconds
+	TR.TaskDeadlines = parameter.TaskDeadlines
+	TR.PowerOnStagger = parameter.PowerOnStagger
+	TR.BudgetPolicy = strings.ToLower(parameter.BudgetPolicy)
+	if err == nil {
+		err = validateLocations(parameter.Location)
+	}
+	if err == nil {
+		err = validateBatching(parameter)
+	}
+	if err == nil {
+		err = validateFailureThreshold(parameter)
+	}
+	if err == nil {
+		err = ValidateCallbackURL(parameter.CallbackURL)
+	}
+	if err == nil {
+		err = ValidateLabels(parameter.Labels)
+	}
+	if err == nil && TR.ConflictPolicy != "" &&
+		TR.ConflictPolicy != TransitionConflictReject && TR.ConflictPolicy != TransitionConflictQueue {
+		err = fmt.Errorf("invalid conflictPolicy %s, must be reject or queue", parameter.ConflictPolicy)
+	}
+	if err == nil && (parameter.ConfirmPollSeconds < 0 ||
+		(parameter.ConfirmPollSeconds > 0 && parameter.ConfirmPollSeconds < MinConfirmPollSeconds)) {
+		err = fmt.Errorf("confirmPollSeconds must be at least %d", MinConfirmPollSeconds)
+	}
+	if err == nil {
+		err = ValidateTaskDeadlines(TR.TaskDeadlines)
+	}
+	if err == nil {
+		err = ValidatePowerOnStaggers(TR.PowerOnStagger)
+	}
+	if err == nil {
+		err = ValidatePowerBudgetPolicy(TR.BudgetPolicy)
+	}
 	TR.CreateTime = time.Now()
 	TR.AutomaticExpirationTime = time.Now().Add(time.Minute * time.Duration(expirationTimeMins))
 	TR.LastActiveTime = time.Now()
 	TR.Status = TransitionStatusNew
 	TR.TaskIDs = []uuid.UUID{}
+
+	// Scheduled transitions don't start, or expire, until their start time.
+	if err == nil {
+		TR.StartAt, err = nextStartTime(parameter.StartAt, parameter.CronSchedule)
+	}
+	if TR.StartAt != nil {
+		TR.CronSchedule = parameter.CronSchedule
+		TR.Status = TransitionStatusScheduled
+		TR.AutomaticExpirationTime = TR.StartAt.Add(time.Minute * time.Duration(expirationTimeMins))
+	}
 	return
 }
 
+// Works out when a transition requested with startAt and cronSchedule
+// should first start. Returns nil if it should start right away.
+func nextStartTime(startAt *time.Time, cronSchedule string) (*time.Time, error) {
+	now := time.Now()
+	if cronSchedule == "" {
+		if startAt == nil || !startAt.After(now) {
+			return nil, nil
+		}
+		start := *startAt
+		return &start, nil
+	}
+
+	sched, err := ParseCronSchedule(cronSchedule)
+	if err != nil {
+		return nil, err
+	}
+	// Next() looks for times strictly after the one given.
+	from := now
+	if startAt != nil && startAt.After(now) {
+		from = startAt.Add(-time.Minute)
+	}
+	start := sched.Next(from)
+	if start.IsZero() {
+		return nil, fmt.Errorf("cron expression '%s' never matches", cronSchedule)
+	}
+	return &start, nil
+}
+
+// NextScheduledTransition creates the next occurrence of a recurring
+// transition, scheduled for the next time its cron expression matches after
+// now. Returns false if tr isn't recurring. The requester's freeze override
+// and force only cover the occurrence they asked for, so later ones don't
+// get them.
+func NextScheduledTransition(tr Transition, expirationTimeMins int) (Transition, bool) {
+	if tr.CronSchedule == "" {
+		return Transition{}, false
+	}
+	start, err := nextStartTime(nil, tr.CronSchedule)
+	if err != nil || start == nil {
+		return Transition{}, false
+	}
+	next := Transition{
+		TransitionID:             uuid.New(),
+		Operation:                tr.Operation,
+		TaskDeadline:             tr.TaskDeadline,
+		Location:                 tr.Location,
+		CreateTime:               time.Now(),
+		LastActiveTime:           time.Now(),
+		AutomaticExpirationTime:  start.Add(time.Minute * time.Duration(expirationTimeMins)),
+		Status:                   TransitionStatusScheduled,
+		PowerSequence:            tr.PowerSequence,
+		BatchSize:                tr.BatchSize,
+		BatchSizePercent:         tr.BatchSizePercent,
+		BatchDelaySeconds:        tr.BatchDelaySeconds,
+		BatchWaitForConfirmation: tr.BatchWaitForConfirmation,
+		MaxFailures:              tr.MaxFailures,
+		MaxFailurePercent:        tr.MaxFailurePercent,
+		StartAt:                  start,
+		CronSchedule:             tr.CronSchedule,
+		CallbackURL:              tr.CallbackURL,
+		RequesterSubject:         tr.RequesterSubject,
+		RequesterIssuer:          tr.RequesterIssuer,
+		Reason:                   tr.Reason,
+		Labels:                   tr.Labels,
+		ConflictPolicy:           tr.ConflictPolicy,
+		ConfirmPollSeconds:       tr.ConfirmPollSeconds,
+		TaskDeadlines:            tr.TaskDeadlines,
+		PowerOnStagger:           tr.PowerOnStagger,
+		BudgetPolicy:             tr.BudgetPolicy,
+		TaskIDs:                  []uuid.UUID{},
+	}
+	return next, true
+}
+
+func validateBatching(parameter TransitionParameter) error {
+	if parameter.BatchSize < 0 {
+		return errors.New("batchSize cannot be negative")
+	}
+	if parameter.BatchSizePercent < 0 || parameter.BatchSizePercent > 100 {
+		return errors.New("batchSizePercent must be between 0 and 100")
+	}
+	if parameter.BatchSize > 0 && parameter.BatchSizePercent > 0 {
+		return errors.New("batchSize and batchSizePercent cannot both be set")
+	}
+	if parameter.BatchDelaySeconds < 0 {
+		return errors.New("batchDelaySeconds cannot be negative")
+	}
+	return nil
+}
+
+func validateFailureThreshold(parameter TransitionParameter) error {
+	if parameter.MaxFailures < 0 {
+		return errors.New("maxFailures cannot be negative")
+	}
+	if parameter.MaxFailurePercent < 0 || parameter.MaxFailurePercent > 100 {
+		return errors.New("maxFailurePercent must be between 0 and 100")
+	}
+	return nil
+}
+
 //////////////
 // INTERNAL - Generally passed around /internal/* packages
 //////////////
@@ -124,6 +422,58 @@ type Transition struct {
 	AutomaticExpirationTime time.Time `json:"automaticExpirationTime" db:"expires"`
 	// Status is the current phase of the transition lifecycle.
 	Status string `json:"transitionStatus" db:"status"`
+	// PowerSequence is the name of the power sequence the transition follows. Empty means the built-in default.
+	PowerSequence string `json:"powerSequence,omitempty" db:"power_sequence"`
+	// BatchSize and BatchSizePercent limit how many components in a tier are acted on at once. Zero means no limit.
+	BatchSize        int `json:"batchSize,omitempty" db:"batch_size"`
+	BatchSizePercent int `json:"batchSizePercent,omitempty" db:"batch_size_percent"`
+	// BatchDelaySeconds is the pause between batches.
+	BatchDelaySeconds int `json:"batchDelaySeconds,omitempty" db:"batch_delay_seconds"`
+	// BatchWaitForConfirmation holds each batch until the previous one is confirmed.
+	BatchWaitForConfirmation bool `json:"batchWaitForConfirmation,omitempty" db:"batch_wait_for_confirmation"`
+	// MaxFailures and MaxFailurePercent halt the transition once exceeded by its failed tasks. Zero means no limit.
+	MaxFailures       int `json:"maxFailures,omitempty" db:"max_failures"`
+	MaxFailurePercent int `json:"maxFailurePercent,omitempty" db:"max_failure_percent"`
+	// StartAt is when a scheduled transition is due to start. CronSchedule, if set, schedules the next occurrence
+	// when this one starts.
+	StartAt      *time.Time `json:"startAt,omitempty" db:"start_at"`
+	CronSchedule string     `json:"cronSchedule,omitempty" db:"cron_schedule"`
+	// ParentID is the transition this one retries the failed tasks of, if any.
+	ParentID *uuid.UUID `json:"parentID,omitempty" db:"parent_id"`
+	// AbortedXnames are components that have been removed from the transition while it is running.
+	AbortedXnames XnameSlice `json:"abortedXnames,omitempty" db:"aborted_xnames"`
+	// CallbackURL is sent a webhook notification when the transition completes.
+	CallbackURL string `json:"callbackURL,omitempty" db:"callback_url"`
+	// RequesterSubject and RequesterIssuer are the sub and iss claims of the caller's token.
+	RequesterSubject string `json:"requesterSubject,omitempty" db:"requester_sub"`
+	RequesterIssuer  string `json:"requesterIssuer,omitempty" db:"requester_iss"`
+	// FreezeOverride is set if the requester may act on frozen components.
+	FreezeOverride bool `json:"freezeOverride,omitempty" db:"freeze_override"`
+	// Force is set if the transition may power off or restart protected components.
+	Force bool `json:"force,omitempty" db:"force"`
+	// Reason and Labels are free-form notes from the request.
+	Reason string `json:"reason,omitempty" db:"reason"`
+	Labels Labels `json:"labels,omitempty" db:"labels"`
+	// ConflictPolicy is whether the transition queues, rather than being rejected, when it overlaps an active
+	// transition. Empty means reject.
+	ConflictPolicy string `json:"conflictPolicy,omitempty" db:"conflict_policy"`
+	// ConfirmPollSeconds is how often to poll BMCs for power state while confirming actions. Zero means use the
+	// stored power status instead.
+	ConfirmPollSeconds int `json:"confirmPollSeconds,omitempty" db:"confirm_poll_seconds"`
+	// TaskDeadlines override TaskDeadline for particular actions and component types.
+	TaskDeadlines TaskDeadlineSlice `json:"taskDeadlines,omitempty" db:"task_deadlines"`
+	// PowerOnStagger overrides the global power on stagger for particular component types.
+	PowerOnStagger PowerOnStaggerSlice `json:"powerOnStagger,omitempty" db:"power_on_stagger"`
+	// BudgetPolicy is whether the transition is rejected, queued, or partly started when it would exceed a power
+	// budget. Empty means reject.
+	BudgetPolicy string `json:"budgetPolicy,omitempty" db:"budget_policy"`
+	// BudgetDeferred are components left out of the transition because powering them on would exceed a power budget.
+	BudgetDeferred XnameSlice `json:"budgetDeferred,omitempty" db:"budget_deferred"`
+	// BudgetUnchecked are nodes the transition powers on without checking them against the power budgets, because
+	// their draw isn't known.
+	BudgetUnchecked XnameSlice `json:"budgetUnchecked,omitempty" db:"budget_unchecked"`
+	// Error is why the transition ended without running, such as a scheduled occurrence that couldn't be started.
+	Error string `json:"error,omitempty" db:"error"`
 	// TaskIDs are the IDs of individual tasks in the transition/
 	TaskIDs []uuid.UUID
 
@@ -141,6 +491,58 @@ type Transition struct {
 	Tasks TransitionTaskRespSlice `json:"tasks,omitempty" db:"tasks"`
 }
 
+// NewRetryTransition creates a transition that repeats parent's operation,
+// with the same options, on the given locations. It starts right away.
+func NewRetryTransition(parent Transition, location []LocationParameter, expirationTimeMins int) Transition {
+	parentID := parent.TransitionID
+	return Transition{
+		TransitionID:             uuid.New(),
+		Operation:                parent.Operation,
+		TaskDeadline:             parent.TaskDeadline,
+		Location:                 location,
+		CreateTime:               time.Now(),
+		LastActiveTime:           time.Now(),
+		AutomaticExpirationTime:  time.Now().Add(time.Minute * time.Duration(expirationTimeMins)),
+		Status:                   TransitionStatusNew,
+		PowerSequence:            parent.PowerSequence,
+		BatchSize:                parent.BatchSize,
+		BatchSizePercent:         parent.BatchSizePercent,
+		BatchDelaySeconds:        parent.BatchDelaySeconds,
+		BatchWaitForConfirmation: parent.BatchWaitForConfirmation,
+		MaxFailures:              parent.MaxFailures,
+		MaxFailurePercent:        parent.MaxFailurePercent,
+		ParentID:                 &parentID,
+		CallbackURL:              parent.CallbackURL,
+		Reason:                   parent.Reason,
+		Labels:                   parent.Labels,
+		ConflictPolicy:           parent.ConflictPolicy,
+		ConfirmPollSeconds:       parent.ConfirmPollSeconds,
+		TaskDeadlines:            parent.TaskDeadlines,
+		PowerOnStagger:           parent.PowerOnStagger,
+		BudgetPolicy:             parent.BudgetPolicy,
+		TaskIDs:                  []uuid.UUID{},
+	}
+}
+
+type XnameSlice []string
+
+func (x XnameSlice) Value() (driver.Value, error) {
+	return json.Marshal(x)
+}
+
+func (x *XnameSlice) Scan(value interface{}) error {
+	if value == nil {
+		*x = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &x)
+}
+
 type TransitionPage struct {
 	ID           string               `json:"ID"`
 	TransitionID uuid.UUID            `json:"transitionID"`
@@ -161,6 +563,8 @@ type TransitionTask struct {
 	Status         string    `json:"taskStatus" db:"status"`
 	StatusDesc     string    `json:"taskStatusDescription" db:"status_desc"`
 	Error          string    `json:"error,omitempty" db:"error"`
+	// Timeline is every change made to the task.
+	Timeline TaskTimeline `json:"timeline,omitempty" db:"timeline"`
 }
 
 //////////////
@@ -168,12 +572,19 @@ type TransitionTask struct {
 //////////////
 
 type TransitionCreation struct {
-	TransitionID uuid.UUID `json:"transitionID"`
-	Operation    string    `json:"operation"`
+	TransitionID     uuid.UUID  `json:"transitionID"`
+	Operation        string     `json:"operation"`
+	TransitionStatus string     `json:"transitionStatus,omitempty"`
+	StartAt          *time.Time `json:"startAt,omitempty"`
+	// BudgetUnchecked are nodes powered on without checking them against
+	// the power budgets, because their draw isn't known.
+	BudgetUnchecked XnameSlice `json:"budgetUnchecked,omitempty"`
 }
 
 type TransitionRespArray struct {
 	Transitions []TransitionResp `json:"transitions"`
+	// NextCursor is passed as the cursor query parameter to get the next page.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 type TransitionResp struct {
@@ -182,6 +593,23 @@ type TransitionResp struct {
 	CreateTime              time.Time               `json:"createTime"`
 	AutomaticExpirationTime time.Time               `json:"automaticExpirationTime"`
 	TransitionStatus        string                  `json:"transitionStatus"`
+	PowerSequence           string                  `json:"powerSequence,omitempty"`
+	StartAt                 *time.Time              `json:"startAt,omitempty"`
+	CronSchedule            string                  `json:"cronSchedule,omitempty"`
+	ParentID                *uuid.UUID              `json:"parentID,omitempty"`
+	CallbackURL             string                  `json:"callbackURL,omitempty"`
+	Requester               *Requester              `json:"requester,omitempty"`
+	FreezeOverride          bool                    `json:"freezeOverride,omitempty"`
+	Force                   bool                    `json:"force,omitempty"`
+	Reason                  string                  `json:"reason,omitempty"`
+	Labels                  Labels                  `json:"labels,omitempty"`
+	ConflictPolicy          string                  `json:"conflictPolicy,omitempty"`
+	TaskDeadlines           TaskDeadlineSlice       `json:"taskDeadlines,omitempty"`
+	PowerOnStagger          PowerOnStaggerSlice     `json:"powerOnStagger,omitempty"`
+	BudgetPolicy            string                  `json:"budgetPolicy,omitempty"`
+	BudgetDeferred          XnameSlice              `json:"budgetDeferred,omitempty"`
+	BudgetUnchecked         XnameSlice              `json:"budgetUnchecked,omitempty"`
+	Error                   string                  `json:"error,omitempty"`
 	TaskCounts              TransitionTaskCounts    `json:"taskCounts"`
 	Tasks                   TransitionTaskRespSlice `json:"tasks,omitempty"`
 }
@@ -193,6 +621,7 @@ type TransitionTaskCounts struct {
 	Failed      int `json:"failed"`
 	Succeeded   int `json:"succeeded"`
 	Unsupported int `json:"un-supported"`
+	Aborted     int `json:"aborted"`
 }
 
 func (t TransitionTaskCounts) Value() (driver.Value, error) {
@@ -209,10 +638,13 @@ func (t *TransitionTaskCounts) Scan(value interface{}) error {
 }
 
 type TransitionTaskResp struct {
-	Xname          string `json:"xname"`
-	TaskStatus     string `json:"taskStatus"`
-	TaskStatusDesc string `json:"taskStatusDescription"`
-	Error          string `json:"error,omitempty"`
+	Xname          string       `json:"xname"`
+	TaskStatus     string       `json:"taskStatus"`
+	TaskStatusDesc string       `json:"taskStatusDescription"`
+	Error          string       `json:"error,omitempty"`
+	Timeline       TaskTimeline `json:"timeline,omitempty"`
+	// TierDurations are worked out from Timeline when it is requested.
+	TierDurations []TaskTierDuration `json:"tierDurations,omitempty"`
 }
 
 type TransitionTaskRespSlice []TransitionTaskResp
@@ -230,10 +662,59 @@ func (t *TransitionTaskRespSlice) Scan(value interface{}) error {
 	return json.Unmarshal(b, &t)
 }
 
+// TransitionPlan is the result of a dry-run transition. It describes what a
+// real transition with the same parameters would do at this point in time.
+type TransitionPlan struct {
+	Operation   string                    `json:"operation"`
+	Components  []TransitionPlanComponent `json:"components"`
+	Unsupported []string                  `json:"unsupported"`
+	Missing     []string                  `json:"missing"`
+}
+
+type TransitionPlanComponent struct {
+	Xname string `json:"xname"`
+	// AutoAdded is set for components that were not requested but would be
+	// added to the transition by PCS (i.e. Rosetta switches).
+	AutoAdded      bool                 `json:"autoAdded"`
+	TaskStatus     string               `json:"taskStatus"`
+	TaskStatusDesc string               `json:"taskStatusDescription"`
+	Error          string               `json:"error,omitempty"`
+	Steps          []TransitionPlanStep `json:"steps,omitempty"`
+}
+
+// TransitionPlanStep is a single power action applied to a component. Tier is
+// the index of the power sequence tier the action is performed in and Batch is
+// the index of the batch within that tier.
+type TransitionPlanStep struct {
+	Tier   int    `json:"tier"`
+	Batch  int    `json:"batch"`
+	Action string `json:"action"`
+	// DelaySeconds is how long after its batch starts the component is
+	// powered on, when power on is staggered.
+	DelaySeconds int `json:"delaySeconds,omitempty"`
+}
+
 type TransitionAbortResp struct {
 	AbortStatus string `json:"abortStatus"`
 }
 
+type TransitionPauseResp struct {
+	PauseStatus string `json:"pauseStatus"`
+}
+
+const (
+	TransitionEventTask     = "task"
+	TransitionEventComplete = "complete"
+)
+
+// TransitionEvent is a single server-sent event about a transition's progress.
+// Data is a TransitionTaskResp for task events and a TransitionResp for the
+// complete event.
+type TransitionEvent struct {
+	Event string
+	Data  interface{}
+}
+
 // Assembles a TransitionResp struct from a transition and an array of its tasks.
 // If 'full' == true, full task information is included (xname, taskStatus, errors, etc).
 func ToTransitionResp(transition Transition, tasks []TransitionTask, full bool) TransitionResp {
@@ -244,6 +725,23 @@ func ToTransitionResp(transition Transition, tasks []TransitionTask, full bool)
 		CreateTime:              transition.CreateTime,
 		AutomaticExpirationTime: transition.AutomaticExpirationTime,
 		TransitionStatus:        transition.Status,
+		PowerSequence:           transition.PowerSequence,
+		StartAt:                 transition.StartAt,
+		CronSchedule:            transition.CronSchedule,
+		ParentID:                transition.ParentID,
+		CallbackURL:             transition.CallbackURL,
+		Requester:               toRequesterResp(transition.RequesterSubject, transition.RequesterIssuer),
+		FreezeOverride:          transition.FreezeOverride,
+		Force:                   transition.Force,
+		Reason:                  transition.Reason,
+		Labels:                  transition.Labels,
+		ConflictPolicy:          transition.ConflictPolicy,
+		TaskDeadlines:           transition.TaskDeadlines,
+		PowerOnStagger:          transition.PowerOnStagger,
+		BudgetPolicy:            transition.BudgetPolicy,
+		BudgetDeferred:          transition.BudgetDeferred,
+		BudgetUnchecked:         transition.BudgetUnchecked,
+		Error:                   transition.Error,
 	}
 
 	// Is a compressed record
@@ -269,6 +767,8 @@ func ToTransitionResp(transition Transition, tasks []TransitionTask, full bool)
 			counts.Succeeded++
 		case TransitionTaskStatusUnsupported:
 			counts.Unsupported++
+		case TransitionTaskStatusAborted:
+			counts.Aborted++
 		}
 		counts.Total++
 		// Include information about individual tasks if full == true
@@ -278,6 +778,7 @@ func ToTransitionResp(transition Transition, tasks []TransitionTask, full bool)
 				TaskStatus:     task.Status,
 				TaskStatusDesc: task.StatusDesc,
 				Error:          task.Error,
+				Timeline:       task.Timeline,
 			}
 			rsp.Tasks = append(rsp.Tasks, taskRsp)
 		}
@@ -0,0 +1,80 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TransitionsTS struct {
+	suite.Suite
+}
+
+func (suite *TransitionsTS) TestLocationSelectors() {
+	suite.False(LocationParameter{Xname: "x1000c0s0b0n0"}.IsSelector())
+	suite.True(LocationParameter{Group: "compute"}.IsSelector())
+	suite.True(LocationParameter{Role: "Compute", SubRole: "Worker"}.IsSelector())
+
+	for _, loc := range []LocationParameter{
+		{Xname: "x1000c0s0b0n0", DeputyKey: "abc"},
+		{Group: "compute"},
+		{Partition: "p1"},
+		{Role: "Compute"},
+		{Role: "Management", SubRole: "Worker"},
+		{Group: "compute", Partition: "p1"},
+		{Xname: "x1000c3", Expand: []string{"node", "RouterModule"}},
+	} {
+		_, err := ToTransition(TransitionParameter{Operation: "On", Location: []LocationParameter{loc}}, 10)
+		suite.NoError(err, "location %+v", loc)
+	}
+	for _, loc := range []LocationParameter{
+		{Xname: "x1000c0s0b0n0", Group: "compute"},
+		{Group: "compute", DeputyKey: "abc"},
+		{SubRole: "Worker"},
+		{Group: "compute", Expand: []string{"Node"}},
+		{Xname: "x1000c3", Expand: []string{"Node"}, DeputyKey: "abc"},
+		{Xname: "x1000c3", Expand: []string{"Blade"}},
+	} {
+		_, err := ToTransition(TransitionParameter{Operation: "On", Location: []LocationParameter{loc}}, 10)
+		suite.Error(err, "location %+v", loc)
+	}
+}
+
+func (suite *TransitionsTS) TestConfirmPollSeconds() {
+	param := TransitionParameter{Operation: "On", ConfirmPollSeconds: 5, CronSchedule: "0 2 * * *"}
+	tr, err := ToTransition(param, 10)
+	suite.Require().NoError(err)
+	suite.Equal(5, tr.ConfirmPollSeconds)
+	next, ok := NextScheduledTransition(tr, 10)
+	suite.Require().True(ok)
+	suite.Equal(5, next.ConfirmPollSeconds)
+	suite.Equal(5, NewRetryTransition(tr, nil, 10).ConfirmPollSeconds)
+
+	for _, seconds := range []int{-1, 1, MinConfirmPollSeconds - 1} {
+		param.ConfirmPollSeconds = seconds
+		_, err = ToTransition(param, 10)
+		suite.Error(err, "confirmPollSeconds %d", seconds)
+	}
+}
+
+func (suite *TransitionsTS) TestXnameSliceScan() {
+	xnames := XnameSlice{"x1000c0s0b0n0"}
+	suite.Require().NoError(xnames.Scan(nil))
+	suite.Nil(xnames)
+
+	for value, want := range map[string]XnameSlice{
+		"null":              nil,
+		"[]":                {},
+		`["x1000c0s0b0n0"]`: {"x1000c0s0b0n0"},
+	} {
+		suite.Require().NoError(xnames.Scan([]byte(value)), value)
+		suite.Equal(want, xnames, value)
+	}
+	suite.Error(xnames.Scan(1))
+}
+
+func TestTransitionsSuite(t *testing.T) {
+	suite.Run(t, new(TransitionsTS))
+}
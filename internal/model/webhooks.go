@@ -0,0 +1,124 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+///////////////////////////
+// Webhook Definitions
+///////////////////////////
+
+const (
+	WebhookEventTransitionComplete = "transition.complete"
+	WebhookEventPowerCapComplete   = "power-cap.complete"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// WebhookNotification is the body PCS POSTs to a webhook URL. Data is a
+// TransitionResp or PowerCapTaskResp summary, without per-component details.
+type WebhookNotification struct {
+	Event      string      `json:"event"`
+	DeliveryID uuid.UUID   `json:"deliveryID"`
+	Time       time.Time   `json:"time"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookDelivery tracks sending a notification to one URL. Deliveries are
+// kept in storage so any PCS instance can retry them.
+type WebhookDelivery struct {
+	DeliveryID uuid.UUID `json:"deliveryID" db:"id"`
+	URL        string    `json:"url" db:"url"`
+	Event      string    `json:"event" db:"event"`
+	// Payload is the JSON encoded WebhookNotification. It is signed as is.
+	Payload    string    `json:"payload" db:"payload"`
+	CreateTime time.Time `json:"createTime" db:"created"`
+	// NextAttempt is when the delivery is next due to be sent. It is pushed
+	// out while an instance is sending it so no other instance picks it up.
+	NextAttempt             time.Time `json:"nextAttempt" db:"next_attempt"`
+	Attempts                int       `json:"attempts" db:"attempts"`
+	Status                  string    `json:"status" db:"status"`
+	LastError               string    `json:"lastError,omitempty" db:"last_error"`
+	AutomaticExpirationTime time.Time `json:"automaticExpirationTime" db:"expires"`
+}
+
+// NewWebhookDelivery creates a pending delivery of event, with data as its
+// summary, to url.
+func NewWebhookDelivery(url string, event string, data interface{}, expirationTimeMins int) (WebhookDelivery, error) {
+	now := WebhookTime(time.Now())
+	delivery := WebhookDelivery{
+		DeliveryID:              uuid.New(),
+		URL:                     url,
+		Event:                   event,
+		CreateTime:              now,
+		NextAttempt:             now,
+		Status:                  WebhookDeliveryStatusPending,
+		AutomaticExpirationTime: now.Add(time.Minute * time.Duration(expirationTimeMins)),
+	}
+	payload, err := json.Marshal(WebhookNotification{
+		Event:      event,
+		DeliveryID: delivery.DeliveryID,
+		Time:       delivery.CreateTime,
+		Data:       data,
+	})
+	delivery.Payload = string(payload)
+	return delivery, err
+}
+
+// WebhookTime truncates t to the precision Postgres stores, so a delivery
+// built in memory can be used as the test value of a TASWebhookDelivery.
+func WebhookTime(t time.Time) time.Time {
+	return t.Truncate(time.Microsecond)
+}
+
+// ValidateCallbackURL checks that a requested callback URL is an absolute
+// http or https URL. An empty URL is valid and means no callback.
+func ValidateCallbackURL(callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("invalid callbackURL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("callbackURL must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// CallbackURLAllowed reports whether callbackURL falls under one of the
+// allowed URL prefixes: it has the same scheme and host, including port, and
+// its cleaned path is the prefix's path or below it.
+func CallbackURLAllowed(callbackURL string, allowed []string) bool {
+	u, err := url.Parse(callbackURL)
+	if err != nil || u.User != nil {
+		return false
+	}
+	for _, prefix := range allowed {
+		p, err := url.Parse(prefix)
+		if err != nil {
+			continue
+		}
+		if !strings.EqualFold(u.Scheme, p.Scheme) || !strings.EqualFold(u.Host, p.Host) {
+			continue
+		}
+		prefixPath := path.Clean("/" + p.Path)
+		callbackPath := path.Clean("/" + u.Path)
+		if prefixPath == "/" || callbackPath == prefixPath || strings.HasPrefix(callbackPath, prefixPath+"/") {
+			return true
+		}
+	}
+	return false
+}
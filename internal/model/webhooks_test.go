@@ -0,0 +1,40 @@
+//go:build !integration_tests
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhooksTS struct {
+	suite.Suite
+}
+
+func (suite *WebhooksTS) TestCallbackURLAllowed() {
+	allowed := []string{"https://hooks.example.com/pcs/", "http://10.1.0.5:8080"}
+	for _, u := range []string{
+		"https://hooks.example.com/pcs",
+		"https://HOOKS.example.com/pcs/transitions",
+		"http://10.1.0.5:8080/anything",
+	} {
+		suite.True(CallbackURLAllowed(u, allowed), u)
+	}
+	for _, u := range []string{
+		"https://hooks.example.com/pcsx",
+		"https://hooks.example.com/pcs/../admin",
+		"http://hooks.example.com/pcs/",
+		"https://hooks.example.com.evil.net/pcs/",
+		"https://user@hooks.example.com/pcs/",
+		"http://10.1.0.5/anything",
+		"http://127.0.0.1:2379/v3/kv/range",
+	} {
+		suite.False(CallbackURLAllowed(u, allowed), u)
+	}
+	suite.False(CallbackURLAllowed("https://hooks.example.com/pcs/", nil))
+}
+
+func TestWebhooksSuite(t *testing.T) {
+	suite.Run(t, new(WebhooksTS))
+}
@@ -0,0 +1,49 @@
+//go:build integration_tests
+
+package storage
+
+import (
+	"time"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// TestDesiredPowerStateSetGetDelete tests storing, replacing, listing, and deleting a desired power state.
+func (s *StorageTestSuite) TestDesiredPowerStateSetGetDelete() {
+	t := s.T()
+	state, err := model.ToDesiredPowerState("group:compute", "on")
+	s.Require().NoError(err)
+
+	t.Logf("inserting a desired power state")
+	err = s.sp.StoreDesiredPowerState(state)
+	s.Require().NoError(err)
+
+	got, err := s.sp.GetDesiredPowerState(state.Target)
+	s.Require().NoError(err)
+	s.Assert().Equal(state.Group, got.Group)
+	s.Assert().Equal(state.PowerState, got.PowerState)
+	s.Assert().WithinDuration(state.UpdateTime, got.UpdateTime, time.Millisecond)
+
+	t.Logf("replacing the desired power state")
+	state.PowerState = model.DesiredPowerStateOff
+	err = s.sp.StoreDesiredPowerState(state)
+	s.Require().NoError(err)
+
+	states, err := s.sp.GetAllDesiredPowerStates()
+	s.Require().NoError(err)
+	found := false
+	for _, gotState := range states {
+		if gotState.Target == state.Target {
+			found = true
+			s.Assert().Equal(model.DesiredPowerStateOff, gotState.PowerState)
+		}
+	}
+	s.Assert().True(found)
+
+	t.Logf("deleting the desired power state")
+	err = s.sp.DeleteDesiredPowerState(state.Target)
+	s.Require().NoError(err)
+
+	_, err = s.sp.GetDesiredPowerState(state.Target)
+	s.Require().ErrorContains(err, "does not exist")
+}
@@ -23,6 +23,8 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -59,6 +61,14 @@ const (
 	keySegTransitionPage     = "/transitionpage"
 	keySegTransitionTask     = "/transitiontask"
 	keySegTransitionStat     = "/transitionstat"
+	keySegPowerSequence      = "/powersequence"
+	keySegWebhookDelivery    = "/webhookdelivery"
+	keySegIdempotency        = "/idempotency"
+	keySegIdempotencyProbe   = "/idempotencyprobe"
+	keySegDesiredPowerState  = "/desiredpowerstate"
+	keySegPowerCapabilities  = "/powercapabilities"
+	keySegFreeze             = "/freeze"
+	keySegRedfishEventSub    = "/redfisheventsub"
 	keyMin                   = " "
 	keyMax                   = "~"
 	DefaultEtcdPageSize      = 5000 // Maximum locations (xnames) and task results to store in each etcd entry
@@ -850,6 +860,30 @@ func (e *ETCDStorage) GetAllTransitions() ([]model.Transition, error) {
 	return transitions, err
 }
 
+// GetTransitions filters and pages the transitions in memory, since etcd can only range over keys.
+func (e *ETCDStorage) GetTransitions(filter model.TransitionFilter) ([]model.Transition, string, error) {
+	transitions, err := e.GetAllTransitions()
+	if err != nil {
+		return []model.Transition{}, "", err
+	}
+	matches := []model.Transition{}
+	for _, transition := range transitions {
+		if !filter.Matches(transition) && filter.Xname != "" && !e.DisableSizeChecks {
+			// The xname may be in a location page rather than the first page.
+			fullTransition, _, err := e.GetTransition(transition.TransitionID)
+			if err == nil && filter.Matches(fullTransition) {
+				matches = append(matches, transition)
+			}
+			continue
+		}
+		if filter.Matches(transition) {
+			matches = append(matches, transition)
+		}
+	}
+	page, cursor := filter.PageTransitions(matches)
+	return page, cursor, nil
+}
+
 func (e *ETCDStorage) DeleteTransition(transitionID uuid.UUID) error {
 	key := fmt.Sprintf("%s/%s", keySegTransition, transitionID.String())
 	var combinedErr error
@@ -906,6 +940,373 @@ func (e *ETCDStorage) TASTransition(transition model.Transition, testVal model.T
 	return ok, combinedErr
 }
 
+///////////////////////
+// Power Sequences
+///////////////////////
+
+func (e *ETCDStorage) StorePowerSequence(seq model.PowerSequence) error {
+	key := fmt.Sprintf("%s/%s", keySegPowerSequence, seq.Name)
+	err := e.kvStore(key, seq)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) GetPowerSequence(name string) (model.PowerSequence, error) {
+	var seq model.PowerSequence
+	key := fmt.Sprintf("%s/%s", keySegPowerSequence, name)
+
+	err := e.kvGet(key, &seq)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return seq, err
+}
+
+func (e *ETCDStorage) GetAllPowerSequences() ([]model.PowerSequence, error) {
+	seqs := []model.PowerSequence{}
+	key := fmt.Sprintf("%s/", keySegPowerSequence)
+	k := e.fixUpKey(key)
+	kvl, err := e.kvHandle.GetRange(k+keyMin, k+keyMax)
+	if err == nil {
+		for _, kv := range kvl {
+			var seq model.PowerSequence
+			err = json.Unmarshal([]byte(kv.Value), &seq)
+			if err != nil {
+				e.Logger.Error(err)
+			} else {
+				seqs = append(seqs, seq)
+			}
+		}
+	} else {
+		e.Logger.Error(err)
+	}
+	return seqs, err
+}
+
+func (e *ETCDStorage) DeletePowerSequence(name string) error {
+	key := fmt.Sprintf("%s/%s", keySegPowerSequence, name)
+	err := e.kvDelete(key)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+///////////////////////
+// Webhook Deliveries
+///////////////////////
+
+func (e *ETCDStorage) StoreWebhookDelivery(delivery model.WebhookDelivery) error {
+	key := fmt.Sprintf("%s/%s", keySegWebhookDelivery, delivery.DeliveryID.String())
+	err := e.kvStore(key, delivery)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) GetWebhookDelivery(deliveryID uuid.UUID) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	key := fmt.Sprintf("%s/%s", keySegWebhookDelivery, deliveryID.String())
+
+	err := e.kvGet(key, &delivery)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return delivery, err
+}
+
+func (e *ETCDStorage) GetAllWebhookDeliveries() ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	key := fmt.Sprintf("%s/", keySegWebhookDelivery)
+	k := e.fixUpKey(key)
+	kvl, err := e.kvHandle.GetRange(k+keyMin, k+keyMax)
+	if err == nil {
+		for _, kv := range kvl {
+			var delivery model.WebhookDelivery
+			err = json.Unmarshal([]byte(kv.Value), &delivery)
+			if err != nil {
+				e.Logger.Error(err)
+			} else {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+	} else {
+		e.Logger.Error(err)
+	}
+	return deliveries, err
+}
+
+func (e *ETCDStorage) DeleteWebhookDelivery(deliveryID uuid.UUID) error {
+	key := fmt.Sprintf("%s/%s", keySegWebhookDelivery, deliveryID.String())
+	err := e.kvDelete(key)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) TASWebhookDelivery(delivery model.WebhookDelivery, testVal model.WebhookDelivery) (bool, error) {
+	key := fmt.Sprintf("%s/%s", keySegWebhookDelivery, delivery.DeliveryID.String())
+	ok, err := e.kvTAS(key, testVal, delivery)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return ok, err
+}
+
+///////////////////////
+// Idempotency Keys
+///////////////////////
+
+// Idempotency keys are chosen by clients, so they and the subject are hashed
+// to get something safe to put in an etcd key.
+func (e *ETCDStorage) idempotencyKey(record model.IdempotencyRecord) string {
+	sum := sha256.Sum256([]byte(record.Subject + "\x00" + record.Key))
+	return fmt.Sprintf("%s/%s/%s", keySegIdempotency, record.Scope, hex.EncodeToString(sum[:]))
+}
+
+func (e *ETCDStorage) ClaimIdempotencyRecord(record model.IdempotencyRecord) (model.IdempotencyRecord, bool, error) {
+	var existing model.IdempotencyRecord
+	key := e.fixUpKey(e.idempotencyKey(record))
+	data, err := json.Marshal(record)
+	if err != nil {
+		return existing, false, err
+	}
+	// etcd has no create-if-absent and a value comparison always fails for a
+	// missing key, so the record is stored by the else branch. A record is
+	// never empty, so the then branch is taken whenever one exists, and only
+	// touches a scratch key.
+	e.mutex.Lock()
+	exists, err := e.kvHandle.Transaction(key, "!=", "", e.fixUpKey(keySegIdempotencyProbe), "1", key, string(data))
+	e.mutex.Unlock()
+	if err != nil {
+		e.Logger.Error(err)
+		return existing, false, err
+	}
+	if !exists {
+		return record, true, nil
+	}
+	err = e.kvGet(key, &existing)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return existing, false, err
+}
+
+func (e *ETCDStorage) StoreIdempotencyRecord(record model.IdempotencyRecord) error {
+	err := e.kvStore(e.idempotencyKey(record), record)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) TASIdempotencyRecord(record model.IdempotencyRecord, testVal model.IdempotencyRecord) (bool, error) {
+	ok, err := e.kvTAS(e.idempotencyKey(record), testVal, record)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return ok, err
+}
+
+func (e *ETCDStorage) GetAllIdempotencyRecords() ([]model.IdempotencyRecord, error) {
+	records := []model.IdempotencyRecord{}
+	key := fmt.Sprintf("%s/", keySegIdempotency)
+	k := e.fixUpKey(key)
+	kvl, err := e.kvHandle.GetRange(k+keyMin, k+keyMax)
+	if err == nil {
+		for _, kv := range kvl {
+			var record model.IdempotencyRecord
+			err = json.Unmarshal([]byte(kv.Value), &record)
+			if err != nil {
+				e.Logger.Error(err)
+			} else {
+				records = append(records, record)
+			}
+		}
+	} else {
+		e.Logger.Error(err)
+	}
+	return records, err
+}
+
+func (e *ETCDStorage) DeleteIdempotencyRecord(record model.IdempotencyRecord) error {
+	err := e.kvDelete(e.idempotencyKey(record))
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+///////////////////////
+// Desired Power States
+///////////////////////
+
+func (e *ETCDStorage) StoreDesiredPowerState(state model.DesiredPowerState) error {
+	key := fmt.Sprintf("%s/%s", keySegDesiredPowerState, state.Target)
+	err := e.kvStore(key, state)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) GetDesiredPowerState(target string) (model.DesiredPowerState, error) {
+	var state model.DesiredPowerState
+	key := fmt.Sprintf("%s/%s", keySegDesiredPowerState, target)
+
+	err := e.kvGet(key, &state)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return state, err
+}
+
+func (e *ETCDStorage) GetAllDesiredPowerStates() ([]model.DesiredPowerState, error) {
+	states := []model.DesiredPowerState{}
+	key := fmt.Sprintf("%s/", keySegDesiredPowerState)
+	k := e.fixUpKey(key)
+	kvl, err := e.kvHandle.GetRange(k+keyMin, k+keyMax)
+	if err == nil {
+		for _, kv := range kvl {
+			var state model.DesiredPowerState
+			err = json.Unmarshal([]byte(kv.Value), &state)
+			if err != nil {
+				e.Logger.Error(err)
+			} else {
+				states = append(states, state)
+			}
+		}
+	} else {
+		e.Logger.Error(err)
+	}
+	return states, err
+}
+
+func (e *ETCDStorage) DeleteDesiredPowerState(target string) error {
+	key := fmt.Sprintf("%s/%s", keySegDesiredPowerState, target)
+	err := e.kvDelete(key)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+/////////////////////////
+// Power Capabilities
+/////////////////////////
+
+func (e *ETCDStorage) StorePowerCapabilities(caps model.ComponentPowerCapabilities) error {
+	key := fmt.Sprintf("%s/%s", keySegPowerCapabilities, caps.Xname)
+	err := e.kvStore(key, caps)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) GetAllPowerCapabilities() ([]model.ComponentPowerCapabilities, error) {
+	capsList := []model.ComponentPowerCapabilities{}
+	key := fmt.Sprintf("%s/", keySegPowerCapabilities)
+	k := e.fixUpKey(key)
+	kvl, err := e.kvHandle.GetRange(k+keyMin, k+keyMax)
+	if err == nil {
+		for _, kv := range kvl {
+			var caps model.ComponentPowerCapabilities
+			err = json.Unmarshal([]byte(kv.Value), &caps)
+			if err != nil {
+				e.Logger.Error(err)
+			} else {
+				capsList = append(capsList, caps)
+			}
+		}
+	} else {
+		e.Logger.Error(err)
+	}
+	return capsList, err
+}
+
+/////////////////////////
+// Freezes
+/////////////////////////
+
+func (e *ETCDStorage) StoreFreeze(freeze model.Freeze) error {
+	key := fmt.Sprintf("%s/%s", keySegFreeze, freeze.FreezeID.String())
+	err := e.kvStore(key, freeze)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) GetFreeze(freezeID uuid.UUID) (model.Freeze, error) {
+	var freeze model.Freeze
+	key := fmt.Sprintf("%s/%s", keySegFreeze, freezeID.String())
+
+	err := e.kvGet(key, &freeze)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return freeze, err
+}
+
+func (e *ETCDStorage) GetAllFreezes() ([]model.Freeze, error) {
+	freezes := []model.Freeze{}
+	key := fmt.Sprintf("%s/", keySegFreeze)
+	k := e.fixUpKey(key)
+	kvl, err := e.kvHandle.GetRange(k+keyMin, k+keyMax)
+	if err == nil {
+		for _, kv := range kvl {
+			var freeze model.Freeze
+			err = json.Unmarshal([]byte(kv.Value), &freeze)
+			if err != nil {
+				e.Logger.Error(err)
+			} else {
+				freezes = append(freezes, freeze)
+			}
+		}
+	} else {
+		e.Logger.Error(err)
+	}
+	return freezes, err
+}
+
+func (e *ETCDStorage) DeleteFreeze(freezeID uuid.UUID) error {
+	key := fmt.Sprintf("%s/%s", keySegFreeze, freezeID.String())
+	err := e.kvDelete(key)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+/////////////////////////
+// Redfish Event Subscriptions
+/////////////////////////
+
+func (e *ETCDStorage) StoreRedfishEventSubscription(sub model.RedfishEventSubscription) error {
+	key := fmt.Sprintf("%s/%s", keySegRedfishEventSub, sub.BMC)
+	err := e.kvStore(key, sub)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return err
+}
+
+func (e *ETCDStorage) GetRedfishEventSubscription(fqdn string) (model.RedfishEventSubscription, error) {
+	var sub model.RedfishEventSubscription
+	key := fmt.Sprintf("%s/%s", keySegRedfishEventSub, fqdn)
+
+	err := e.kvGet(key, &sub)
+	if err != nil {
+		e.Logger.Error(err)
+	}
+	return sub, err
+}
+
 func (e *ETCDStorage) Close() error {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
@@ -0,0 +1,70 @@
+//go:build integration_tests
+
+package storage
+
+import (
+	"time"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// TestFreezeSetGetDelete tests storing, listing, and deleting a freeze.
+func (s *StorageTestSuite) TestFreezeSetGetDelete() {
+	t := s.T()
+	freeze, err := model.ToFreeze(model.FreezeParameter{
+		Xnames:    []string{"x1000c0s0b0n0"},
+		Groups:    []string{"compute"},
+		Prefixes:  []string{"x1001c0"},
+		EndTime:   time.Now().Add(time.Hour),
+		Reason:    "Firmware update",
+		Requester: model.Requester{Subject: "admin", Issuer: "https://issuer.example"},
+	})
+	s.Require().NoError(err)
+
+	t.Logf("inserting a freeze")
+	err = s.sp.StoreFreeze(freeze)
+	s.Require().NoError(err)
+
+	got, err := s.sp.GetFreeze(freeze.FreezeID)
+	s.Require().NoError(err)
+	s.Assert().Equal(freeze.Xnames, got.Xnames)
+	s.Assert().Equal(freeze.Groups, got.Groups)
+	s.Assert().Equal(freeze.Prefixes, got.Prefixes)
+	s.Assert().Equal(freeze.Reason, got.Reason)
+	s.Assert().Equal(freeze.RequesterSubject, got.RequesterSubject)
+	s.Assert().WithinDuration(freeze.EndTime, got.EndTime, time.Millisecond)
+
+	freezes, err := s.sp.GetAllFreezes()
+	s.Require().NoError(err)
+	found := false
+	for _, gotFreeze := range freezes {
+		if gotFreeze.FreezeID == freeze.FreezeID {
+			found = true
+		}
+	}
+	s.Assert().True(found)
+
+	t.Logf("inserting a freeze of a group alone")
+	groupFreeze, err := model.ToFreeze(model.FreezeParameter{
+		Groups:  []string{"io"},
+		EndTime: time.Now().Add(time.Hour),
+		Reason:  "Switch maintenance",
+	})
+	s.Require().NoError(err)
+	err = s.sp.StoreFreeze(groupFreeze)
+	s.Require().NoError(err)
+	got, err = s.sp.GetFreeze(groupFreeze.FreezeID)
+	s.Require().NoError(err)
+	s.Assert().Empty(got.Xnames)
+	s.Assert().Empty(got.Prefixes)
+	s.Assert().Equal(groupFreeze.Groups, got.Groups)
+	err = s.sp.DeleteFreeze(groupFreeze.FreezeID)
+	s.Require().NoError(err)
+
+	t.Logf("deleting the freeze")
+	err = s.sp.DeleteFreeze(freeze.FreezeID)
+	s.Require().NoError(err)
+
+	_, err = s.sp.GetFreeze(freeze.FreezeID)
+	s.Require().ErrorContains(err, "does not exist")
+}
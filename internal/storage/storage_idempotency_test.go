@@ -0,0 +1,69 @@
+//go:build integration_tests
+
+package storage
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// TestIdempotencyRecordClaim tests claiming, test-and-setting, finishing, listing, and deleting an idempotency key.
+func (s *StorageTestSuite) TestIdempotencyRecordClaim() {
+	t := s.T()
+	requester := model.Requester{Subject: "automation"}
+	params := model.PowerCapSnapshotParameter{Xnames: []string{"x0c0s0b0n0"}}
+	record, err := model.NewIdempotencyRecord(model.IdempotencyScopePowerCapSnapshot, requester, uuid.New().String(), params, 5)
+	s.Require().NoError(err)
+
+	t.Logf("claiming an idempotency key")
+	got, claimed, err := s.sp.ClaimIdempotencyRecord(record)
+	s.Require().NoError(err)
+	s.Require().True(claimed)
+	s.Assert().Equal(record.RequestHash, got.RequestHash)
+
+	// the key is taken, so a second claim gets the first record back
+	second := record
+	second.RequestHash = "different"
+	got, claimed, err = s.sp.ClaimIdempotencyRecord(second)
+	s.Require().NoError(err)
+	s.Require().False(claimed)
+	s.Assert().Equal(record.RequestHash, got.RequestHash)
+	s.Assert().Empty(got.Response)
+
+	t.Logf("storing the response")
+	finished := record
+	finished.Response = `{"taskID":"` + uuid.New().String() + `"}`
+	stored, err := s.sp.TASIdempotencyRecord(finished, second)
+	s.Require().NoError(err)
+	s.Require().False(stored)
+	stored, err = s.sp.TASIdempotencyRecord(finished, record)
+	s.Require().NoError(err)
+	s.Require().True(stored)
+	record = finished
+	err = s.sp.StoreIdempotencyRecord(record)
+	s.Require().NoError(err)
+	got, claimed, err = s.sp.ClaimIdempotencyRecord(second)
+	s.Require().NoError(err)
+	s.Require().False(claimed)
+	s.Assert().Equal(record.Response, got.Response)
+
+	records, err := s.sp.GetAllIdempotencyRecords()
+	s.Require().NoError(err)
+	found := false
+	for _, r := range records {
+		if r.Scope == record.Scope && r.Subject == record.Subject && r.Key == record.Key {
+			found = true
+		}
+	}
+	s.Assert().True(found)
+
+	t.Logf("deleting the idempotency key")
+	err = s.sp.DeleteIdempotencyRecord(record)
+	s.Require().NoError(err)
+	_, claimed, err = s.sp.ClaimIdempotencyRecord(second)
+	s.Require().NoError(err)
+	s.Require().True(claimed)
+	err = s.sp.DeleteIdempotencyRecord(second)
+	s.Require().NoError(err)
+}
@@ -59,9 +59,49 @@ type StorageProvider interface {
 	GetTransitionTask(transitionID uuid.UUID, taskID uuid.UUID) (model.TransitionTask, error)
 	GetAllTasksForTransition(transitionID uuid.UUID) ([]model.TransitionTask, error)
 	GetAllTransitions() ([]model.Transition, error)
+	// GetTransitions returns a page of the transitions matching filter and the cursor for the next page, if any.
+	GetTransitions(filter model.TransitionFilter) ([]model.Transition, string, error)
 	DeleteTransition(transitionID uuid.UUID) error
 	DeleteTransitionTask(transitionID uuid.UUID, taskID uuid.UUID) error
 	TASTransition(transition model.Transition, testVal model.Transition) (bool, error)
+
+	StorePowerSequence(seq model.PowerSequence) error
+	GetPowerSequence(name string) (model.PowerSequence, error)
+	GetAllPowerSequences() ([]model.PowerSequence, error)
+	DeletePowerSequence(name string) error
+
+	StoreWebhookDelivery(delivery model.WebhookDelivery) error
+	GetWebhookDelivery(deliveryID uuid.UUID) (model.WebhookDelivery, error)
+	GetAllWebhookDeliveries() ([]model.WebhookDelivery, error)
+	DeleteWebhookDelivery(deliveryID uuid.UUID) error
+	TASWebhookDelivery(delivery model.WebhookDelivery, testVal model.WebhookDelivery) (bool, error)
+
+	// ClaimIdempotencyRecord stores record unless there is already one with
+	// the same scope, subject and key, in which case it returns that one and
+	// false.
+	ClaimIdempotencyRecord(record model.IdempotencyRecord) (model.IdempotencyRecord, bool, error)
+	StoreIdempotencyRecord(record model.IdempotencyRecord) error
+	// TASIdempotencyRecord replaces the record with the same scope, subject
+	// and key with record if it is still testVal.
+	TASIdempotencyRecord(record model.IdempotencyRecord, testVal model.IdempotencyRecord) (bool, error)
+	GetAllIdempotencyRecords() ([]model.IdempotencyRecord, error)
+	DeleteIdempotencyRecord(record model.IdempotencyRecord) error
+
+	StoreDesiredPowerState(state model.DesiredPowerState) error
+	GetDesiredPowerState(target string) (model.DesiredPowerState, error)
+	GetAllDesiredPowerStates() ([]model.DesiredPowerState, error)
+	DeleteDesiredPowerState(target string) error
+
+	StorePowerCapabilities(caps model.ComponentPowerCapabilities) error
+	GetAllPowerCapabilities() ([]model.ComponentPowerCapabilities, error)
+
+	StoreFreeze(freeze model.Freeze) error
+	GetFreeze(freezeID uuid.UUID) (model.Freeze, error)
+	GetAllFreezes() ([]model.Freeze, error)
+	DeleteFreeze(freezeID uuid.UUID) error
+
+	StoreRedfishEventSubscription(sub model.RedfishEventSubscription) error
+	GetRedfishEventSubscription(fqdn string) (model.RedfishEventSubscription, error)
 	// Close closes the storage provider and releases any resources it holds.
 	Close() error
 }
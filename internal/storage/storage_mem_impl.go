@@ -200,6 +200,11 @@ func (m *MEMStorage) GetAllTransitions() ([]model.Transition, error) {
 	return e.GetAllTransitions()
 }
 
+func (m *MEMStorage) GetTransitions(filter model.TransitionFilter) ([]model.Transition, string, error) {
+	e := toETCDStorage(m)
+	return e.GetTransitions(filter)
+}
+
 func (m *MEMStorage) DeleteTransition(transitionID uuid.UUID) error {
 	e := toETCDStorage(m)
 	return e.DeleteTransition(transitionID)
@@ -215,6 +220,140 @@ func (m *MEMStorage) TASTransition(transition model.Transition, testVal model.Tr
 	return e.TASTransition(transition, testVal)
 }
 
+///////////////////////
+// Power Sequences
+///////////////////////
+
+func (m *MEMStorage) StorePowerSequence(seq model.PowerSequence) error {
+	e := toETCDStorage(m)
+	return e.StorePowerSequence(seq)
+}
+
+func (m *MEMStorage) GetPowerSequence(name string) (model.PowerSequence, error) {
+	e := toETCDStorage(m)
+	return e.GetPowerSequence(name)
+}
+
+func (m *MEMStorage) GetAllPowerSequences() ([]model.PowerSequence, error) {
+	e := toETCDStorage(m)
+	return e.GetAllPowerSequences()
+}
+
+func (m *MEMStorage) DeletePowerSequence(name string) error {
+	e := toETCDStorage(m)
+	return e.DeletePowerSequence(name)
+}
+
+func (m *MEMStorage) StoreWebhookDelivery(delivery model.WebhookDelivery) error {
+	e := toETCDStorage(m)
+	return e.StoreWebhookDelivery(delivery)
+}
+
+func (m *MEMStorage) GetWebhookDelivery(deliveryID uuid.UUID) (model.WebhookDelivery, error) {
+	e := toETCDStorage(m)
+	return e.GetWebhookDelivery(deliveryID)
+}
+
+func (m *MEMStorage) GetAllWebhookDeliveries() ([]model.WebhookDelivery, error) {
+	e := toETCDStorage(m)
+	return e.GetAllWebhookDeliveries()
+}
+
+func (m *MEMStorage) DeleteWebhookDelivery(deliveryID uuid.UUID) error {
+	e := toETCDStorage(m)
+	return e.DeleteWebhookDelivery(deliveryID)
+}
+
+func (m *MEMStorage) TASWebhookDelivery(delivery model.WebhookDelivery, testVal model.WebhookDelivery) (bool, error) {
+	e := toETCDStorage(m)
+	return e.TASWebhookDelivery(delivery, testVal)
+}
+
+func (m *MEMStorage) ClaimIdempotencyRecord(record model.IdempotencyRecord) (model.IdempotencyRecord, bool, error) {
+	e := toETCDStorage(m)
+	return e.ClaimIdempotencyRecord(record)
+}
+
+func (m *MEMStorage) StoreIdempotencyRecord(record model.IdempotencyRecord) error {
+	e := toETCDStorage(m)
+	return e.StoreIdempotencyRecord(record)
+}
+
+func (m *MEMStorage) TASIdempotencyRecord(record model.IdempotencyRecord, testVal model.IdempotencyRecord) (bool, error) {
+	e := toETCDStorage(m)
+	return e.TASIdempotencyRecord(record, testVal)
+}
+
+func (m *MEMStorage) GetAllIdempotencyRecords() ([]model.IdempotencyRecord, error) {
+	e := toETCDStorage(m)
+	return e.GetAllIdempotencyRecords()
+}
+
+func (m *MEMStorage) DeleteIdempotencyRecord(record model.IdempotencyRecord) error {
+	e := toETCDStorage(m)
+	return e.DeleteIdempotencyRecord(record)
+}
+
+func (m *MEMStorage) StoreDesiredPowerState(state model.DesiredPowerState) error {
+	e := toETCDStorage(m)
+	return e.StoreDesiredPowerState(state)
+}
+
+func (m *MEMStorage) GetDesiredPowerState(target string) (model.DesiredPowerState, error) {
+	e := toETCDStorage(m)
+	return e.GetDesiredPowerState(target)
+}
+
+func (m *MEMStorage) GetAllDesiredPowerStates() ([]model.DesiredPowerState, error) {
+	e := toETCDStorage(m)
+	return e.GetAllDesiredPowerStates()
+}
+
+func (m *MEMStorage) DeleteDesiredPowerState(target string) error {
+	e := toETCDStorage(m)
+	return e.DeleteDesiredPowerState(target)
+}
+
+func (m *MEMStorage) StorePowerCapabilities(caps model.ComponentPowerCapabilities) error {
+	e := toETCDStorage(m)
+	return e.StorePowerCapabilities(caps)
+}
+
+func (m *MEMStorage) GetAllPowerCapabilities() ([]model.ComponentPowerCapabilities, error) {
+	e := toETCDStorage(m)
+	return e.GetAllPowerCapabilities()
+}
+
+func (m *MEMStorage) StoreFreeze(freeze model.Freeze) error {
+	e := toETCDStorage(m)
+	return e.StoreFreeze(freeze)
+}
+
+func (m *MEMStorage) GetFreeze(freezeID uuid.UUID) (model.Freeze, error) {
+	e := toETCDStorage(m)
+	return e.GetFreeze(freezeID)
+}
+
+func (m *MEMStorage) GetAllFreezes() ([]model.Freeze, error) {
+	e := toETCDStorage(m)
+	return e.GetAllFreezes()
+}
+
+func (m *MEMStorage) DeleteFreeze(freezeID uuid.UUID) error {
+	e := toETCDStorage(m)
+	return e.DeleteFreeze(freezeID)
+}
+
+func (m *MEMStorage) StoreRedfishEventSubscription(sub model.RedfishEventSubscription) error {
+	e := toETCDStorage(m)
+	return e.StoreRedfishEventSubscription(sub)
+}
+
+func (m *MEMStorage) GetRedfishEventSubscription(fqdn string) (model.RedfishEventSubscription, error) {
+	e := toETCDStorage(m)
+	return e.GetRedfishEventSubscription(fqdn)
+}
+
 func (m *MEMStorage) Close() error {
 	return toETCDStorage(m).Close()
 }
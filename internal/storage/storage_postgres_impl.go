@@ -2,8 +2,10 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Cray-HPE/hms-xname/xnametypes"
@@ -351,8 +353,12 @@ func (p *PostgresStorage) StorePowerCapTask(task model.PowerCapTask) error {
 		patch_parameters,
 		compressed,
 		task_counts,
-		components
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		components,
+		requester_sub,
+		requester_iss,
+		reason,
+		labels
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	ON CONFLICT (id) DO UPDATE SET
 	status = excluded.status,
 	snapshot_parameters = excluded.snapshot_parameters,
@@ -377,6 +383,10 @@ func (p *PostgresStorage) StorePowerCapTask(task model.PowerCapTask) error {
 		task.IsCompressed,
 		task.TaskCounts,
 		task.Components,
+		task.RequesterSubject,
+		task.RequesterIssuer,
+		task.Reason,
+		task.Labels,
 	)
 	if err != nil {
 		return fmt.Errorf("Failed to store task '%s': %w", task.TaskID, err)
@@ -501,14 +511,46 @@ func storeTransitionWithTx(tx *sqlx.Tx, transition model.Transition) error {
 		status,
 		compressed,
 		task_counts,
-		tasks
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		tasks,
+		power_sequence,
+		batch_size,
+		batch_size_percent,
+		batch_delay_seconds,
+		batch_wait_for_confirmation,
+		max_failures,
+		max_failure_percent,
+		start_at,
+		cron_schedule,
+		parent_id,
+		aborted_xnames,
+		callback_url,
+		requester_sub,
+		requester_iss,
+		reason,
+		labels,
+		conflict_policy,
+		confirm_poll_seconds,
+		task_deadlines,
+		power_on_stagger,
+		budget_policy,
+		budget_deferred,
+		freeze_override,
+		force,
+		budget_unchecked,
+		error
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
+		$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37)
 	ON CONFLICT (id) DO UPDATE SET
+		location = excluded.location,
+		budget_deferred = excluded.budget_deferred,
+		budget_unchecked = excluded.budget_unchecked,
 		active = excluded.active,
 		status = excluded.status,
 		compressed = excluded.compressed,
 		task_counts = excluded.task_counts,
-		tasks = excluded.tasks
+		tasks = excluded.tasks,
+		aborted_xnames = excluded.aborted_xnames,
+		error = excluded.error
 		`
 	_, err := tx.Exec(
 		exec,
@@ -523,6 +565,32 @@ func storeTransitionWithTx(tx *sqlx.Tx, transition model.Transition) error {
 		transition.IsCompressed,
 		transition.TaskCounts,
 		transition.Tasks,
+		transition.PowerSequence,
+		transition.BatchSize,
+		transition.BatchSizePercent,
+		transition.BatchDelaySeconds,
+		transition.BatchWaitForConfirmation,
+		transition.MaxFailures,
+		transition.MaxFailurePercent,
+		transition.StartAt,
+		transition.CronSchedule,
+		transition.ParentID,
+		transition.AbortedXnames,
+		transition.CallbackURL,
+		transition.RequesterSubject,
+		transition.RequesterIssuer,
+		transition.Reason,
+		transition.Labels,
+		transition.ConflictPolicy,
+		transition.ConfirmPollSeconds,
+		transition.TaskDeadlines,
+		transition.PowerOnStagger,
+		transition.BudgetPolicy,
+		transition.BudgetDeferred,
+		transition.FreezeOverride,
+		transition.Force,
+		transition.BudgetUnchecked,
+		transition.Error,
 	)
 	if err != nil {
 		return fmt.Errorf("Failed to store transition '%s': %w", transition.TransitionID, err)
@@ -541,9 +609,10 @@ func (p *PostgresStorage) StoreTransitionTask(op model.TransitionTask) error {
 		deputy_key,
 		status,
 		status_desc,
-		error
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	ON CONFLICT (id) DO UPDATE SET state = excluded.state, status = excluded.status, status_desc = excluded.status_desc, error = excluded.error`
+		error,
+		timeline
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (id) DO UPDATE SET state = excluded.state, status = excluded.status, status_desc = excluded.status_desc, error = excluded.error, timeline = excluded.timeline`
 	_, err := p.db.Exec(
 		exec,
 		op.TaskID,
@@ -556,6 +625,7 @@ func (p *PostgresStorage) StoreTransitionTask(op model.TransitionTask) error {
 		op.Status,
 		op.StatusDesc,
 		op.Error,
+		op.Timeline,
 	)
 	if err != nil {
 		return fmt.Errorf("Failed to store task '%s': %w", op.TaskID, err)
@@ -618,6 +688,83 @@ func (p *PostgresStorage) GetAllTransitions() ([]model.Transition, error) {
 	return transitions, nil
 }
 
+func (p *PostgresStorage) GetTransitions(filter model.TransitionFilter) ([]model.Transition, string, error) {
+	where := []string{}
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if len(filter.Statuses) > 0 {
+		where = append(where, "status = ANY("+arg(pq.Array(filter.Statuses))+")")
+	}
+	if len(filter.Operations) > 0 {
+		ops := make([]int64, len(filter.Operations))
+		for i, op := range filter.Operations {
+			ops[i] = int64(op)
+		}
+		where = append(where, "operation = ANY("+arg(pq.Array(ops))+")")
+	}
+	if filter.Xname != "" {
+		// Matches the transitions_location_idx expression index.
+		loc, err := json.Marshal([]map[string]string{{"xname": filter.Xname}})
+		if err != nil {
+			return []model.Transition{}, "", err
+		}
+		where = append(where, "location::jsonb @> "+arg(string(loc))+"::jsonb")
+	}
+	for _, label := range filter.Labels {
+		// Both match the transitions_labels_idx index.
+		if label.AnyValue {
+			where = append(where, "labels ? "+arg(label.Key))
+		} else {
+			l, err := json.Marshal(map[string]string{label.Key: label.Value})
+			if err != nil {
+				return []model.Transition{}, "", err
+			}
+			where = append(where, "labels @> "+arg(string(l))+"::jsonb")
+		}
+	}
+	if filter.CreatedAfter != nil {
+		where = append(where, "created > "+arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		where = append(where, "created < "+arg(*filter.CreatedBefore))
+	}
+	order := "ASC"
+	after := ">"
+	if filter.Order == model.TransitionOrderDesc {
+		order = "DESC"
+		after = "<"
+	}
+	if filter.Cursor != nil {
+		where = append(where, fmt.Sprintf("(created, id) %s (%s, %s)", after,
+			arg(filter.Cursor.CreateTime), arg(filter.Cursor.TransitionID)))
+	}
+
+	query := "SELECT * FROM transitions"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY created %s, id %s", order, order)
+	if filter.Limit > 0 {
+		// Fetch one extra to tell whether there is a next page.
+		query += " LIMIT " + arg(filter.Limit+1)
+	}
+
+	transitions := []model.Transition{}
+	err := p.db.Select(&transitions, query, args...)
+	if err != nil {
+		return []model.Transition{}, "", fmt.Errorf("could not retrieve transitions: %w", err)
+	}
+	cursor := ""
+	if filter.Limit > 0 && len(transitions) > filter.Limit {
+		transitions = transitions[:filter.Limit]
+		cursor = model.NewTransitionCursor(transitions[len(transitions)-1]).String()
+	}
+	return transitions, cursor, nil
+}
+
 func (p *PostgresStorage) DeleteTransition(transitionID uuid.UUID) error {
 	_, err := p.db.Exec("DELETE FROM transitions WHERE id = $1", transitionID)
 	return err
@@ -656,6 +803,425 @@ func (p *PostgresStorage) TASTransition(transition model.Transition, testVal mod
 	return true, nil
 }
 
+func (p *PostgresStorage) StorePowerSequence(seq model.PowerSequence) error {
+	exec := `INSERT INTO power_sequences (
+		name,
+		description,
+		steps
+	) VALUES ($1, $2, $3)
+	ON CONFLICT (name) DO UPDATE SET
+		description = excluded.description,
+		steps = excluded.steps
+	`
+	_, err := p.db.Exec(exec, seq.Name, seq.Description, seq.Steps)
+	if err != nil {
+		return fmt.Errorf("Failed to store power sequence '%s': %w", seq.Name, err)
+	}
+	return nil
+}
+
+func (p *PostgresStorage) GetPowerSequence(name string) (model.PowerSequence, error) {
+	var seq model.PowerSequence
+	err := p.db.Get(&seq, "SELECT * FROM power_sequences WHERE name = $1", name)
+	if err != nil {
+		// Calling control flow code expects error containing "does not exist"
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PowerSequence{}, fmt.Errorf("power sequence does not exist")
+		}
+
+		return model.PowerSequence{}, fmt.Errorf("could not retrieve power sequence %s: %w", name, err)
+	}
+	return seq, nil
+}
+
+func (p *PostgresStorage) GetAllPowerSequences() ([]model.PowerSequence, error) {
+	seqs := []model.PowerSequence{}
+	err := p.db.Select(&seqs, "SELECT * FROM power_sequences")
+	if err != nil {
+		return []model.PowerSequence{}, fmt.Errorf("could not retrieve power sequences: %w", err)
+	}
+	return seqs, nil
+}
+
+func (p *PostgresStorage) DeletePowerSequence(name string) error {
+	_, err := p.db.Exec("DELETE FROM power_sequences WHERE name = $1", name)
+	return err
+}
+
+func (p *PostgresStorage) StoreWebhookDelivery(delivery model.WebhookDelivery) error {
+	return storeWebhookDeliveryWithTx(p.db, delivery)
+}
+
+// storeWebhookDeliveryWithTx upserts a delivery with either the database or a transaction.
+func storeWebhookDeliveryWithTx(tx sqlx.Execer, delivery model.WebhookDelivery) error {
+	exec := `INSERT INTO webhook_deliveries (
+		id,
+		url,
+		event,
+		payload,
+		created,
+		next_attempt,
+		attempts,
+		status,
+		last_error,
+		expires
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (id) DO UPDATE SET
+		next_attempt = excluded.next_attempt,
+		attempts = excluded.attempts,
+		status = excluded.status,
+		last_error = excluded.last_error
+	`
+	_, err := tx.Exec(
+		exec,
+		delivery.DeliveryID,
+		delivery.URL,
+		delivery.Event,
+		delivery.Payload,
+		delivery.CreateTime,
+		delivery.NextAttempt,
+		delivery.Attempts,
+		delivery.Status,
+		delivery.LastError,
+		delivery.AutomaticExpirationTime,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to store webhook delivery '%s': %w", delivery.DeliveryID.String(), err)
+	}
+	return nil
+}
+
+func (p *PostgresStorage) GetWebhookDelivery(deliveryID uuid.UUID) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := p.db.Get(&delivery, "SELECT * FROM webhook_deliveries WHERE id = $1", deliveryID)
+	if err != nil {
+		// Calling control flow code expects error containing "does not exist"
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.WebhookDelivery{}, fmt.Errorf("webhook delivery does not exist")
+		}
+
+		return model.WebhookDelivery{}, fmt.Errorf("could not retrieve webhook delivery %s: %w", deliveryID.String(), err)
+	}
+	return delivery, nil
+}
+
+func (p *PostgresStorage) GetAllWebhookDeliveries() ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	err := p.db.Select(&deliveries, "SELECT * FROM webhook_deliveries")
+	if err != nil {
+		return []model.WebhookDelivery{}, fmt.Errorf("could not retrieve webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (p *PostgresStorage) DeleteWebhookDelivery(deliveryID uuid.UUID) error {
+	_, err := p.db.Exec("DELETE FROM webhook_deliveries WHERE id = $1", deliveryID)
+	return err
+}
+
+func (p *PostgresStorage) TASWebhookDelivery(delivery model.WebhookDelivery, testVal model.WebhookDelivery) (bool, error) {
+	tx, err := p.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("could not begin TAS transaction: %w", err)
+	}
+	defer tx.Rollback()
+	var current model.WebhookDelivery
+	err = tx.Get(&current, "SELECT * FROM webhook_deliveries WHERE id = $1 FOR UPDATE", delivery.DeliveryID)
+	if err != nil {
+		return false, fmt.Errorf("could retrieve TAS webhook delivery: %w", err)
+	}
+	if !cmp.Equal(testVal, current) {
+		return false, nil
+	}
+	err = storeWebhookDeliveryWithTx(tx, delivery)
+	if err != nil {
+		return false, fmt.Errorf("could not replace TAS webhook delivery: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("could not commit TAS webhook delivery: %w", err)
+	}
+	return true, nil
+}
+
+func (p *PostgresStorage) ClaimIdempotencyRecord(record model.IdempotencyRecord) (model.IdempotencyRecord, bool, error) {
+	exec := `INSERT INTO idempotency_keys (
+		scope,
+		subject,
+		key,
+		request_hash,
+		response,
+		created,
+		expires
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (scope, subject, key) DO NOTHING
+	`
+	result, err := p.db.Exec(
+		exec,
+		record.Scope,
+		record.Subject,
+		record.Key,
+		record.RequestHash,
+		record.Response,
+		record.CreateTime,
+		record.AutomaticExpirationTime,
+	)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, fmt.Errorf("Failed to claim idempotency key '%s': %w", record.Key, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 1 {
+		return record, true, nil
+	}
+	var existing model.IdempotencyRecord
+	err = p.db.Get(&existing, "SELECT * FROM idempotency_keys WHERE scope = $1 AND subject = $2 AND key = $3",
+		record.Scope, record.Subject, record.Key)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, fmt.Errorf("could not retrieve idempotency key '%s': %w", record.Key, err)
+	}
+	return existing, false, nil
+}
+
+func (p *PostgresStorage) StoreIdempotencyRecord(record model.IdempotencyRecord) error {
+	return storeIdempotencyRecordWithTx(p.db, record)
+}
+
+func (p *PostgresStorage) TASIdempotencyRecord(record model.IdempotencyRecord, testVal model.IdempotencyRecord) (bool, error) {
+	tx, err := p.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("could not begin TAS transaction: %w", err)
+	}
+	defer tx.Rollback()
+	var current model.IdempotencyRecord
+	err = tx.Get(&current, "SELECT * FROM idempotency_keys WHERE scope = $1 AND subject = $2 AND key = $3 FOR UPDATE",
+		record.Scope, record.Subject, record.Key)
+	if err != nil {
+		return false, fmt.Errorf("could retrieve TAS idempotency key: %w", err)
+	}
+	if !cmp.Equal(testVal, current) {
+		return false, nil
+	}
+	err = storeIdempotencyRecordWithTx(tx, record)
+	if err != nil {
+		return false, fmt.Errorf("could not replace TAS idempotency key: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("could not commit TAS idempotency key: %w", err)
+	}
+	return true, nil
+}
+
+// storeIdempotencyRecordWithTx upserts a record with either the database or a transaction.
+func storeIdempotencyRecordWithTx(tx sqlx.Execer, record model.IdempotencyRecord) error {
+	exec := `INSERT INTO idempotency_keys (
+		scope,
+		subject,
+		key,
+		request_hash,
+		response,
+		created,
+		expires
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (scope, subject, key) DO UPDATE SET
+		request_hash = excluded.request_hash,
+		response = excluded.response,
+		created = excluded.created,
+		expires = excluded.expires
+	`
+	_, err := tx.Exec(
+		exec,
+		record.Scope,
+		record.Subject,
+		record.Key,
+		record.RequestHash,
+		record.Response,
+		record.CreateTime,
+		record.AutomaticExpirationTime,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to store idempotency key '%s': %w", record.Key, err)
+	}
+	return nil
+}
+
+func (p *PostgresStorage) GetAllIdempotencyRecords() ([]model.IdempotencyRecord, error) {
+	records := []model.IdempotencyRecord{}
+	err := p.db.Select(&records, "SELECT * FROM idempotency_keys")
+	if err != nil {
+		return []model.IdempotencyRecord{}, fmt.Errorf("could not retrieve idempotency keys: %w", err)
+	}
+	return records, nil
+}
+
+func (p *PostgresStorage) DeleteIdempotencyRecord(record model.IdempotencyRecord) error {
+	_, err := p.db.Exec("DELETE FROM idempotency_keys WHERE scope = $1 AND subject = $2 AND key = $3",
+		record.Scope, record.Subject, record.Key)
+	return err
+}
+
+func (p *PostgresStorage) StoreDesiredPowerState(state model.DesiredPowerState) error {
+	exec := `INSERT INTO desired_power_states (
+		target,
+		xname,
+		group_name,
+		power_state,
+		updated
+	) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (target) DO UPDATE SET
+		power_state = excluded.power_state,
+		updated = excluded.updated
+	`
+	_, err := p.db.Exec(exec, state.Target, state.Xname, state.Group, state.PowerState, state.UpdateTime)
+	if err != nil {
+		return fmt.Errorf("Failed to store desired power state '%s': %w", state.Target, err)
+	}
+	return nil
+}
+
+func (p *PostgresStorage) GetDesiredPowerState(target string) (model.DesiredPowerState, error) {
+	var state model.DesiredPowerState
+	err := p.db.Get(&state, "SELECT * FROM desired_power_states WHERE target = $1", target)
+	if err != nil {
+		// Calling control flow code expects error containing "does not exist"
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.DesiredPowerState{}, fmt.Errorf("desired power state does not exist")
+		}
+
+		return model.DesiredPowerState{}, fmt.Errorf("could not retrieve desired power state %s: %w", target, err)
+	}
+	return state, nil
+}
+
+func (p *PostgresStorage) GetAllDesiredPowerStates() ([]model.DesiredPowerState, error) {
+	states := []model.DesiredPowerState{}
+	err := p.db.Select(&states, "SELECT * FROM desired_power_states")
+	if err != nil {
+		return []model.DesiredPowerState{}, fmt.Errorf("could not retrieve desired power states: %w", err)
+	}
+	return states, nil
+}
+
+func (p *PostgresStorage) DeleteDesiredPowerState(target string) error {
+	_, err := p.db.Exec("DELETE FROM desired_power_states WHERE target = $1", target)
+	return err
+}
+
+func (p *PostgresStorage) StorePowerCapabilities(caps model.ComponentPowerCapabilities) error {
+	exec := `INSERT INTO power_capabilities (
+		xname,
+		host_limit_max,
+		host_limit_min,
+		powerup_power,
+		updated
+	) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (xname) DO UPDATE SET
+		host_limit_max = excluded.host_limit_max,
+		host_limit_min = excluded.host_limit_min,
+		powerup_power = excluded.powerup_power,
+		updated = excluded.updated
+	`
+	_, err := p.db.Exec(exec, caps.Xname, caps.HostLimitMax, caps.HostLimitMin, caps.PowerupPower, caps.UpdateTime)
+	if err != nil {
+		return fmt.Errorf("Failed to store power capabilities '%s': %w", caps.Xname, err)
+	}
+	return nil
+}
+
+func (p *PostgresStorage) GetAllPowerCapabilities() ([]model.ComponentPowerCapabilities, error) {
+	capsList := []model.ComponentPowerCapabilities{}
+	err := p.db.Select(&capsList, "SELECT * FROM power_capabilities")
+	if err != nil {
+		return []model.ComponentPowerCapabilities{}, fmt.Errorf("could not retrieve power capabilities: %w", err)
+	}
+	return capsList, nil
+}
+
+func (p *PostgresStorage) StoreFreeze(freeze model.Freeze) error {
+	exec := `INSERT INTO freezes (
+		id,
+		xnames,
+		group_names,
+		prefixes,
+		start_time,
+		end_time,
+		reason,
+		requester_sub,
+		requester_iss,
+		created
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (id) DO UPDATE SET
+		xnames = excluded.xnames,
+		group_names = excluded.group_names,
+		prefixes = excluded.prefixes,
+		start_time = excluded.start_time,
+		end_time = excluded.end_time,
+		reason = excluded.reason
+	`
+	_, err := p.db.Exec(exec, freeze.FreezeID, freeze.Xnames, freeze.Groups, freeze.Prefixes, freeze.StartTime,
+		freeze.EndTime, freeze.Reason, freeze.RequesterSubject, freeze.RequesterIssuer, freeze.CreateTime)
+	if err != nil {
+		return fmt.Errorf("Failed to store freeze '%s': %w", freeze.FreezeID, err)
+	}
+	return nil
+}
+
+func (p *PostgresStorage) GetFreeze(freezeID uuid.UUID) (model.Freeze, error) {
+	var freeze model.Freeze
+	err := p.db.Get(&freeze, "SELECT * FROM freezes WHERE id = $1", freezeID)
+	if err != nil {
+		// Calling control flow code expects error containing "does not exist"
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Freeze{}, fmt.Errorf("freeze does not exist")
+		}
+
+		return model.Freeze{}, fmt.Errorf("could not retrieve freeze %s: %w", freezeID, err)
+	}
+	return freeze, nil
+}
+
+func (p *PostgresStorage) GetAllFreezes() ([]model.Freeze, error) {
+	freezes := []model.Freeze{}
+	err := p.db.Select(&freezes, "SELECT * FROM freezes")
+	if err != nil {
+		return []model.Freeze{}, fmt.Errorf("could not retrieve freezes: %w", err)
+	}
+	return freezes, nil
+}
+
+func (p *PostgresStorage) DeleteFreeze(freezeID uuid.UUID) error {
+	_, err := p.db.Exec("DELETE FROM freezes WHERE id = $1", freezeID)
+	return err
+}
+
+func (p *PostgresStorage) StoreRedfishEventSubscription(sub model.RedfishEventSubscription) error {
+	exec := `INSERT INTO redfish_event_subscriptions (
+		bmc,
+		secret,
+		created
+	) VALUES ($1, $2, $3)
+	ON CONFLICT (bmc) DO UPDATE SET
+		secret = excluded.secret,
+		created = excluded.created
+	`
+	_, err := p.db.Exec(exec, sub.BMC, sub.Secret, sub.CreateTime)
+	if err != nil {
+		return fmt.Errorf("Failed to store Redfish event subscription for '%s': %w", sub.BMC, err)
+	}
+	return nil
+}
+
+func (p *PostgresStorage) GetRedfishEventSubscription(fqdn string) (model.RedfishEventSubscription, error) {
+	var sub model.RedfishEventSubscription
+	err := p.db.Get(&sub, "SELECT * FROM redfish_event_subscriptions WHERE bmc = $1", fqdn)
+	if err != nil {
+		// Calling control flow code expects error containing "does not exist"
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.RedfishEventSubscription{}, fmt.Errorf("Redfish event subscription does not exist")
+		}
+
+		return model.RedfishEventSubscription{}, fmt.Errorf("could not retrieve Redfish event subscription for %s: %w", fqdn, err)
+	}
+	return sub, nil
+}
+
 func (p *PostgresStorage) Close() error {
 	if p.db != nil {
 		return p.db.Close()
@@ -3,9 +3,10 @@
 package storage
 
 import (
+	"time"
+
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/google/uuid"
-	"golang.org/x/exp/slices"
 
 	"github.com/OpenCHAMI/power-control/v2/internal/model"
 )
@@ -85,7 +86,7 @@ func (s *StorageTestSuite) TestTransitionSetGet() {
 	s.Require().Equal(testTransition.TransitionID, gotTransition.TransitionID)
 	s.Require().Equal(testTransition.Operation, gotTransition.Operation)
 	s.Require().Equal(testTransition.TaskDeadline, gotTransition.TaskDeadline)
-	s.Require().True(slices.Equal(testTransition.Location, gotTransition.Location))
+	s.Require().Equal(testTransition.Location, gotTransition.Location)
 
 	gotTask, err := s.sp.GetTransitionTask(task.TransitionID, task.TaskID)
 	s.Require().NoError(err)
@@ -234,7 +235,7 @@ func (s *StorageTestSuite) TestTransitionTAS() {
 	t.Logf("retrieving transition %s", testTransition.TransitionID)
 	gotTransition, _, err := s.sp.GetTransition(testTransition.TransitionID)
 	s.Require().NoError(err)
-	s.Require().True(slices.Equal(testTransition.Location, gotTransition.Location))
+	s.Require().Equal(testTransition.Location, gotTransition.Location)
 
 	modTransition := gotTransition
 	modTransition.Status = model.TransitionStatusAborted
@@ -256,6 +257,16 @@ func (s *StorageTestSuite) TestTransitionTAS() {
 	s.Require().NoError(err)
 	s.Require().Equal(gotTransition.Status, model.TransitionStatusAborted)
 
+	// locations can be replaced too, as when a scheduled transition's selectors are resolved
+	resolvedTransition := gotTransition
+	resolvedTransition.Location = []model.LocationParameter{{Xname: "x0c0s3b0n0"}}
+	changed, err = s.sp.TASTransition(resolvedTransition, gotTransition)
+	s.Require().NoError(err)
+	s.Require().True(changed)
+	gotTransition, _, err = s.sp.GetTransition(testTransition.TransitionID)
+	s.Require().NoError(err)
+	s.Require().Equal(resolvedTransition.Location, gotTransition.Location)
+
 	modTransition.TransitionID = uuid.New()
 	t.Logf("failing to update non-existent transition %s", modTransition.TransitionID)
 	changed, err = s.sp.TASTransition(modTransition, gotTransition)
@@ -335,3 +346,114 @@ func (s *StorageTestSuite) TestTransitionCompress() {
 	s.Require().Equal(rsp.TaskCounts, gotTransition.TaskCounts)
 	s.Require().Equal(rsp.Tasks, gotTransition.Tasks)
 }
+
+// TestTransitionFilter tests filtering and paging transitions.
+func (s *StorageTestSuite) TestTransitionFilter() {
+	t := s.T()
+	// A location unique to this test, so other tests' transitions don't match.
+	xname := "x9c0s9b0n0"
+	base := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+
+	t.Logf("inserting some transitions")
+	ids := []uuid.UUID{}
+	for i, status := range []string{model.TransitionStatusCompleted, model.TransitionStatusAborted, model.TransitionStatusCompleted} {
+		transition, _ := model.ToTransition(model.TransitionParameter{
+			Operation: "Off",
+			Location:  []model.LocationParameter{{Xname: "x0c0s1b0n0"}, {Xname: xname}},
+		}, 5)
+		transition.Status = status
+		transition.CreateTime = base.Add(time.Duration(i) * time.Minute)
+		err := s.sp.StoreTransition(transition)
+		s.Require().NoError(err)
+		ids = append(ids, transition.TransitionID)
+	}
+
+	t.Logf("filtering by xname and status")
+	got, cursor, err := s.sp.GetTransitions(model.TransitionFilter{
+		Xname:    xname,
+		Statuses: []string{model.TransitionStatusCompleted},
+		Order:    model.TransitionOrderAsc,
+	})
+	s.Require().NoError(err)
+	s.Require().Empty(cursor)
+	s.Require().Len(got, 2)
+	s.Assert().Equal(ids[0], got[0].TransitionID)
+	s.Assert().Equal(ids[2], got[1].TransitionID)
+
+	t.Logf("paging in descending order")
+	filter := model.TransitionFilter{Xname: xname, Order: model.TransitionOrderDesc, Limit: 2}
+	got, cursor, err = s.sp.GetTransitions(filter)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(cursor)
+	s.Require().Len(got, 2)
+	s.Assert().Equal(ids[2], got[0].TransitionID)
+	s.Assert().Equal(ids[1], got[1].TransitionID)
+	c, err := model.ParseTransitionCursor(cursor)
+	s.Require().NoError(err)
+	filter.Cursor = &c
+	got, cursor, err = s.sp.GetTransitions(filter)
+	s.Require().NoError(err)
+	s.Require().Empty(cursor)
+	s.Require().Len(got, 1)
+	s.Assert().Equal(ids[0], got[0].TransitionID)
+
+	t.Logf("filtering by creation time and operation")
+	after := base
+	got, _, err = s.sp.GetTransitions(model.TransitionFilter{
+		Xname:        xname,
+		Operations:   []model.Operation{model.Operation_Off},
+		CreatedAfter: &after,
+		Order:        model.TransitionOrderAsc,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(got, 2)
+	got, _, err = s.sp.GetTransitions(model.TransitionFilter{Xname: xname, Operations: []model.Operation{model.Operation_On}})
+	s.Require().NoError(err)
+	s.Require().Empty(got)
+}
+
+// TestTransitionNullColumns tests reading a transition stored before its newer JSON columns existed.
+func (s *StorageTestSuite) TestTransitionNullColumns() {
+	t := s.T()
+	pg, ok := s.sp.(*PostgresStorage)
+	if !ok {
+		t.Skip("column defaults only apply to Postgres")
+	}
+
+	transition, _ := model.ToTransition(model.TransitionParameter{
+		Operation: "Off",
+		Location:  []model.LocationParameter{{Xname: "x0c0s1b0n0"}},
+	}, 5)
+	transition.Status = model.TransitionStatusCompleted
+	err := s.sp.StoreTransition(transition)
+	s.Require().NoError(err)
+
+	t.Logf("resetting the newer columns to what migrations leave in existing rows")
+	_, err = pg.db.Exec(`UPDATE transitions SET
+		aborted_xnames = DEFAULT,
+		budget_deferred = DEFAULT,
+		budget_unchecked = DEFAULT,
+		labels = NULL,
+		task_deadlines = NULL,
+		power_on_stagger = NULL
+		WHERE id = $1`, transition.TransitionID)
+	s.Require().NoError(err)
+
+	got, _, err := s.sp.GetTransition(transition.TransitionID)
+	s.Require().NoError(err)
+	s.Assert().Empty(got.AbortedXnames)
+	s.Assert().Empty(got.BudgetDeferred)
+	s.Assert().Empty(got.BudgetUnchecked)
+	s.Assert().Nil(got.Labels)
+	s.Assert().Nil(got.TaskDeadlines)
+	s.Assert().Nil(got.PowerOnStagger)
+
+	_, err = s.sp.GetAllTransitions()
+	s.Require().NoError(err)
+
+	t.Logf("xname columns may not be NULL")
+	for _, column := range []string{"aborted_xnames", "budget_deferred", "budget_unchecked"} {
+		_, err = pg.db.Exec("UPDATE transitions SET "+column+" = NULL WHERE id = $1", transition.TransitionID)
+		s.Require().Error(err, column)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build integration_tests
+
+package storage
+
+import (
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// TestPowerCapabilitiesSetGet tests storing, replacing, and listing component power capabilities.
+func (s *StorageTestSuite) TestPowerCapabilitiesSetGet() {
+	t := s.T()
+	max := 1200
+	powerup := 900
+	caps := model.ToComponentPowerCapabilities("x1000c0s0b0n0", model.PowerCapabilities{HostLimitMax: &max})
+
+	t.Logf("inserting power capabilities")
+	err := s.sp.StorePowerCapabilities(caps)
+	s.Require().NoError(err)
+
+	t.Logf("replacing the power capabilities")
+	caps.PowerupPower = &powerup
+	err = s.sp.StorePowerCapabilities(caps)
+	s.Require().NoError(err)
+
+	capsList, err := s.sp.GetAllPowerCapabilities()
+	s.Require().NoError(err)
+	found := false
+	for _, got := range capsList {
+		if got.Xname == caps.Xname {
+			found = true
+			s.Require().NotNil(got.HostLimitMax)
+			s.Require().NotNil(got.PowerupPower)
+			s.Assert().Nil(got.HostLimitMin)
+			s.Assert().Equal(max, *got.HostLimitMax)
+			s.Assert().Equal(powerup, *got.PowerupPower)
+		}
+	}
+	s.Assert().True(found)
+}
@@ -0,0 +1,53 @@
+//go:build integration_tests
+
+package storage
+
+import (
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// TestPowerSequenceSetGetDelete tests storing, replacing, listing, and deleting a power sequence.
+func (s *StorageTestSuite) TestPowerSequenceSetGetDelete() {
+	t := s.T()
+	seq := model.PowerSequence{
+		Name:        "nodes-only",
+		Description: "Nodes only",
+		Steps: model.PowerSequenceStepSlice{
+			{Action: "gracefulshutdown", ComponentTypes: []string{"Node"}},
+			{Action: "forceoff", ComponentTypes: []string{"Node"}},
+			{Action: "on", ComponentTypes: []string{"Node"}},
+		},
+	}
+
+	t.Logf("inserting a power sequence")
+	err := s.sp.StorePowerSequence(seq)
+	s.Require().NoError(err)
+
+	got, err := s.sp.GetPowerSequence(seq.Name)
+	s.Require().NoError(err)
+	s.Assert().Equal(seq, got)
+
+	t.Logf("replacing the power sequence")
+	seq.Description = "Nodes only, replaced"
+	seq.Steps = seq.Steps[2:]
+	err = s.sp.StorePowerSequence(seq)
+	s.Require().NoError(err)
+
+	seqs, err := s.sp.GetAllPowerSequences()
+	s.Require().NoError(err)
+	found := false
+	for _, gotSeq := range seqs {
+		if gotSeq.Name == seq.Name {
+			found = true
+			s.Assert().Equal(seq, gotSeq)
+		}
+	}
+	s.Assert().True(found)
+
+	t.Logf("deleting the power sequence")
+	err = s.sp.DeletePowerSequence(seq.Name)
+	s.Require().NoError(err)
+
+	_, err = s.sp.GetPowerSequence(seq.Name)
+	s.Require().ErrorContains(err, "does not exist")
+}
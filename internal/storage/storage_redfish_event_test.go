@@ -0,0 +1,38 @@
+//go:build integration_tests
+
+package storage
+
+import (
+	"time"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// TestRedfishEventSubscriptionSetGet tests storing, replacing, and reading a Redfish event subscription.
+func (s *StorageTestSuite) TestRedfishEventSubscriptionSetGet() {
+	t := s.T()
+	sub, err := model.NewRedfishEventSubscription("x3000c0s0b0.example.com")
+	s.Require().NoError(err)
+
+	_, err = s.sp.GetRedfishEventSubscription(sub.BMC)
+	s.Require().ErrorContains(err, "does not exist")
+
+	t.Logf("inserting a Redfish event subscription")
+	err = s.sp.StoreRedfishEventSubscription(sub)
+	s.Require().NoError(err)
+
+	got, err := s.sp.GetRedfishEventSubscription(sub.BMC)
+	s.Require().NoError(err)
+	s.Assert().Equal(sub.Secret, got.Secret)
+	s.Assert().WithinDuration(sub.CreateTime, got.CreateTime, time.Millisecond)
+
+	t.Logf("replacing the Redfish event subscription")
+	replacement, err := model.NewRedfishEventSubscription(sub.BMC)
+	s.Require().NoError(err)
+	err = s.sp.StoreRedfishEventSubscription(replacement)
+	s.Require().NoError(err)
+
+	got, err = s.sp.GetRedfishEventSubscription(sub.BMC)
+	s.Require().NoError(err)
+	s.Assert().Equal(replacement.Secret, got.Secret)
+}
@@ -0,0 +1,56 @@
+//go:build integration_tests
+
+package storage
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/OpenCHAMI/power-control/v2/internal/model"
+)
+
+// TestWebhookDeliverySetGetTAS tests storing, updating, listing, and deleting a webhook delivery.
+func (s *StorageTestSuite) TestWebhookDeliverySetGetTAS() {
+	t := s.T()
+	delivery, err := model.NewWebhookDelivery("https://example.com/hook", model.WebhookEventTransitionComplete,
+		model.TransitionResp{TransitionID: uuid.New()}, 5)
+	s.Require().NoError(err)
+
+	t.Logf("inserting a webhook delivery")
+	err = s.sp.StoreWebhookDelivery(delivery)
+	s.Require().NoError(err)
+
+	got, err := s.sp.GetWebhookDelivery(delivery.DeliveryID)
+	s.Require().NoError(err)
+	s.Assert().Equal(delivery.URL, got.URL)
+	s.Assert().Equal(delivery.Payload, got.Payload)
+	s.Assert().Equal(model.WebhookDeliveryStatusPending, got.Status)
+
+	t.Logf("claiming the webhook delivery")
+	claimed := got
+	claimed.Attempts++
+	changed, err := s.sp.TASWebhookDelivery(claimed, got)
+	s.Require().NoError(err)
+	s.Require().True(changed)
+
+	// the claim succeeded, claiming against the original should now fail
+	changed, err = s.sp.TASWebhookDelivery(claimed, got)
+	s.Require().NoError(err)
+	s.Require().False(changed)
+
+	deliveries, err := s.sp.GetAllWebhookDeliveries()
+	s.Require().NoError(err)
+	found := false
+	for _, d := range deliveries {
+		if d.DeliveryID == delivery.DeliveryID {
+			found = true
+			s.Assert().Equal(1, d.Attempts)
+		}
+	}
+	s.Assert().True(found)
+
+	t.Logf("deleting the webhook delivery")
+	err = s.sp.DeleteWebhookDelivery(delivery.DeliveryID)
+	s.Require().NoError(err)
+	_, err = s.sp.GetWebhookDelivery(delivery.DeliveryID)
+	s.Require().ErrorContains(err, "does not exist")
+}
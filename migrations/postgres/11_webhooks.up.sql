@@ -0,0 +1,44 @@
+-- MIT License
+--
+-- Copyright © 2025 Contributors to the OpenCHAMI Project
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in all
+-- copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+BEGIN;
+
+-- URL to POST a summary to when the transition completes, if requested.
+ALTER TABLE transitions ADD COLUMN IF NOT EXISTS "callback_url" TEXT NOT NULL DEFAULT '';
+
+-- Pending and finished webhook notifications. Each row is one notification to one URL, so any PCS instance can
+-- retry it.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	"id" UUID PRIMARY KEY,
+	"url" TEXT NOT NULL,
+	"event" VARCHAR(255) NOT NULL,
+	-- the signed request body, kept as-is so every attempt sends identical bytes
+	"payload" TEXT NOT NULL,
+	"created" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	"next_attempt" TIMESTAMPTZ NOT NULL,
+	"attempts" INT NOT NULL DEFAULT 0,
+	"status" VARCHAR(255) NOT NULL,
+	"last_error" TEXT NOT NULL DEFAULT '',
+	"expires" TIMESTAMPTZ
+);
+
+COMMIT;
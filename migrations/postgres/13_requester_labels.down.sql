@@ -0,0 +1,37 @@
+-- MIT License
+--
+-- Copyright © 2025 Contributors to the OpenCHAMI Project
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in all
+-- copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+BEGIN;
+
+DROP INDEX IF EXISTS transitions_labels_idx;
+ALTER TABLE transitions
+	DROP COLUMN IF EXISTS "requester_sub",
+	DROP COLUMN IF EXISTS "requester_iss",
+	DROP COLUMN IF EXISTS "reason",
+	DROP COLUMN IF EXISTS "labels";
+ALTER TABLE power_cap_tasks
+	DROP COLUMN IF EXISTS "requester_sub",
+	DROP COLUMN IF EXISTS "requester_iss",
+	DROP COLUMN IF EXISTS "reason",
+	DROP COLUMN IF EXISTS "labels";
+
+COMMIT;
@@ -0,0 +1,39 @@
+-- MIT License
+--
+-- Copyright © 2025 Contributors to the OpenCHAMI Project
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in all
+-- copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+BEGIN;
+
+-- Responses to requests made with an Idempotency-Key, so a retried request gets the original response instead of
+-- starting a duplicate job. Keys are scoped to the request type and the requester's subject.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	"scope" VARCHAR(255) NOT NULL,
+	"subject" TEXT NOT NULL DEFAULT '',
+	"key" VARCHAR(255) NOT NULL,
+	"request_hash" VARCHAR(64) NOT NULL,
+	-- the JSON creation response, empty until the job has been started
+	"response" TEXT NOT NULL DEFAULT '',
+	"created" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	"expires" TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY ("scope", "subject", "key")
+);
+
+COMMIT;
@@ -0,0 +1,38 @@
+-- MIT License
+--
+-- Copyright © 2025 Contributors to the OpenCHAMI Project
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in all
+-- copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+BEGIN;
+
+-- What to do when a transition would exceed a power budget, and the components left out of it if partly started.
+ALTER TABLE transitions ADD COLUMN IF NOT EXISTS "budget_policy" VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE transitions ADD COLUMN IF NOT EXISTS "budget_deferred" JSON NOT NULL DEFAULT '[]';
+
+-- The power capabilities last read from each component's BMC by a power capping task.
+CREATE TABLE IF NOT EXISTS power_capabilities (
+	"xname" VARCHAR(255) PRIMARY KEY,
+	"host_limit_max" INTEGER,
+	"host_limit_min" INTEGER,
+	"powerup_power" INTEGER,
+	"updated" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMIT;
@@ -0,0 +1,43 @@
+-- MIT License
+--
+-- Copyright © 2025 Contributors to the OpenCHAMI Project
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in all
+-- copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+BEGIN;
+
+-- Whether a transition's requester may act on frozen components.
+ALTER TABLE transitions ADD COLUMN IF NOT EXISTS "freeze_override" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Windows in which transitions and power capping leave components alone. Components are frozen by xname, by HSM
+-- group, or by prefix, an xname whose descendants are frozen with it.
+CREATE TABLE IF NOT EXISTS freezes (
+	"id" UUID PRIMARY KEY,
+	"xnames" JSON NOT NULL DEFAULT '[]',
+	"group_names" JSON NOT NULL DEFAULT '[]',
+	"prefixes" JSON NOT NULL DEFAULT '[]',
+	"start_time" TIMESTAMPTZ NOT NULL,
+	"end_time" TIMESTAMPTZ NOT NULL,
+	"reason" TEXT NOT NULL DEFAULT '',
+	"requester_sub" VARCHAR(255) NOT NULL DEFAULT '',
+	"requester_iss" VARCHAR(255) NOT NULL DEFAULT '',
+	"created" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMIT;